@@ -0,0 +1,182 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// BatchesURL is the url endpoint for batch operations on mailchimp v3
+const BatchesURL = "/batches"
+
+// BatchStatus is the processing status of a batch request
+type BatchStatus string
+
+const (
+	BatchStatusPending       BatchStatus = "pending"
+	BatchStatusPreprocessing BatchStatus = "preprocessing"
+	BatchStatusStarted       BatchStatus = "started"
+	BatchStatusFinalizing    BatchStatus = "finalizing"
+	BatchStatusFinished      BatchStatus = "finished"
+)
+
+// Batch is a collection of operations sent to mailchimp in a single request,
+// and processed in the background.
+// http://developer.mailchimp.com/documentation/mailchimp/reference/batches/
+type Batch struct {
+	// A string that uniquely identifies this batch request.
+	ID string `                json:"id"`
+
+	// The status of the batch call.
+	Status BatchStatus `        json:"status"`
+
+	// The total number of operations to complete as part of this batch request.
+	TotalOperations int `       json:"total_operations"`
+
+	// The number of completed operations. This includes operations that returned an error.
+	FinishedOperations int `    json:"finished_operations"`
+
+	// The number of completed operations that returned an error.
+	ErroredOperations int `     json:"errored_operations"`
+
+	// The time and date when the server received the batch request.
+	SubmittedAt Time `          json:"submitted_at"`
+
+	// The time and date when all operations in the batch request completed.
+	CompletedAt Time `          json:"completed_at"`
+
+	// The URL of the gzipped archive of the results of all the operations.
+	ResponseBodyURL string `    json:"response_body_url"`
+
+	// Internal
+	Client MailchimpClient `json:"-"`
+}
+
+// SetClient fulfills ClientType
+func (b *Batch) SetClient(c MailchimpClient) { b.Client = c }
+
+// BatchOperation is a single request in a batch.
+type BatchOperation struct {
+	// The HTTP method to use for the operation. Possible Values:
+	// GET, POST, PUT, PATCH, DELETE
+	Method string `                    json:"method"`
+
+	// The relative path to use for the operation.
+	Path string `                      json:"path"`
+
+	// Any URL params to use, only applies to GET operations.
+	Params map[string]interface{} `    json:"params,omitempty"`
+
+	// A string containing the JSON body to use with the request.
+	Body string `                      json:"body,omitempty"`
+
+	// An optional client-supplied id returned with the operation results.
+	OperationID string `               json:"operation_id,omitempty"`
+}
+
+// NewBatchOperation returns a operation with data marshalled into the body.
+// data may be nil for operations without a body.
+func NewBatchOperation(method string, path string, data interface{}) (*BatchOperation, error) {
+	op := &BatchOperation{
+		Method: method,
+		Path:   "/" + slashJoin(path),
+	}
+
+	if data != nil {
+		js, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		op.Body = string(js)
+	}
+
+	return op, nil
+}
+
+// CreateBatch contains the operations of a new batch request
+type CreateBatch struct {
+	Operations []*BatchOperation `json:"operations"`
+}
+
+// CreateBatch starts a new batch request
+func (c *Client) CreateBatch(ctx context.Context, data *CreateBatch) (*Batch, error) {
+	return createBatch(ctx, c, data)
+}
+
+// createBatch is shared with helpers that only hold a MailchimpClient
+func createBatch(ctx context.Context, client MailchimpClient, data *CreateBatch) (*Batch, error) {
+	if len(data.Operations) == 0 {
+		return nil, fmt.Errorf("missing field: Operations")
+	}
+
+	response, err := client.Post(ctx, BatchesURL, nil, data)
+	if err != nil {
+		Log.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("response error", caller())
+		return nil, err
+	}
+
+	var batch *Batch
+	err = json.Unmarshal(response, &batch)
+	if err != nil {
+		Log.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("response error", caller())
+		return nil, err
+	}
+
+	batch.Client = client
+
+	return batch, nil
+}
+
+// GetBatch returns the status of a batch request
+func (c *Client) GetBatch(ctx context.Context, id string) (*Batch, error) {
+	response, err := c.Get(ctx, slashJoin(BatchesURL, id), nil)
+	if err != nil {
+		Log.WithFields(logrus.Fields{
+			"batch_id": id,
+			"error":    err.Error(),
+		}).Error("response error", caller())
+		return nil, err
+	}
+
+	var batch *Batch
+	err = json.Unmarshal(response, &batch)
+	if err != nil {
+		Log.WithFields(logrus.Fields{
+			"batch_id": id,
+			"error":    err.Error(),
+		}).Error("response error", caller())
+		return nil, err
+	}
+
+	batch.Client = c
+
+	return batch, nil
+}
+
+// Delete stops a batch request from running
+func (b *Batch) Delete(ctx context.Context) error {
+	if b.Client == nil {
+		return ErrorNoClient
+	}
+	return b.Client.Delete(ctx, slashJoin(BatchesURL, b.ID))
+}
@@ -0,0 +1,147 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	check "gopkg.in/check.v1"
+
+	t "github.com/greatbeyond/mailchimp/testing"
+)
+
+var _ = check.Suite(&BatchSuite{})
+
+type BatchSuite struct {
+	client *Client
+	server *t.MockServer
+	ctx    context.Context
+}
+
+func (s *BatchSuite) SetUpSuite(c *check.C) {}
+
+func (s *BatchSuite) SetUpTest(c *check.C) {
+	s.server = t.NewMockServer()
+	s.server.SetChecker(c)
+
+	s.client = NewClient()
+	s.client.HTTPClient = s.server.HTTPClient
+
+	s.ctx = NewContextWithToken(context.Background(), os.Getenv("MAILCHIMP_TEST_TOKEN"))
+	// We need http to use the mock server
+	s.ctx = NewContextWithURL(s.ctx, "http://us13.api.mailchimp.com/3.0/")
+}
+
+func (s *BatchSuite) TearDownTest(c *check.C) {}
+
+func (s *BatchSuite) Test_NewBatchOperation(c *check.C) {
+	op, err := NewBatchOperation("PATCH", "lists/57afe96172/members/abc", &UpdateMember{Status: Unsubscribed})
+	c.Assert(err, check.IsNil)
+	c.Assert(op, check.DeepEquals, &BatchOperation{
+		Method: "PATCH",
+		Path:   "/lists/57afe96172/members/abc",
+		Body:   `{"status":"unsubscribed"}`,
+	})
+
+	op, err = NewBatchOperation("DELETE", "/lists/57afe96172/members/abc", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(op.Path, check.Equals, "/lists/57afe96172/members/abc")
+	c.Assert(op.Body, check.Equals, "")
+}
+
+func (s *BatchSuite) Test_CreateBatch_Normal(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "POST",
+		Code:   200,
+		Body:   `{"id":"e2b5a6a1b6","status":"pending","total_operations":0,"finished_operations":0,"errored_operations":0,"submitted_at":"2017-02-10T14:44:00+00:00","completed_at":"","response_body_url":""}`,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(body, check.Equals, `{"operations":[{"method":"DELETE","path":"/lists/57afe96172/members/abc","operation_id":"1"}]}`)
+			c.Assert(r.RequestURI, check.Equals, "http://us13.api.mailchimp.com/3.0/batches")
+		},
+	})
+
+	batch, err := s.client.CreateBatch(s.ctx, &CreateBatch{
+		Operations: []*BatchOperation{
+			{Method: "DELETE", Path: "/lists/57afe96172/members/abc", OperationID: "1"},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(batch.ID, check.Equals, "e2b5a6a1b6")
+	c.Assert(batch.Status, check.Equals, BatchStatusPending)
+	c.Assert(batch.Client, check.Not(check.IsNil))
+}
+
+func (s *BatchSuite) Test_CreateBatch_NoOperations(c *check.C) {
+	batch, err := s.client.CreateBatch(s.ctx, &CreateBatch{})
+	c.Assert(err, check.ErrorMatches, "missing field: Operations")
+	c.Assert(batch, check.IsNil)
+}
+
+func (s *BatchSuite) Test_GetBatch_Normal(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"id":"e2b5a6a1b6","status":"finished","total_operations":3,"finished_operations":3,"errored_operations":1,"submitted_at":"2017-02-10T14:44:00+00:00","completed_at":"2017-02-10T14:44:14+00:00","response_body_url":"https://example.net/result.tar.gz"}`,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(r.RequestURI, check.Equals, "http://us13.api.mailchimp.com/3.0/batches/e2b5a6a1b6")
+		},
+	})
+
+	batch, err := s.client.GetBatch(s.ctx, "e2b5a6a1b6")
+	c.Assert(err, check.IsNil)
+	c.Assert(batch, check.DeepEquals, &Batch{
+		ID:                 "e2b5a6a1b6",
+		Status:             BatchStatusFinished,
+		TotalOperations:    3,
+		FinishedOperations: 3,
+		ErroredOperations:  1,
+		SubmittedAt:        mustParseTime("2017-02-10T14:44:00+00:00"),
+		CompletedAt:        mustParseTime("2017-02-10T14:44:14+00:00"),
+		ResponseBodyURL:    "https://example.net/result.tar.gz",
+		Client:             s.client,
+	})
+}
+
+func (s *BatchSuite) Test_GetBatch_BadResponse(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{ bad json response`,
+	})
+
+	batch, err := s.client.GetBatch(s.ctx, "e2b5a6a1b6")
+	c.Assert(err, check.ErrorMatches, "invalid character.*")
+	c.Assert(batch, check.IsNil)
+}
+
+func (s *BatchSuite) Test_Delete_Normal(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "DELETE",
+		Code:   http.StatusNoContent,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(r.RequestURI, check.Equals, "http://us13.api.mailchimp.com/3.0/batches/e2b5a6a1b6")
+		},
+	})
+
+	batch := &Batch{ID: "e2b5a6a1b6", Client: s.client}
+	c.Assert(batch.Delete(s.ctx), check.IsNil)
+}
+
+func (s *BatchSuite) Test_Delete_NoClient(c *check.C) {
+	batch := &Batch{ID: "e2b5a6a1b6"}
+	c.Assert(batch.Delete(s.ctx), check.ErrorMatches, "no client assigned by parent")
+}
@@ -15,8 +15,15 @@
 package mailchimp
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -27,71 +34,87 @@ const (
 	// CampaignContentURL is the url endpoint for campaign content on mailchimp v3
 	CampaignContentURL = "/content"
 
-	CampaignActionCancel     = "/actions/cancel-send" //	Cancel a campaign
-	CampaignActionPause      = "/actions/pause"       //	Pause an RSS-Driven campaign
-	CampaignActionResume     = "/actions/resume"      //	Resume an RSS-Driven campaign
-	CampaignActionSchedule   = "/actions/schedule"    //	Schedule a campaign
-	CampaignActionSend       = "/actions/send"        //	Send a campaign
-	CampaignActionTest       = "/actions/test"        //	Send a test email
-	CampaignActionUnschedule = "/actions/unschedule"  //	Unschedule a campaign
-	// CampaignActionReplicate  = "/actions/replicate"   //	Replicate a campaign ( not implemented )
+	CampaignActionCancel       = "/actions/cancel-send"   //	Cancel a campaign
+	CampaignActionPause        = "/actions/pause"         //	Pause an RSS-Driven campaign
+	CampaignActionResume       = "/actions/resume"        //	Resume an RSS-Driven campaign
+	CampaignActionSchedule     = "/actions/schedule"      //	Schedule a campaign
+	CampaignActionSend         = "/actions/send"          //	Send a campaign
+	CampaignActionTest         = "/actions/test"          //	Send a test email
+	CampaignActionUnschedule   = "/actions/unschedule"    //	Unschedule a campaign
+	CampaignActionReplicate    = "/actions/replicate"     //	Replicate a campaign
+	CampaignActionCreateResend = "/actions/create-resend" //	Resend a campaign
+
+	// CampaignSendChecklistURL is the url endpoint for the send checklist of a campaign
+	CampaignSendChecklistURL = "/send-checklist"
+)
+
+// Resend shortcut types for CreateResend
+const (
+	ResendToNonOpeners     = "to_non_openers"
+	ResendToNewSubscribers = "to_new_subscribers"
+	ResendToNonClickers    = "to_non_clickers"
+	ResendToNonPurchasers  = "to_non_purchasers"
 )
 
 // Campaign defines a campaign on mailchimp
 type Campaign struct {
 	// A string that uniquely identifies this campaign.
-	ID string `                              json:"id"`
+	ID string `                               json:"id"`
 
 	// There are four types of campaigns you can create in MailChimp. A/B Split campaigns have been deprecated and variate campaigns should be used instead.
-	Type string `                            json:"type"`
+	Type string `                             json:"type"`
 
 	// The date and time the campaign was created.
-	CreateTime string `                      json:"create_time"`
+	CreateTime Time `                         json:"create_time"`
 
 	// The link to the campaign’s archive version.
-	ArchiveURL string `                      json:"archive_url"`
+	ArchiveURL string `                       json:"archive_url"`
 
 	// The original link to the campaign’s archive version.
-	LongArchiveURL string `                  json:"long_archive_url"`
+	LongArchiveURL string `                   json:"long_archive_url"`
 
 	// The current status of the campaign.
-	Status string `                          json:"status"`
+	Status CampaignStatus `                   json:"status"`
 
 	// The total number of emails sent for this campaign.
-	EmailsSent int `                         json:"emails_sent"`
+	EmailsSent int `                          json:"emails_sent"`
 
 	// The date and time a campaign was sent.
-	SendTime string `                        json:"send_time"`
+	SendTime Time `                           json:"send_time"`
 
 	// How the campaign’s content is put together (‘template’, ‘drag_and_drop’, ‘html’, ‘url’).
-	ContentType string `                     json:"content_type"`
+	ContentType string `                      json:"content_type"`
 
 	// List settings for the campaign.
-	Recipients CampaignRecipients `          json:"recipients"`
+	Recipients CampaignRecipients `           json:"recipients"`
 
 	// The settings for your campaign, including subject, from name, reply-to address, and more.
-	Settings CampaignSettings `              json:"settings"`
+	Settings CampaignSettings `               json:"settings"`
 
 	// The settings specific to A/B test campaigns.
-	VariateSettings interface{} `            json:"variate_settings"`
+	VariateSettings *CampaignVariateSettings `json:"variate_settings"`
 
 	// The tracking options for a campaign.
-	Tracking CampaignTracking `              json:"tracking"`
+	Tracking CampaignTracking `               json:"tracking"`
 
 	// RSS options for a campaign.
-	RSSOpts interface{} `                    json:"rss_opts"`
+	RSSOpts *CampaignRSSOpts `                json:"rss_opts"`
 
 	// A/B Testing options for a campaign.
-	ABSplitOpts interface{} `                json:"ab_split_opts"`
+	ABSplitOpts *CampaignABSplitOpts `        json:"ab_split_opts"`
 
 	// The preview for the campaign, rendered by social networks like Facebook and Twitter. Learn more.
-	SocialCard interface{} `                 json:"social_card"`
+	SocialCard *CampaignSocialCard `          json:"social_card"`
 
 	// For sent campaigns, a summary of opens, clicks, and unsubscribes.
-	ReportSummary interface{} `              json:"report_summary"`
+	ReportSummary *CampaignReportSummary `    json:"report_summary"`
 
 	// Updates on campaigns in the process of sending.
-	DeliveryStatus CampaignDeliveryStatus `  json:"delivery_status"`
+	DeliveryStatus CampaignDeliveryStatus `   json:"delivery_status"`
+
+	// Reload the campaign before Send, Schedule, Unschedule, Cancel, Pause
+	// and Resume so the status check is made against the current status.
+	RefreshBeforeAction bool `json:"-"`
 
 	// Internal
 	Client MailchimpClient `json:"-"`
@@ -154,39 +177,272 @@ type CampaignSettings struct {
 // CampaignTracking settings
 type CampaignTracking struct {
 	// Whether to track opens. Defaults to true. Cannot be set to false for variate campaigns.
-	Opens bool `                 json:"opens,omitempty"`
+	Opens bool `                    json:"opens,omitempty"`
 
 	// Whether to track clicks in the HTML version of the campaign. Defaults to true. Cannot be set to false for variate campaigns.
-	HTMLClicks bool `            json:"html_clicks,omitempty"`
+	HTMLClicks bool `               json:"html_clicks,omitempty"`
 
 	// Whether to track clicks in the plain-text version of the campaign. Defaults to true. Cannot be set to false for variate campaigns.
-	TextClicks bool `            json:"text_clicks,omitempty"`
+	TextClicks bool `               json:"text_clicks,omitempty"`
 
 	// Whether to enable Goal tracking.
-	GoalTracking bool `          json:"goal_tracking,omitempty"`
+	GoalTracking bool `             json:"goal_tracking,omitempty"`
 
 	// Whether to enable E-commerce tracking.
-	Ecomm360 bool `              json:"ecomm360,omitempty"`
+	Ecomm360 bool `                 json:"ecomm360,omitempty"`
 
 	// The custom slug for Google Analytics tracking (max of 50 bytes).
-	GoogleAnalytics string `     json:"google_analytics,omitempty"`
+	GoogleAnalytics string `        json:"google_analytics,omitempty"`
 
 	// The custom slug for ClickTale tracking (max of 50 bytes).
-	Clicktale string `           json:"clicktale,omitempty"`
+	Clicktale string `              json:"clicktale,omitempty"`
 
 	// Salesforce tracking options for a campaign. Must be using MailChimp’s built-in Salesforce integration.
-	Salesforce interface{} `     json:"salesforce,omitempty"`
+	Salesforce *CampaignSalesforce `json:"salesforce,omitempty"`
 
 	// Highrise tracking options for a campaign. Must be using MailChimp’s built-in Highrise integration.
-	Highrise interface{} `       json:"highrise,omitempty"`
+	Highrise *CampaignHighrise `    json:"highrise,omitempty"`
 
 	// Capsule tracking options for a campaign. Must be using MailChimp’s built-in Capsule integration.
-	Capsule interface{} `        json:"capsule,omitempty"`
+	Capsule *CampaignCapsule `      json:"capsule,omitempty"`
+}
+
+// CampaignSalesforce are the Salesforce tracking options of a campaign
+type CampaignSalesforce struct {
+	// Create a campaign in a connected Salesforce account.
+	Campaign bool `json:"campaign"`
+	// Update contact notes for a campaign based on subscriber email addresses.
+	Notes bool `json:"notes"`
+}
+
+// CampaignHighrise are the Highrise tracking options of a campaign
+type CampaignHighrise struct {
+	// Create a campaign in a connected Highrise account.
+	Campaign bool `json:"campaign"`
+	// Update contact notes for a campaign based on subscriber email addresses.
+	Notes bool `json:"notes"`
+}
+
+// CampaignCapsule are the Capsule tracking options of a campaign
+type CampaignCapsule struct {
+	// Update contact notes for a campaign based on subscriber email addresses.
+	Notes bool `json:"notes"`
+}
+
+// Variate winner criteria
+const (
+	WinnerCriteriaOpens        = "opens"
+	WinnerCriteriaClicks       = "clicks"
+	WinnerCriteriaManual       = "manual"
+	WinnerCriteriaTotalRevenue = "total_revenue"
+)
+
+// CampaignVariateSettings are the settings of a variate (A/B test) campaign.
+// Up to three values can be tested for each of subject lines, send times,
+// from names and reply-to addresses, or contents.
+type CampaignVariateSettings struct {
+	// ID for the winning combination.
+	WinningCombinationID string `       json:"winning_combination_id,omitempty"`
+
+	// ID of the campaign that was sent to the remaining recipients based on the winning combination.
+	WinningCampaignID string `          json:"winning_campaign_id,omitempty"`
+
+	// The combination that performs the best. Possible values:
+	// opens, clicks, manual, total_revenue
+	WinnerCriteria string `             json:"winner_criteria,omitempty"`
+
+	// The number of minutes to wait before choosing the winning campaign.
+	WaitTime int `                      json:"wait_time,omitempty"`
+
+	// The percentage of recipients to send the test combinations to, between 10 and 100.
+	TestSize int `                      json:"test_size,omitempty"`
+
+	// The possible subject lines to test.
+	SubjectLines []string `             json:"subject_lines,omitempty"`
+
+	// The possible send times to test.
+	SendTimes []Time `                  json:"send_times,omitempty"`
+
+	// The possible from names.
+	FromNames []string `                json:"from_names,omitempty"`
+
+	// The possible reply-to addresses.
+	ReplyToAddresses []string `         json:"reply_to_addresses,omitempty"`
+
+	// Descriptions of possible email contents.
+	Contents []string `                 json:"contents,omitempty"`
+
+	// Combinations of possible variables used to build emails.
+	Combinations []*VariateCombination `json:"combinations,omitempty"`
+}
+
+// VariateCombination is one of the emails built from the variate
+// settings. The values are indexes into the matching settings array.
+type VariateCombination struct {
+	// Unique ID for the combination.
+	ID string `             json:"id"`
+
+	// The index of variate_settings.subject_lines used.
+	SubjectLine int `       json:"subject_line"`
+
+	// The index of variate_settings.send_times used.
+	SendTime int `          json:"send_time"`
+
+	// The index of variate_settings.from_names used.
+	FromName int `          json:"from_name"`
+
+	// The index of variate_settings.reply_to_addresses used.
+	ReplyTo int `           json:"reply_to"`
+
+	// The index of variate_settings.contents used.
+	ContentDescription int `json:"content_description"`
+
+	// The number of recipients for this combination.
+	Recipients int `        json:"recipients"`
+}
+
+// RSS frequencies
+const (
+	RSSFrequencyDaily   = "daily"
+	RSSFrequencyWeekly  = "weekly"
+	RSSFrequencyMonthly = "monthly"
+)
+
+// CampaignRSSOpts are the options of a RSS campaign
+type CampaignRSSOpts struct {
+	// The URL for the RSS feed.
+	FeedURL string `       json:"feed_url,omitempty"`
+
+	// The frequency of the RSS Campaign. Possible values:
+	// daily, weekly, monthly
+	Frequency string `     json:"frequency,omitempty"`
+
+	// The schedule for sending the RSS Campaign.
+	Schedule *RSSSchedule `json:"schedule,omitempty"`
+
+	// The date the campaign was last sent.
+	LastSent *Time `       json:"last_sent,omitempty"`
+
+	// Whether to add CSS to images in the RSS feed to constrain their width in campaigns.
+	ConstrainRSSImg bool ` json:"constrain_rss_img,omitempty"`
+}
+
+// RSSSchedule is when a RSS campaign is sent
+type RSSSchedule struct {
+	// The hour to send the campaign in local time, 0-23.
+	Hour int `               json:"hour"`
+
+	// The days of the week to send a daily RSS Campaign.
+	DailySend *RSSDailySend `json:"daily_send,omitempty"`
+
+	// The day of the week to send a weekly RSS Campaign, for example monday.
+	WeeklySendDay string `   json:"weekly_send_day,omitempty"`
+
+	// The day of the month to send a monthly RSS Campaign. Acceptable
+	// days are 0-31, where 0 is the last day of a month.
+	MonthlySendDate int `    json:"monthly_send_date,omitempty"`
+}
+
+// RSSDailySend are the days of the week a daily RSS campaign is sent
+type RSSDailySend struct {
+	Sunday    bool `json:"sunday"`
+	Monday    bool `json:"monday"`
+	Tuesday   bool `json:"tuesday"`
+	Wednesday bool `json:"wednesday"`
+	Thursday  bool `json:"thursday"`
+	Friday    bool `json:"friday"`
+	Saturday  bool `json:"saturday"`
+}
+
+// CampaignABSplitOpts are the options of a A/B Split campaign. A/B Split
+// campaigns are deprecated, use variate campaigns instead.
+type CampaignABSplitOpts struct {
+	// The type of AB split to run. Possible values:
+	// subject, from_name, schedule
+	SplitTest string `    json:"split_test,omitempty"`
+
+	// How we should evaluate a winner. Possible values:
+	// opens, clicks, manual
+	PickWinner string `   json:"pick_winner,omitempty"`
+
+	// How unit of time for measuring the winner, hours or days.
+	WaitUnits string `    json:"wait_units,omitempty"`
+
+	// The amount of time to wait before picking a winner.
+	WaitTime int `        json:"wait_time,omitempty"`
+
+	// The size of the split groups, between 1 and 50.
+	SplitSize int `       json:"split_size,omitempty"`
+
+	// For campaigns split on from name, the from names for group A and B.
+	FromNameA string `    json:"from_name_a,omitempty"`
+	FromNameB string `    json:"from_name_b,omitempty"`
+
+	// For campaigns split on from name, the reply-to addresses for group A and B.
+	ReplyEmailA string `  json:"reply_email_a,omitempty"`
+	ReplyEmailB string `  json:"reply_email_b,omitempty"`
+
+	// For campaigns split on subject line, the subject lines for group A and B.
+	SubjectA string `     json:"subject_a,omitempty"`
+	SubjectB string `     json:"subject_b,omitempty"`
+
+	// For campaigns split on send time, the send times for group A and B.
+	SendTimeA *Time `     json:"send_time_a,omitempty"`
+	SendTimeB *Time `     json:"send_time_b,omitempty"`
+
+	// The send time for the winning version.
+	SendTimeWinner *Time `json:"send_time_winner,omitempty"`
+}
+
+// CampaignSocialCard is the preview of a campaign on social networks
+type CampaignSocialCard struct {
+	// The url for the header image for the card.
+	ImageURL string `   json:"image_url,omitempty"`
+
+	// A short summary of the campaign to display.
+	Description string `json:"description,omitempty"`
+
+	// The title for the card. Typically the subject line of the campaign.
+	Title string `      json:"title,omitempty"`
+}
+
+// CampaignReportSummary is a summary of the opens and clicks of a sent
+// campaign, see GetReport for the full report
+type CampaignReportSummary struct {
+	Opens            int             `json:"opens"`
+	UniqueOpens      int             `json:"unique_opens"`
+	OpenRate         float64         `json:"open_rate"`
+	Clicks           int             `json:"clicks"`
+	SubscriberClicks int             `json:"subscriber_clicks"`
+	ClickRate        float64         `json:"click_rate"`
+	Ecommerce        ReportEcommerce `json:"ecommerce"`
 }
 
+// Delivery statuses of a sending campaign
+const (
+	DeliveryStatusDelivering = "delivering"
+	DeliveryStatusDelivered  = "delivered"
+	DeliveryStatusCanceling  = "canceling"
+	DeliveryStatusCanceled   = "canceled"
+)
+
 // CampaignDeliveryStatus updates on campaigns in the process of sending.
 type CampaignDeliveryStatus struct {
-	Enabled bool `json:"enabled"`
+	// Whether Campaign Delivery Status is enabled for this account and campaign.
+	Enabled bool `       json:"enabled"`
+
+	// Whether a campaign send can be canceled.
+	CanCancel bool `     json:"can_cancel"`
+
+	// The current state of a campaign delivery.
+	// Possible values: delivering, delivered, canceling, canceled
+	Status string `      json:"status"`
+
+	// The total number of emails confirmed sent for this campaign so far.
+	EmailsSent int `     json:"emails_sent"`
+
+	// The total number of emails canceled for this campaign.
+	EmailsCanceled int `json:"emails_canceled"`
 }
 
 // CreateCampaign reference:
@@ -199,7 +455,7 @@ type CreateCampaign struct {
 	Type string `                             json:"type"`
 
 	// List settings for the campaign.
-	Recipients *CampaignRecipients `         json:"recipients,omitempty"`
+	Recipients *CampaignRecipients `          json:"recipients,omitempty"`
 
 	// The settings for your campaign, including subject,
 	// from name, reply-to address, and more.
@@ -209,23 +465,23 @@ type CreateCampaign struct {
 	Settings interface{} `                    json:"settings,omitempty"`
 
 	// The settings specific to A/B test campaigns.
-	VariateSettings interface{} `             json:"variate_settings,omitempty"`
+	VariateSettings *CampaignVariateSettings `json:"variate_settings,omitempty"`
 
 	// The tracking options for a campaign.
 	Tracking interface{} `                    json:"tracking,omitempty"`
 
 	// RSS options for a campaign.
-	RssOpts interface{} `                     json:"rss_opts,omitempty"`
+	RssOpts *CampaignRSSOpts `                json:"rss_opts,omitempty"`
 
 	// A/B Testing options for a campaign.
-	ABSplitOpts interface{} `                 json:"ab_split_opts,omitempty"`
+	ABSplitOpts *CampaignABSplitOpts `        json:"ab_split_opts,omitempty"`
 
 	// The preview for the campaign, rendered by social networks
 	// like Facebook and Twitter. Learn more.
-	SocialCard interface{} `                  json:"social_card,omitempty"`
+	SocialCard *CampaignSocialCard `          json:"social_card,omitempty"`
 
 	// For sent campaigns, a summary of opens, clicks, and unsubscribes.
-	ReportSummary interface{} `               json:"report_summary,omitempty"`
+	ReportSummary *CampaignReportSummary `    json:"report_summary,omitempty"`
 
 	// Updates on campaigns in the process of sending.
 	DeliveryStatus *CampaignDeliveryStatus `  json:"delivery_status,omitempty"`
@@ -434,90 +690,130 @@ func (c *Campaign) Update(ctx context.Context, data *UpdateCampaign) (*Campaign,
 // -----------------------------------------------------------------
 // Actions on campaign
 
-// Cancel a campaign
+// Cancel a sending campaign
 func (c *Campaign) Cancel(ctx context.Context) error {
-	_, err := c.Client.Post(ctx, slashJoin(CampaignsURL, c.ID, CampaignActionCancel), nil, nil)
-	if err != nil {
-		Log.WithFields(logrus.Fields{
-			"ID":    c.ID,
-			"error": err.Error(),
-		}).Error("response error", caller())
-		return err
-	}
-	return nil
+	return c.transition(ctx, CampaignActionCancel, nil)
 }
 
-// Pause a campaign
+// Pause a sending RSS campaign
 func (c *Campaign) Pause(ctx context.Context) error {
-	_, err := c.Client.Post(ctx, slashJoin(CampaignsURL, c.ID, CampaignActionPause), nil, nil)
-	if err != nil {
-		Log.WithFields(logrus.Fields{
-			"ID":    c.ID,
-			"error": err.Error(),
-		}).Error("response error", caller())
-		return err
-	}
-	return nil
+	return c.transition(ctx, CampaignActionPause, nil)
 }
 
-// Resume a campaign
+// Resume a paused RSS campaign
 func (c *Campaign) Resume(ctx context.Context) error {
-	_, err := c.Client.Post(ctx, slashJoin(CampaignsURL, c.ID, CampaignActionResume), nil, nil)
-	if err != nil {
-		Log.WithFields(logrus.Fields{
-			"ID":    c.ID,
-			"error": err.Error(),
-		}).Error("response error", caller())
-		return err
-	}
-	return nil
+	return c.transition(ctx, CampaignActionResume, nil)
 }
 
-// CampaignScheduleData contains data required by Schedule()
+// CampaignScheduleData contains data required by Schedule().
+// Use NewSchedule to create a valid schedule.
 type CampaignScheduleData struct {
 	// The date and time in UTC (2017-02-04T19:13:00+00:00) to schedule the campaign for delivery.
 	// Campaigns may only be scheduled to send on the quarter-hour (:00, :15, :30, :45).
-	ScheduleTime string `json:"schedule_time"`
+	ScheduleTime Time `json:"schedule_time"`
 	// Choose whether the campaign should use Timewarp when sending. Campaigns
 	// scheduled with Timewarp are localized based on the recipients’ time zones.
 	// For example, a Timewarp campaign with a schedule_time of 13:00 will be sent
 	// to each recipient at 1:00pm in their local time. Cannot be set to true
 	// for campaigns using Batch Delivery.
 	Timewarp bool `json:"timewarp,omitempty"`
-	// Choose whether the campaign should use Batch Delivery. Cannot be set to
-	// true for campaigns using Timewarp.
-	BatchDelivery struct {
-		// The delay, in minutes, between batches.
-		BatchDelay int `json:"batch_delay"`
-		// The number of batches for the campaign send.
-		BatchCount int `json:"batch_count"`
-	} `json:"batch_delivery,omitempty"`
+	// Choose whether the campaign should use Batch Delivery. Cannot be set
+	// for campaigns using Timewarp.
+	BatchDelivery *CampaignBatchDelivery `json:"batch_delivery,omitempty"`
 }
 
-// Schedule a campaign
-func (c *Campaign) Schedule(ctx context.Context, data *CampaignScheduleData) error {
-	_, err := c.Client.Post(ctx, slashJoin(CampaignsURL, c.ID, CampaignActionSchedule), nil, data)
-	if err != nil {
-		Log.WithFields(logrus.Fields{
-			"ID":    c.ID,
-			"error": err.Error(),
-		}).Error("response error", caller())
-		return err
+// CampaignBatchDelivery splits the send of a campaign in batches
+type CampaignBatchDelivery struct {
+	// The delay, in minutes, between batches.
+	BatchDelay int `json:"batch_delay"`
+	// The number of batches for the campaign send.
+	BatchCount int `json:"batch_count"`
+}
+
+// ScheduleInterval is the interval campaigns may be scheduled on
+const ScheduleInterval = 15 * time.Minute
+
+// ErrInvalidSchedule is returned by NewSchedule and Validate for
+// schedules mailchimp would refuse.
+var ErrInvalidSchedule = errors.New("mailchimp: invalid schedule")
+
+// ScheduleOptions are the optional settings of NewSchedule
+type ScheduleOptions struct {
+	// Round times that are not on the quarter-hour up to the next
+	// quarter-hour instead of returning an error.
+	RoundUp bool
+	// Send using Timewarp, see CampaignScheduleData.
+	Timewarp bool
+	// Send in batches, see CampaignScheduleData.
+	BatchDelivery *CampaignBatchDelivery
+}
+
+// NewSchedule creates schedule data for Schedule() sending at t.
+// opts may be nil.
+//
+//  schedule, err := mailchimp.NewSchedule(time.Now().Add(time.Hour), &mailchimp.ScheduleOptions{RoundUp: true})
+//  if err != nil {
+//      return err
+//  }
+//  err = campaign.Schedule(ctx, schedule)
+func NewSchedule(t time.Time, opts *ScheduleOptions) (*CampaignScheduleData, error) {
+	if opts == nil {
+		opts = &ScheduleOptions{}
+	}
+
+	t = t.UTC()
+	if opts.RoundUp {
+		if rounded := t.Truncate(ScheduleInterval); !rounded.Equal(t) {
+			t = rounded.Add(ScheduleInterval)
+		}
+	}
+
+	data := &CampaignScheduleData{
+		ScheduleTime:  NewTime(t),
+		Timewarp:      opts.Timewarp,
+		BatchDelivery: opts.BatchDelivery,
+	}
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Validate checks the schedule against the rules of mailchimp. The
+// returned errors match ErrInvalidSchedule with errors.Is.
+func (d *CampaignScheduleData) Validate() error {
+	if d.ScheduleTime.IsZero() {
+		return fmt.Errorf("%w: missing schedule time", ErrInvalidSchedule)
+	}
+	if !d.ScheduleTime.Truncate(ScheduleInterval).Equal(d.ScheduleTime.Time) {
+		return fmt.Errorf("%w: %s is not on the quarter-hour", ErrInvalidSchedule, d.ScheduleTime)
+	}
+	if d.BatchDelivery != nil {
+		if d.Timewarp {
+			return fmt.Errorf("%w: timewarp can not be used with batch delivery", ErrInvalidSchedule)
+		}
+		if d.BatchDelivery.BatchCount < 1 || d.BatchDelivery.BatchDelay < 1 {
+			return fmt.Errorf("%w: batch count and delay must be positive", ErrInvalidSchedule)
+		}
 	}
 	return nil
 }
 
-// Send a campaign
-func (c *Campaign) Send(ctx context.Context) error {
-	_, err := c.Client.Post(ctx, slashJoin(CampaignsURL, c.ID, CampaignActionSend), nil, nil)
-	if err != nil {
-		Log.WithFields(logrus.Fields{
-			"ID":    c.ID,
-			"error": err.Error(),
-		}).Error("response error", caller())
+// Schedule a saved or paused campaign. The schedule is validated before it is
+// sent, errors from Validate match ErrInvalidSchedule.
+func (c *Campaign) Schedule(ctx context.Context, data *CampaignScheduleData) error {
+	if data == nil {
+		return fmt.Errorf("%w: missing schedule", ErrInvalidSchedule)
+	}
+	if err := data.Validate(); err != nil {
 		return err
 	}
-	return nil
+	return c.transition(ctx, CampaignActionSchedule, data)
+}
+
+// Send a saved campaign. Scheduled campaigns must be unscheduled first.
+func (c *Campaign) Send(ctx context.Context) error {
+	return c.transition(ctx, CampaignActionSend, nil)
 }
 
 // CampaignTestData contains values requiered by Test()
@@ -541,9 +837,14 @@ func (c *Campaign) Test(ctx context.Context, data *CampaignTestData) error {
 	return nil
 }
 
-// Unschedule a campaign
+// Unschedule a scheduled campaign
 func (c *Campaign) Unschedule(ctx context.Context) error {
-	_, err := c.Client.Post(ctx, slashJoin(CampaignsURL, c.ID, CampaignActionUnschedule), nil, nil)
+	return c.transition(ctx, CampaignActionUnschedule, nil)
+}
+
+// Delete removes a campaign
+func (c *Campaign) Delete(ctx context.Context) error {
+	err := c.Client.Delete(ctx, slashJoin(CampaignsURL, c.ID))
 	if err != nil {
 		Log.WithFields(logrus.Fields{
 			"ID":    c.ID,
@@ -554,17 +855,130 @@ func (c *Campaign) Unschedule(ctx context.Context) error {
 	return nil
 }
 
-// Delete removes a campaign
-func (c *Campaign) Delete(ctx context.Context) error {
-	err := c.Client.Delete(ctx, slashJoin(CampaignsURL, c.ID))
+// Replicate creates a copy of the campaign and returns it
+func (c *Campaign) Replicate(ctx context.Context) (*Campaign, error) {
+	return c.copyAction(ctx, CampaignActionReplicate, nil)
+}
+
+type createResend struct {
+	ShortcutType string `json:"shortcut_type,omitempty"`
+}
+
+// CreateResend creates a copy of the campaign sent to a subset of its
+// recipients, see the Resend constants. An empty resendType defaults to
+// ResendToNonOpeners. The new campaign is returned unsent.
+func (c *Campaign) CreateResend(ctx context.Context, resendType string) (*Campaign, error) {
+	return c.copyAction(ctx, CampaignActionCreateResend, &createResend{ShortcutType: resendType})
+}
+
+// copyAction posts a action that responds with a new campaign
+func (c *Campaign) copyAction(ctx context.Context, action string, data interface{}) (*Campaign, error) {
+	if c.Client == nil {
+		return nil, ErrorNoClient
+	}
+
+	response, err := c.Client.Post(ctx, slashJoin(CampaignsURL, c.ID, action), nil, data)
 	if err != nil {
 		Log.WithFields(logrus.Fields{
 			"ID":    c.ID,
 			"error": err.Error(),
 		}).Error("response error", caller())
-		return err
+		return nil, err
 	}
-	return nil
+
+	var campaign *Campaign
+	err = json.Unmarshal(response, &campaign)
+	if err != nil {
+		Log.WithFields(logrus.Fields{
+			"ID":    c.ID,
+			"error": err.Error(),
+		}).Error("response error", caller())
+		return nil, err
+	}
+
+	campaign.Client = c.Client
+
+	return campaign, nil
+}
+
+// Send checklist item types
+const (
+	ChecklistItemSuccess = "success"
+	ChecklistItemWarning = "warning"
+	ChecklistItemError   = "error"
+)
+
+// SendChecklist tells if a campaign is ready to send, and why not
+type SendChecklist struct {
+	// Whether the campaign is ready to send.
+	IsReady bool `              json:"is_ready"`
+
+	// A list of feedback items to review before sending your campaign.
+	Items []*SendChecklistItem `json:"items"`
+}
+
+// SendChecklistItem is a single check of a SendChecklist
+type SendChecklistItem struct {
+	// The item type, one of: success, warning, error.
+	Type string `   json:"type"`
+
+	// The ID for the specific item.
+	ID int `        json:"id"`
+
+	// The heading for the specific item.
+	Heading string `json:"heading"`
+
+	// Details about the specific feedback item.
+	Details string `json:"details"`
+}
+
+// Errors returns the items that prevent the campaign from being sent
+func (s *SendChecklist) Errors() []*SendChecklistItem {
+	return s.itemsOfType(ChecklistItemError)
+}
+
+// Warnings returns the items that should be reviewed before sending
+func (s *SendChecklist) Warnings() []*SendChecklistItem {
+	return s.itemsOfType(ChecklistItemWarning)
+}
+
+func (s *SendChecklist) itemsOfType(itemType string) []*SendChecklistItem {
+	items := []*SendChecklistItem{}
+	for _, item := range s.Items {
+		if item.Type == itemType {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// SendChecklist returns the send checklist of the campaign. Mailchimp
+// rejects sending campaigns that are not IsReady.
+func (c *Campaign) SendChecklist(ctx context.Context) (*SendChecklist, error) {
+	if c.Client == nil {
+		return nil, ErrorNoClient
+	}
+
+	response, err := c.Client.Get(ctx, slashJoin(CampaignsURL, c.ID, CampaignSendChecklistURL), nil)
+	if err != nil {
+		Log.WithFields(logrus.Fields{
+			"ID":    c.ID,
+			"error": err.Error(),
+		}).Error("response error", caller())
+		return nil, err
+	}
+
+	var checklist *SendChecklist
+	err = json.Unmarshal(response, &checklist)
+	if err != nil {
+		Log.WithFields(logrus.Fields{
+			"ID":    c.ID,
+			"error": err.Error(),
+		}).Error("response error", caller())
+		return nil, err
+	}
+
+	return checklist, nil
 }
 
 // -----------------------------------------------------------------
@@ -573,66 +987,146 @@ func (c *Campaign) Delete(ctx context.Context) error {
 // CampaignContent can be retrived
 type CampaignContent struct {
 	// Content options for multivariate campaigns.
-	VariateContents struct {
-		// Label used to identify the content option.
-		ContentLabel string `     json:"content_label,omitempty"`
+	VariateContents []*VariateContent `json:"variate_contents,omitempty"`
 
-		// The plain-text portion of the campaign. If left unspecified, we’ll generate this automatically.
-		PlainText string `        json:"plain_text,omitempty"`
+	// The plain-text portion of the campaign. If left unspecified, we’ll generate this automatically.
+	PlainText string `                 json:"plain_text,omitempty"`
 
-		// The raw HTML for the campaign.
-		HTML string `             json:"html,omitempty"`
-	} `json:"variate_contents,omitempty"`
+	// The raw HTML for the campaign.
+	HTML string `                      json:"html,omitempty"`
+
+	// The Archive HTML for the campaign.
+	ArchiveHTML string `               json:"archive_html,omitempty"`
+}
+
+// VariateContent is a content option of a multivariate campaign
+type VariateContent struct {
+	// Label used to identify the content option.
+	ContentLabel string `json:"content_label,omitempty"`
 
 	// The plain-text portion of the campaign. If left unspecified, we’ll generate this automatically.
-	PlainText string `            json:"plain_text,omitempty"`
+	PlainText string `   json:"plain_text,omitempty"`
 
 	// The raw HTML for the campaign.
-	HTML string `                 json:"html,omitempty"`
+	HTML string `        json:"html,omitempty"`
 }
 
 // CampaignContentEdit is documented here:
 // http://developer.mailchimp.com/documentation/mailchimp/reference/campaigns/content/
 type CampaignContentEdit struct {
 	// The plain-text portion of the campaign. If left unspecified, we’ll generate this automatically.
-	PlainText string `          json:"plain_text,omitempty"`
+	PlainText string `                 json:"plain_text,omitempty"`
 
 	// The raw HTML for the campaign.
-	HTML string `               json:"html,omitempty"`
+	HTML string `                      json:"html,omitempty"`
 
 	// When importing a campaign, the URL where the HTML lives.
-	URL string `                json:"url,omitempty"`
+	URL string `                       json:"url,omitempty"`
 
 	// Use this template to generate the HTML content of the campaign
-	Template *struct {
-		// The id of the template to use.
-		ID int `                json:"id,omitempty"`
-
-		// Content for the sections of the template. Each key should be the
-		// unique mc:edit area name from the template.
-		Sections interface{} `  json:"sections,omitempty"`
-	} `json:"template,omitempty"`
+	Template *CampaignContentTemplate `json:"template,omitempty"`
 
 	// Available when uploading an archive to create campaign content.
 	// The archive should include all campaign content and images. Learn more.
-	Archive *struct {
-		// he base64-encoded representation of the archive file.
-		ArchiveContent string `json:"archive_content,omitempty"`
-
-		// The type of encoded file. Defaults to zip.
-		// Possible Values:
-		// 	zip tar.gz tar.bz2 tar tgz tbz
-		ArchiveType string `   json:"archive_type,omitempty"`
-	} `json:"archive,omitempty"`
+	Archive *CampaignContentArchive `  json:"archive,omitempty"`
 
 	// Content options for Multivariate Campaigns. Each content option must provide HTML
 	// content and may optionally provide plain text. For campaigns not testing content,
 	// only one object should be provided.
-	VariateContents []interface{} `json:"variate_contents,omitempty"`
+	VariateContents []*VariateContent `json:"variate_contents,omitempty"`
+}
+
+// SetSection sets the content of a mc:edit area of the template. The
+// template is added if missing, set its ID before calling SetContent.
+//
+//  content := &mailchimp.CampaignContentEdit{Template: &mailchimp.CampaignContentTemplate{ID: 42}}
+//  content.SetSection("header", "<h1>Hello</h1>")
+//  content.SetSection("body", "<p>World</p>")
+func (e *CampaignContentEdit) SetSection(name string, html string) {
+	if e.Template == nil {
+		e.Template = &CampaignContentTemplate{}
+	}
+	if e.Template.Sections == nil {
+		e.Template.Sections = map[string]string{}
+	}
+	e.Template.Sections[name] = html
+}
+
+// CampaignContentTemplate is a template used to generate the content of a campaign
+type CampaignContentTemplate struct {
+	// The id of the template to use.
+	ID int `                    json:"id,omitempty"`
+
+	// Content for the sections of the template. Each key should be the
+	// unique mc:edit area name from the template.
+	Sections map[string]string `json:"sections,omitempty"`
+}
+
+// Archive types for CampaignContentArchive
+const (
+	ArchiveTypeZip   = "zip"
+	ArchiveTypeTarGz = "tar.gz"
+	ArchiveTypeTarBz = "tar.bz2"
+	ArchiveTypeTar   = "tar"
+	ArchiveTypeTgz   = "tgz"
+	ArchiveTypeTbz   = "tbz"
+)
+
+// CampaignContentArchive is a archive file with the content of a campaign
+type CampaignContentArchive struct {
+	// The base64-encoded representation of the archive file.
+	ArchiveContent string `json:"archive_content,omitempty"`
+
+	// The type of encoded file. Defaults to zip.
+	// Possible Values:
+	// 	zip tar.gz tar.bz2 tar tgz tbz
+	ArchiveType string `   json:"archive_type,omitempty"`
+}
+
+// NewArchive creates a zip archive of all files in fsys
+//
+//  archive, err := mailchimp.NewArchive(os.DirFS("newsletter"))
+//  if err != nil {
+//      return err
+//  }
+//  content, err := campaign.SetContent(ctx, &mailchimp.CampaignContentEdit{Archive: archive})
+func NewArchive(fsys fs.FS) (*CampaignContentArchive, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return err
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		w, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return &CampaignContentArchive{
+		ArchiveContent: base64.StdEncoding.EncodeToString(buf.Bytes()),
+		ArchiveType:    ArchiveTypeZip,
+	}, nil
 }
 
 // GetContent retrives the content for a campaign
-func (c *Campaign) GetContent(ctx context.Context) (interface{}, error) {
+func (c *Campaign) GetContent(ctx context.Context) (*CampaignContent, error) {
 	response, err := c.Client.Get(ctx, slashJoin(CampaignsURL, c.ID, CampaignContentURL), nil)
 	if err != nil {
 		Log.WithFields(logrus.Fields{
@@ -0,0 +1,211 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CampaignStatus is the current status of a campaign
+type CampaignStatus string
+
+// Campaign statuses
+const (
+	CampaignStatusSave      CampaignStatus = "save"
+	CampaignStatusPaused    CampaignStatus = "paused"
+	CampaignStatusSchedule  CampaignStatus = "schedule"
+	CampaignStatusSending   CampaignStatus = "sending"
+	CampaignStatusSent      CampaignStatus = "sent"
+	CampaignStatusCanceling CampaignStatus = "canceling"
+	CampaignStatusCanceled  CampaignStatus = "canceled"
+	CampaignStatusArchived  CampaignStatus = "archived"
+)
+
+// ErrInvalidTransition is returned by the campaign actions when the
+// action is not allowed from the current status of the campaign.
+// No request is sent to mailchimp when it is returned.
+var ErrInvalidTransition = errors.New("mailchimp: invalid campaign status transition")
+
+// CampaignTransition describes the statuses a campaign action may be
+// taken from and the status the campaign is left in.
+type CampaignTransition struct {
+	From []CampaignStatus
+	To   CampaignStatus
+}
+
+// CampaignTransitions holds the status transitions of the campaign
+// actions, keyed by the CampaignAction url.
+var CampaignTransitions = map[string]CampaignTransition{
+	CampaignActionSend: {
+		From: []CampaignStatus{CampaignStatusSave},
+		To:   CampaignStatusSending,
+	},
+	CampaignActionSchedule: {
+		From: []CampaignStatus{CampaignStatusSave, CampaignStatusPaused},
+		To:   CampaignStatusSchedule,
+	},
+	CampaignActionUnschedule: {
+		From: []CampaignStatus{CampaignStatusSchedule},
+		To:   CampaignStatusSave,
+	},
+	CampaignActionCancel: {
+		From: []CampaignStatus{CampaignStatusSending},
+		To:   CampaignStatusCanceling,
+	},
+	CampaignActionPause: {
+		From: []CampaignStatus{CampaignStatusSending},
+		To:   CampaignStatusPaused,
+	},
+	CampaignActionResume: {
+		From: []CampaignStatus{CampaignStatusPaused},
+		To:   CampaignStatusSending,
+	},
+}
+
+// CanTransition reports if action may be taken on a campaign with status s.
+// Actions missing from CampaignTransitions are always allowed.
+func (s CampaignStatus) CanTransition(action string) bool {
+	transition, ok := CampaignTransitions[action]
+	if !ok {
+		return true
+	}
+	for _, from := range transition.From {
+		if from == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Refresh reloads the campaign from mailchimp
+func (c *Campaign) Refresh(ctx context.Context) error {
+	if c.Client == nil {
+		return ErrorNoClient
+	}
+
+	response, err := c.Client.Get(ctx, slashJoin(CampaignsURL, c.ID), nil)
+	if err != nil {
+		Log.WithFields(logrus.Fields{
+			"ID":    c.ID,
+			"error": err.Error(),
+		}).Error("response error", caller())
+		return err
+	}
+
+	var campaign Campaign
+	err = json.Unmarshal(response, &campaign)
+	if err != nil {
+		Log.WithFields(logrus.Fields{
+			"ID":    c.ID,
+			"error": err.Error(),
+		}).Error("response error", caller())
+		return err
+	}
+
+	campaign.Client = c.Client
+	campaign.RefreshBeforeAction = c.RefreshBeforeAction
+	*c = campaign
+
+	return nil
+}
+
+// WaitForDelivery polls the campaign every interval until its status is
+// sent or canceled, or ctx is done. onProgress, if not nil, is called with
+// the delivery status after every poll. The campaign is left refreshed.
+// interval must be positive.
+//
+//  if err := campaign.Send(ctx); err != nil {
+//      return err
+//  }
+//  err := campaign.WaitForDelivery(ctx, time.Minute, func(s mailchimp.CampaignDeliveryStatus) {
+//      log.Printf("%d emails sent", s.EmailsSent)
+//  })
+func (c *Campaign) WaitForDelivery(ctx context.Context, interval time.Duration, onProgress func(CampaignDeliveryStatus)) error {
+	if c.Client == nil {
+		return ErrorNoClient
+	}
+	if interval <= 0 {
+		return fmt.Errorf("invalid poll interval %s", interval)
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		if err := c.Refresh(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		if onProgress != nil {
+			onProgress(c.DeliveryStatus)
+		}
+
+		if c.Status == CampaignStatusSent || c.Status == CampaignStatusCanceled {
+			return nil
+		}
+
+		timer.Reset(interval)
+	}
+}
+
+// transition posts a action after checking it against the status of the
+// campaign, and moves the campaign to the new status when it succeeds.
+// Campaigns without a known status, such as those from NewCampaign, are
+// not checked unless RefreshBeforeAction is set.
+func (c *Campaign) transition(ctx context.Context, action string, data interface{}) error {
+	if c.Client == nil {
+		return ErrorNoClient
+	}
+
+	if c.RefreshBeforeAction {
+		if err := c.Refresh(ctx); err != nil {
+			return err
+		}
+	}
+
+	if c.Status != "" && !c.Status.CanTransition(action) {
+		return fmt.Errorf("%w: %s from %s", ErrInvalidTransition, action, c.Status)
+	}
+
+	_, err := c.Client.Post(ctx, slashJoin(CampaignsURL, c.ID, action), nil, data)
+	if err != nil {
+		Log.WithFields(logrus.Fields{
+			"ID":    c.ID,
+			"error": err.Error(),
+		}).Error("response error", caller())
+		return err
+	}
+
+	if transition, ok := CampaignTransitions[action]; ok {
+		c.Status = transition.To
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	check "gopkg.in/check.v1"
+
+	t "github.com/greatbeyond/mailchimp/testing"
+)
+
+var _ = check.Suite(&CampaignStatusSuite{})
+
+type CampaignStatusSuite struct {
+	client *t.MockClient
+	ctx    context.Context
+}
+
+func (s *CampaignStatusSuite) SetUpSuite(c *check.C) {}
+
+func (s *CampaignStatusSuite) SetUpTest(c *check.C) {
+	s.client = t.NewMockClient()
+	s.ctx = NewContextWithToken(context.Background(), os.Getenv("MAILCHIMP_TEST_TOKEN"))
+}
+
+func (s *CampaignStatusSuite) TearDownTest(c *check.C) {}
+
+func (s *CampaignStatusSuite) Test_CanTransition(c *check.C) {
+	c.Assert(CampaignStatusSave.CanTransition(CampaignActionSend), check.Equals, true)
+	c.Assert(CampaignStatusSchedule.CanTransition(CampaignActionSend), check.Equals, false)
+	c.Assert(CampaignStatusSchedule.CanTransition(CampaignActionUnschedule), check.Equals, true)
+	c.Assert(CampaignStatusSent.CanTransition(CampaignActionCancel), check.Equals, false)
+	c.Assert(CampaignStatusPaused.CanTransition(CampaignActionResume), check.Equals, true)
+	c.Assert(CampaignStatusSending.CanTransition(CampaignActionResume), check.Equals, false)
+
+	// Actions without transitions are not checked
+	c.Assert(CampaignStatusSent.CanTransition(CampaignActionReplicate), check.Equals, true)
+}
+
+func (s *CampaignStatusSuite) Test_InvalidTransition(c *check.C) {
+	campaign := &Campaign{ID: "42694e9e57", Status: CampaignStatusSent, Client: s.client}
+	schedule := &CampaignScheduleData{ScheduleTime: NewTime(time.Date(2017, 2, 4, 13, 15, 0, 0, time.UTC))}
+
+	err := campaign.Send(s.ctx)
+	c.Assert(errors.Is(err, ErrInvalidTransition), check.Equals, true)
+	c.Assert(err, check.ErrorMatches, ".*/actions/send from sent")
+	c.Assert(campaign.Pause(s.ctx), check.ErrorMatches, ErrInvalidTransition.Error()+".*")
+	c.Assert(campaign.Resume(s.ctx), check.ErrorMatches, ErrInvalidTransition.Error()+".*")
+	c.Assert(campaign.Cancel(s.ctx), check.ErrorMatches, ErrInvalidTransition.Error()+".*")
+	c.Assert(campaign.Unschedule(s.ctx), check.ErrorMatches, ErrInvalidTransition.Error()+".*")
+	c.Assert(campaign.Schedule(s.ctx, schedule), check.ErrorMatches, ErrInvalidTransition.Error()+".*")
+
+	s.client.AssertCallCount(c, 0)
+	c.Assert(campaign.Status, check.Equals, CampaignStatusSent)
+}
+
+func (s *CampaignStatusSuite) Test_Transition(c *check.C) {
+	campaign := &Campaign{ID: "42694e9e57", Status: CampaignStatusSave, Client: s.client}
+	schedule := &CampaignScheduleData{ScheduleTime: NewTime(time.Date(2017, 2, 4, 13, 15, 0, 0, time.UTC))}
+
+	c.Assert(campaign.Schedule(s.ctx, schedule), check.IsNil)
+	c.Assert(campaign.Status, check.Equals, CampaignStatusSchedule)
+	c.Assert(campaign.Unschedule(s.ctx), check.IsNil)
+	c.Assert(campaign.Status, check.Equals, CampaignStatusSave)
+	c.Assert(campaign.Send(s.ctx), check.IsNil)
+	c.Assert(campaign.Status, check.Equals, CampaignStatusSending)
+
+	s.client.AssertCalled(c, "POST", "campaigns/42694e9e57/actions/schedule")
+	s.client.AssertCalled(c, "POST", "campaigns/42694e9e57/actions/unschedule")
+	s.client.AssertCalled(c, "POST", "campaigns/42694e9e57/actions/send")
+}
+
+func (s *CampaignStatusSuite) Test_Schedule_Invalid(c *check.C) {
+	campaign := &Campaign{ID: "42694e9e57", Status: CampaignStatusSave, Client: s.client}
+
+	err := campaign.Schedule(s.ctx, &CampaignScheduleData{})
+	c.Assert(errors.Is(err, ErrInvalidSchedule), check.Equals, true)
+	err = campaign.Schedule(s.ctx, nil)
+	c.Assert(errors.Is(err, ErrInvalidSchedule), check.Equals, true)
+
+	s.client.AssertCallCount(c, 0)
+	c.Assert(campaign.Status, check.Equals, CampaignStatusSave)
+}
+
+func (s *CampaignStatusSuite) Test_Transition_Failed(c *check.C) {
+	s.client.Stub("POST", "campaigns/42694e9e57/actions/send", "", Error{Status: 400, Title: "Bad Request"})
+	campaign := &Campaign{ID: "42694e9e57", Status: CampaignStatusSave, Client: s.client}
+
+	c.Assert(campaign.Send(s.ctx), check.ErrorMatches, "Bad Request.*")
+	c.Assert(campaign.Status, check.Equals, CampaignStatusSave)
+}
+
+func (s *CampaignStatusSuite) Test_Transition_UnknownStatus(c *check.C) {
+	campaign := &Campaign{ID: "42694e9e57", Client: s.client}
+
+	c.Assert(campaign.Resume(s.ctx), check.IsNil)
+	c.Assert(campaign.Status, check.Equals, CampaignStatusSending)
+}
+
+func (s *CampaignStatusSuite) Test_RefreshBeforeAction(c *check.C) {
+	s.client.Stub("GET", "campaigns/42694e9e57", `{"id":"42694e9e57","status":"schedule","emails_sent":0}`, nil)
+	campaign := &Campaign{ID: "42694e9e57", Status: CampaignStatusSave, Client: s.client, RefreshBeforeAction: true}
+
+	err := campaign.Send(s.ctx)
+	c.Assert(errors.Is(err, ErrInvalidTransition), check.Equals, true)
+	s.client.AssertNotCalled(c, "POST", "campaigns/42694e9e57/actions/send")
+
+	c.Assert(campaign.Status, check.Equals, CampaignStatusSchedule)
+	c.Assert(campaign.RefreshBeforeAction, check.Equals, true)
+	c.Assert(campaign.Client, check.Equals, s.client)
+
+	c.Assert(campaign.Unschedule(s.ctx), check.IsNil)
+	c.Assert(s.client.CallsTo("GET", "campaigns/42694e9e57"), check.HasLen, 2)
+}
+
+func (s *CampaignStatusSuite) Test_Transition_NoClient(c *check.C) {
+	campaign := &Campaign{ID: "42694e9e57"}
+
+	c.Assert(campaign.Send(s.ctx), check.Equals, ErrorNoClient)
+	c.Assert(campaign.Refresh(s.ctx), check.Equals, ErrorNoClient)
+}
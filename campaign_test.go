@@ -0,0 +1,454 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing/fstest"
+	"time"
+
+	check "gopkg.in/check.v1"
+
+	t "github.com/greatbeyond/mailchimp/testing"
+)
+
+var _ = check.Suite(&CampaignSuite{})
+
+type CampaignSuite struct {
+	client *Client
+	server *t.MockServer
+	ctx    context.Context
+}
+
+func (s *CampaignSuite) SetUpSuite(c *check.C) {}
+
+func (s *CampaignSuite) SetUpTest(c *check.C) {
+	s.server = t.NewMockServer()
+	s.server.SetChecker(c)
+
+	s.client = NewClient()
+	s.client.HTTPClient = s.server.HTTPClient
+
+	s.ctx = NewContextWithToken(context.Background(), os.Getenv("MAILCHIMP_TEST_TOKEN"))
+	s.ctx = NewContextWithURL(s.ctx, "http://us13.api.mailchimp.com/3.0/")
+}
+
+func (s *CampaignSuite) TearDownTest(c *check.C) {}
+
+func (s *CampaignSuite) Test_GetCampaign_Typed(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/campaigns/42694e9e57",
+		Code:   200,
+		Body: `{"id":"42694e9e57","type":"variate","status":"sent",` +
+			`"variate_settings":{"winning_combination_id":"c1","winner_criteria":"opens","wait_time":60,"test_size":20,"subject_lines":["A","B"],"send_times":["2017-02-04T13:15:00+00:00"],"combinations":[{"id":"c1","subject_line":1,"send_time":0,"from_name":0,"reply_to":0,"content_description":0,"recipients":20}]},` +
+			`"tracking":{"opens":true,"salesforce":{"campaign":true,"notes":false},"highrise":{"campaign":false,"notes":true},"capsule":{"notes":true}},` +
+			`"rss_opts":{"feed_url":"http://example.com/rss","frequency":"daily","schedule":{"hour":7,"daily_send":{"monday":true,"friday":true}},"last_sent":"2017-02-03T07:00:00+00:00","constrain_rss_img":true},` +
+			`"social_card":{"image_url":"http://example.com/a.png","description":"Jokes","title":"Hello"},` +
+			`"report_summary":{"opens":10,"unique_opens":8,"open_rate":0.4,"clicks":3,"subscriber_clicks":2,"click_rate":0.1,"ecommerce":{"total_orders":1,"total_spent":5,"total_revenue":5}}}`,
+	})
+
+	campaign, err := s.client.GetCampaign(s.ctx, "42694e9e57")
+	c.Assert(err, check.IsNil)
+
+	variate := campaign.VariateSettings
+	c.Assert(variate.WinnerCriteria, check.Equals, WinnerCriteriaOpens)
+	c.Assert(variate.WaitTime, check.Equals, 60)
+	c.Assert(variate.TestSize, check.Equals, 20)
+	c.Assert(variate.SubjectLines, check.DeepEquals, []string{"A", "B"})
+	c.Assert(variate.SendTimes, check.DeepEquals, []Time{mustParseTime("2017-02-04T13:15:00+00:00")})
+	c.Assert(variate.Combinations, check.DeepEquals, []*VariateCombination{{ID: "c1", SubjectLine: 1, Recipients: 20}})
+
+	c.Assert(campaign.Tracking.Salesforce, check.DeepEquals, &CampaignSalesforce{Campaign: true})
+	c.Assert(campaign.Tracking.Highrise, check.DeepEquals, &CampaignHighrise{Notes: true})
+	c.Assert(campaign.Tracking.Capsule, check.DeepEquals, &CampaignCapsule{Notes: true})
+
+	rss := campaign.RSSOpts
+	c.Assert(rss.Frequency, check.Equals, RSSFrequencyDaily)
+	c.Assert(rss.Schedule.Hour, check.Equals, 7)
+	c.Assert(rss.Schedule.DailySend, check.DeepEquals, &RSSDailySend{Monday: true, Friday: true})
+	c.Assert(*rss.LastSent, check.Equals, mustParseTime("2017-02-03T07:00:00+00:00"))
+
+	c.Assert(campaign.SocialCard, check.DeepEquals, &CampaignSocialCard{ImageURL: "http://example.com/a.png", Description: "Jokes", Title: "Hello"})
+	c.Assert(campaign.ReportSummary.UniqueOpens, check.Equals, 8)
+	c.Assert(campaign.ReportSummary.Ecommerce.TotalOrders, check.Equals, 1)
+	c.Assert(campaign.ABSplitOpts, check.IsNil)
+}
+
+func (s *CampaignSuite) Test_GetCampaign_ABSplit(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/campaigns/42694e9e57",
+		Code:   200,
+		Body: `{"id":"42694e9e57","type":"absplit","status":"sent",` +
+			`"ab_split_opts":{"split_test":"schedule","pick_winner":"opens","wait_units":"hours","wait_time":2,"split_size":10,` +
+			`"send_time_a":"2017-02-04T13:15:00+00:00","send_time_b":"2017-02-04T15:15:00+00:00","send_time_winner":"2017-02-04T17:00:00+00:00"}}`,
+	})
+
+	campaign, err := s.client.GetCampaign(s.ctx, "42694e9e57")
+	c.Assert(err, check.IsNil)
+
+	split := campaign.ABSplitOpts
+	c.Assert(split.SplitTest, check.Equals, "schedule")
+	c.Assert(*split.SendTimeA, check.Equals, mustParseTime("2017-02-04T13:15:00+00:00"))
+	c.Assert(*split.SendTimeB, check.Equals, mustParseTime("2017-02-04T15:15:00+00:00"))
+	c.Assert(*split.SendTimeWinner, check.Equals, mustParseTime("2017-02-04T17:00:00+00:00"))
+}
+
+func (s *CampaignSuite) Test_CreateCampaign_Typed(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "POST",
+		Path:   "/campaigns",
+		JSON: `{"type":"variate","recipients":{"list_id":"57afe96172","segment_opts":{}},` +
+			`"variate_settings":{"winner_criteria":"clicks","wait_time":120,"test_size":30,"subject_lines":["A","B"]},` +
+			`"rss_opts":{"feed_url":"http://example.com/rss","frequency":"monthly","schedule":{"hour":0,"monthly_send_date":1}},` +
+			`"social_card":{"title":"Hello"}}`,
+		Code: 200,
+		Body: `{"id":"42694e9e57","type":"variate"}`,
+	})
+
+	campaign, err := s.client.CreateCampaign(s.ctx, &CreateCampaign{
+		Type:       "variate",
+		Recipients: &CampaignRecipients{ListID: "57afe96172"},
+		VariateSettings: &CampaignVariateSettings{
+			WinnerCriteria: WinnerCriteriaClicks,
+			WaitTime:       120,
+			TestSize:       30,
+			SubjectLines:   []string{"A", "B"},
+		},
+		RssOpts: &CampaignRSSOpts{
+			FeedURL:   "http://example.com/rss",
+			Frequency: RSSFrequencyMonthly,
+			Schedule:  &RSSSchedule{MonthlySendDate: 1},
+		},
+		SocialCard: &CampaignSocialCard{Title: "Hello"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(campaign.ID, check.Equals, "42694e9e57")
+	s.server.VerifyNoMoreRequests(c)
+}
+
+func (s *CampaignSuite) Test_Replicate(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "POST",
+		Path:   "/campaigns/42694e9e57/actions/replicate",
+		Code:   200,
+		Body:   `{"id":"b03bfc273a","type":"regular","status":"save"}`,
+	})
+
+	campaign := s.client.NewCampaign(s.ctx, "42694e9e57")
+	replica, err := campaign.Replicate(s.ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(replica.ID, check.Equals, "b03bfc273a")
+	c.Assert(replica.Status, check.Equals, CampaignStatusSave)
+	c.Assert(replica.Client, check.Equals, s.client)
+	s.server.VerifyNoMoreRequests(c)
+}
+
+func (s *CampaignSuite) Test_CreateResend(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "POST",
+		Path:   "/campaigns/42694e9e57/actions/create-resend",
+		JSON:   `{"shortcut_type":"to_non_clickers"}`,
+		Code:   200,
+		Body:   `{"id":"b03bfc273a","type":"regular","status":"save"}`,
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "POST",
+		Path:   "/campaigns/42694e9e57/actions/create-resend",
+		JSON:   `{}`,
+		Code:   200,
+		Body:   `{"id":"c14cgd384b","type":"regular","status":"save"}`,
+	})
+
+	campaign := s.client.NewCampaign(s.ctx, "42694e9e57")
+	resend, err := campaign.CreateResend(s.ctx, ResendToNonClickers)
+	c.Assert(err, check.IsNil)
+	c.Assert(resend.ID, check.Equals, "b03bfc273a")
+
+	resend, err = campaign.CreateResend(s.ctx, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(resend.ID, check.Equals, "c14cgd384b")
+	s.server.VerifyNoMoreRequests(c)
+}
+
+func (s *CampaignSuite) Test_CreateResend_Error(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "POST",
+		Path:   "/campaigns/42694e9e57/actions/create-resend",
+		Code:   400,
+		Body:   `{"type":"http://developer.mailchimp.com/documentation/mailchimp/guides/error-glossary/","title":"Bad Request","status":400,"detail":"This campaign has not been sent.","instance":""}`,
+	})
+
+	resend, err := s.client.NewCampaign(s.ctx, "42694e9e57").CreateResend(s.ctx, ResendToNonOpeners)
+	c.Assert(err, check.ErrorMatches, "Bad Request \\(400\\): This campaign has not been sent.*")
+	c.Assert(resend, check.IsNil)
+}
+
+func (s *CampaignSuite) Test_SendChecklist(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/campaigns/42694e9e57/send-checklist",
+		Code:   200,
+		Body: `{"is_ready":false,"items":[` +
+			`{"type":"success","id":0,"heading":"List","details":"MailChimp will deliver this to 200 recipients."},` +
+			`{"type":"warning","id":1,"heading":"Subject line","details":"Your subject line is long."},` +
+			`{"type":"error","id":2,"heading":"From email","details":"You need to set a from email address."}]}`,
+	})
+
+	checklist, err := s.client.NewCampaign(s.ctx, "42694e9e57").SendChecklist(s.ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(checklist.IsReady, check.Equals, false)
+	c.Assert(checklist.Items, check.HasLen, 3)
+	c.Assert(checklist.Errors(), check.DeepEquals, []*SendChecklistItem{
+		{Type: ChecklistItemError, ID: 2, Heading: "From email", Details: "You need to set a from email address."},
+	})
+	c.Assert(checklist.Warnings(), check.HasLen, 1)
+	c.Assert(checklist.Warnings()[0].Heading, check.Equals, "Subject line")
+}
+
+func (s *CampaignSuite) Test_Actions_NoClient(c *check.C) {
+	campaign := &Campaign{ID: "42694e9e57"}
+
+	_, err := campaign.Replicate(s.ctx)
+	c.Assert(err, check.Equals, ErrorNoClient)
+	_, err = campaign.CreateResend(s.ctx, ResendToNonOpeners)
+	c.Assert(err, check.Equals, ErrorNoClient)
+	_, err = campaign.SendChecklist(s.ctx)
+	c.Assert(err, check.Equals, ErrorNoClient)
+}
+
+func (s *CampaignSuite) Test_WaitForDelivery(c *check.C) {
+	for _, body := range []string{
+		`{"id":"42694e9e57","status":"sending","delivery_status":{"enabled":true,"can_cancel":true,"status":"delivering","emails_sent":10,"emails_canceled":0}}`,
+		`{"id":"42694e9e57","status":"sending","delivery_status":{"enabled":true,"can_cancel":true,"status":"delivering","emails_sent":60,"emails_canceled":0}}`,
+		`{"id":"42694e9e57","status":"sent","emails_sent":100,"delivery_status":{"enabled":true,"can_cancel":false,"status":"delivered","emails_sent":100,"emails_canceled":0}}`,
+	} {
+		s.server.AddResponse(&t.MockResponse{
+			Method: "GET",
+			Path:   "/campaigns/42694e9e57",
+			Code:   200,
+			Body:   body,
+		})
+	}
+
+	campaign := &Campaign{ID: "42694e9e57", Status: CampaignStatusSending, Client: s.client}
+
+	progress := []int{}
+	err := campaign.WaitForDelivery(s.ctx, time.Millisecond, func(status CampaignDeliveryStatus) {
+		progress = append(progress, status.EmailsSent)
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(progress, check.DeepEquals, []int{10, 60, 100})
+	c.Assert(campaign.Status, check.Equals, CampaignStatusSent)
+	c.Assert(campaign.EmailsSent, check.Equals, 100)
+	c.Assert(campaign.DeliveryStatus, check.DeepEquals, CampaignDeliveryStatus{
+		Enabled:    true,
+		Status:     DeliveryStatusDelivered,
+		EmailsSent: 100,
+	})
+	c.Assert(campaign.Client, check.Equals, s.client)
+}
+
+func (s *CampaignSuite) Test_WaitForDelivery_Canceled(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/campaigns/42694e9e57",
+		Code:   200,
+		Body:   `{"id":"42694e9e57","status":"canceled","delivery_status":{"enabled":true,"status":"canceled","emails_sent":10,"emails_canceled":90}}`,
+	})
+
+	campaign := &Campaign{ID: "42694e9e57", Client: s.client}
+	c.Assert(campaign.WaitForDelivery(s.ctx, time.Hour, nil), check.IsNil)
+	c.Assert(campaign.DeliveryStatus.EmailsCanceled, check.Equals, 90)
+}
+
+func (s *CampaignSuite) Test_WaitForDelivery_Context(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method:     "GET",
+		Path:       "/campaigns/42694e9e57",
+		Code:       200,
+		Body:       `{"id":"42694e9e57","status":"sending","delivery_status":{"enabled":true,"status":"delivering"}}`,
+		Persistant: true,
+	})
+
+	ctx, cancel := context.WithTimeout(s.ctx, 20*time.Millisecond)
+	defer cancel()
+
+	polls := 0
+	campaign := &Campaign{ID: "42694e9e57", Client: s.client}
+	err := campaign.WaitForDelivery(ctx, time.Millisecond, func(CampaignDeliveryStatus) { polls++ })
+	c.Assert(err, check.Equals, context.DeadlineExceeded)
+	c.Assert(polls > 1, check.Equals, true)
+	c.Assert(campaign.Status, check.Equals, CampaignStatusSending)
+}
+
+func (s *CampaignSuite) Test_WaitForDelivery_Error(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/campaigns/42694e9e57",
+		Code:   404,
+		Body:   `{"type":"http://developer.mailchimp.com/documentation/mailchimp/guides/error-glossary/","title":"Resource Not Found","status":404,"detail":"The requested resource could not be found."}`,
+	})
+
+	campaign := &Campaign{ID: "42694e9e57", Client: s.client}
+	err := campaign.WaitForDelivery(s.ctx, time.Millisecond, nil)
+	c.Assert(IsNotFound(err), check.Equals, true)
+
+	c.Assert((&Campaign{}).WaitForDelivery(s.ctx, time.Millisecond, nil), check.Equals, ErrorNoClient)
+	c.Assert(campaign.WaitForDelivery(s.ctx, 0, nil), check.ErrorMatches, "invalid poll interval 0s")
+	c.Assert(campaign.WaitForDelivery(s.ctx, -time.Second, nil), check.ErrorMatches, "invalid poll interval -1s")
+}
+
+func (s *CampaignSuite) Test_NewSchedule(c *check.C) {
+	cet := time.FixedZone("CET", 3600)
+
+	schedule, err := NewSchedule(time.Date(2017, 2, 4, 20, 15, 0, 0, cet), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(schedule.ScheduleTime.Location(), check.Equals, time.UTC)
+	js, err := json.Marshal(schedule)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(js), check.Equals, `{"schedule_time":"2017-02-04T19:15:00Z"}`)
+
+	_, err = NewSchedule(time.Date(2017, 2, 4, 20, 10, 0, 0, cet), nil)
+	c.Assert(errors.Is(err, ErrInvalidSchedule), check.Equals, true)
+	c.Assert(err, check.ErrorMatches, ".*2017-02-04T19:10:00Z is not on the quarter-hour")
+	_, err = NewSchedule(time.Date(2017, 2, 4, 20, 15, 1, 0, cet), nil)
+	c.Assert(errors.Is(err, ErrInvalidSchedule), check.Equals, true)
+	_, err = NewSchedule(time.Time{}, nil)
+	c.Assert(err, check.ErrorMatches, ".*missing schedule time")
+
+	schedule, err = NewSchedule(time.Date(2017, 2, 4, 20, 10, 5, 0, cet), &ScheduleOptions{RoundUp: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(schedule.ScheduleTime.String(), check.Equals, "2017-02-04T19:15:00Z")
+	schedule, err = NewSchedule(time.Date(2017, 2, 4, 23, 45, 0, 1, time.UTC), &ScheduleOptions{RoundUp: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(schedule.ScheduleTime.String(), check.Equals, "2017-02-05T00:00:00Z")
+	schedule, err = NewSchedule(time.Date(2017, 2, 4, 19, 30, 0, 0, time.UTC), &ScheduleOptions{RoundUp: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(schedule.ScheduleTime.String(), check.Equals, "2017-02-04T19:30:00Z")
+}
+
+func (s *CampaignSuite) Test_NewSchedule_Delivery(c *check.C) {
+	at := time.Date(2017, 2, 4, 19, 15, 0, 0, time.UTC)
+
+	schedule, err := NewSchedule(at, &ScheduleOptions{Timewarp: true})
+	c.Assert(err, check.IsNil)
+	js, _ := json.Marshal(schedule)
+	c.Assert(string(js), check.Equals, `{"schedule_time":"2017-02-04T19:15:00Z","timewarp":true}`)
+
+	schedule, err = NewSchedule(at, &ScheduleOptions{BatchDelivery: &CampaignBatchDelivery{BatchDelay: 10, BatchCount: 3}})
+	c.Assert(err, check.IsNil)
+	js, _ = json.Marshal(schedule)
+	c.Assert(string(js), check.Equals, `{"schedule_time":"2017-02-04T19:15:00Z","batch_delivery":{"batch_delay":10,"batch_count":3}}`)
+
+	_, err = NewSchedule(at, &ScheduleOptions{Timewarp: true, BatchDelivery: &CampaignBatchDelivery{BatchDelay: 10, BatchCount: 3}})
+	c.Assert(errors.Is(err, ErrInvalidSchedule), check.Equals, true)
+	c.Assert(err, check.ErrorMatches, ".*timewarp can not be used with batch delivery")
+
+	_, err = NewSchedule(at, &ScheduleOptions{BatchDelivery: &CampaignBatchDelivery{BatchCount: 3}})
+	c.Assert(errors.Is(err, ErrInvalidSchedule), check.Equals, true)
+}
+
+func (s *CampaignSuite) Test_GetContent(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/campaigns/42694e9e57/content",
+		Code:   200,
+		Body: `{"variate_contents":[{"content_label":"A","html":"<p>A</p>","plain_text":"A"},{"content_label":"B","html":"<p>B</p>","plain_text":"B"}],` +
+			`"plain_text":"A","html":"<p>A</p>","archive_html":"<html><p>A</p></html>"}`,
+	})
+
+	campaign := &Campaign{ID: "42694e9e57", Client: s.client}
+	content, err := campaign.GetContent(s.ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(content.VariateContents, check.DeepEquals, []*VariateContent{
+		{ContentLabel: "A", HTML: "<p>A</p>", PlainText: "A"},
+		{ContentLabel: "B", HTML: "<p>B</p>", PlainText: "B"},
+	})
+	c.Assert(content.HTML, check.Equals, "<p>A</p>")
+	c.Assert(content.ArchiveHTML, check.Equals, "<html><p>A</p></html>")
+}
+
+func (s *CampaignSuite) Test_SetContent_Sections(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "PUT",
+		Path:   "/campaigns/42694e9e57/content",
+		JSON:   `{"template":{"id":42,"sections":{"body":"<p>World</p>","header":"<h1>Hello</h1>"}}}`,
+		Code:   200,
+		Body:   `{"plain_text":"Hello World","html":"<h1>Hello</h1><p>World</p>"}`,
+	})
+
+	edit := &CampaignContentEdit{Template: &CampaignContentTemplate{ID: 42}}
+	edit.SetSection("header", "<h1>Hello</h1>")
+	edit.SetSection("body", "<p>World</p>")
+
+	campaign := &Campaign{ID: "42694e9e57", Client: s.client}
+	content, err := campaign.SetContent(s.ctx, edit)
+	c.Assert(err, check.IsNil)
+	c.Assert(content.PlainText, check.Equals, "Hello World")
+
+	edit = &CampaignContentEdit{}
+	edit.SetSection("body", "<p>World</p>")
+	c.Assert(edit.Template, check.DeepEquals, &CampaignContentTemplate{Sections: map[string]string{"body": "<p>World</p>"}})
+}
+
+func (s *CampaignSuite) Test_NewArchive(c *check.C) {
+	archive, err := NewArchive(fstest.MapFS{
+		"index.html":      {Data: []byte("<p>Hello</p>")},
+		"images/logo.png": {Data: []byte("png")},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(archive.ArchiveType, check.Equals, ArchiveTypeZip)
+
+	data, err := base64.StdEncoding.DecodeString(archive.ArchiveContent)
+	c.Assert(err, check.IsNil)
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	c.Assert(err, check.IsNil)
+
+	files := map[string]string{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		c.Assert(err, check.IsNil)
+		b, err := ioutil.ReadAll(rc)
+		c.Assert(err, check.IsNil)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	c.Assert(files, check.DeepEquals, map[string]string{
+		"index.html":      "<p>Hello</p>",
+		"images/logo.png": "png",
+	})
+
+	js, err := json.Marshal(&CampaignContentEdit{Archive: archive})
+	c.Assert(err, check.IsNil)
+	c.Assert(string(js), check.Matches, `\{"archive":\{"archive_content":"[A-Za-z0-9+/=]+","archive_type":"zip"\}\}`)
+}
+
+func (s *CampaignSuite) Test_CampaignContentEdit_VariateContents(c *check.C) {
+	js, err := json.Marshal(&CampaignContentEdit{VariateContents: []*VariateContent{
+		{ContentLabel: "A", HTML: "<p>A</p>"},
+		{ContentLabel: "B", HTML: "<p>B</p>", PlainText: "B"},
+	}})
+	c.Assert(err, check.IsNil)
+	c.Assert(string(js), check.Equals, `{"variate_contents":[{"content_label":"A","html":"\u003cp\u003eA\u003c/p\u003e"},{"content_label":"B","plain_text":"B","html":"\u003cp\u003eB\u003c/p\u003e"}]}`)
+}
@@ -0,0 +1,78 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"context"
+	"errors"
+
+	check "gopkg.in/check.v1"
+
+	t "github.com/greatbeyond/mailchimp/testing"
+)
+
+// MockClient must keep up with the interface
+var _ MailchimpClient = (*t.MockClient)(nil)
+
+var _ = check.Suite(&ClientSuite{})
+
+type ClientSuite struct {
+	client *t.MockClient
+	ctx    context.Context
+}
+
+func (s *ClientSuite) SetUpSuite(c *check.C) {}
+
+func (s *ClientSuite) SetUpTest(c *check.C) {
+	s.client = t.NewMockClient()
+	s.ctx = context.Background()
+}
+
+func (s *ClientSuite) TearDownTest(c *check.C) {}
+
+func (s *ClientSuite) Test_MockClient_Member(c *check.C) {
+	s.client.Stub("PUT", "/lists/*/members/*", `{"id":"abc","status":"unsubscribed"}`, nil)
+
+	member := &Member{ID: "abc", ListID: "1", Client: s.client}
+	updated, err := member.Update(s.ctx, &UpdateMember{Status: Unsubscribed})
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Status, check.Equals, Unsubscribed)
+	c.Assert(updated.Client, check.Equals, s.client)
+
+	call := s.client.AssertCalled(c, "PUT", "/lists/1/members/abc")
+	c.Assert(call.JSON(), check.Equals, `{"status":"unsubscribed"}`)
+	s.client.AssertCallCount(c, 1)
+}
+
+func (s *ClientSuite) Test_MockClient_Error(c *check.C) {
+	s.client.Stub("DELETE", "/lists/*", "", errors.New("boom"))
+
+	list := &List{ID: "1", Client: s.client}
+	c.Assert(list.Delete(s.ctx), check.ErrorMatches, "boom")
+	s.client.AssertNotCalled(c, "GET", "")
+}
+
+func (s *ClientSuite) Test_MockClient_Parameters(c *check.C) {
+	s.client.Stub("GET", "/lists/*/segments/*/members", `{"members":[{"id":"abc"}],"total_items":1}`, nil)
+
+	segment := &Segment{ID: 2, ListID: "1", Client: s.client}
+	members, err := segment.GetMembers(s.ctx, Parameters{"count": 10})
+	c.Assert(err, check.IsNil)
+	c.Assert(members, check.HasLen, 1)
+	c.Assert(members[0].Client, check.Equals, s.client)
+
+	call := s.client.AssertCalled(c, "GET", "/lists/1/segments/2/members")
+	c.Assert(call.Parameters, check.DeepEquals, map[string]interface{}{"count": 10})
+}
@@ -0,0 +1,384 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package csvimport imports list members from CSV files.
+//
+//  importer := csvimport.New(client, listID, &csvimport.Mapping{
+//      EmailAddress: "E-mail",
+//      MergeFields: map[string]string{
+//          "First name": "FNAME",
+//          "Last name":  "LNAME",
+//      },
+//  })
+//  result, err := importer.Import(ctx, file, rejects)
+package csvimport
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/greatbeyond/mailchimp"
+)
+
+// Mapping maps CSV column headers to member fields
+type Mapping struct {
+	// Header of the email address column. (required)
+	EmailAddress string
+
+	// Header of the status column. Rows with an empty status, or all rows
+	// if no column is given, get DefaultStatus.
+	Status string
+
+	// Status used when the row has none. Defaults to subscribed.
+	DefaultStatus mailchimp.MemberStatus
+
+	// Maps column headers to merge field tags.
+	MergeFields map[string]string
+}
+
+// Result summarizes an import
+type Result struct {
+	// Number of data rows read, excluding the header.
+	Rows int
+
+	Created  int
+	Updated  int
+	Rejected int
+}
+
+// Importer reads members from CSV and uploads them with bulk subscribe
+type Importer struct {
+	ListID  string
+	Mapping *Mapping
+
+	// UpdateExisting changes the status and merge fields of members
+	// already on the list.
+	UpdateExisting bool
+
+	// ChunkSize is the number of members sent per request. Defaults to,
+	// and can not exceed, 500.
+	ChunkSize int
+
+	Client mailchimp.MailchimpClient
+}
+
+// New returns a importer for a list
+func New(client mailchimp.MailchimpClient, listID string, mapping *Mapping) *Importer {
+	return &Importer{
+		ListID:  listID,
+		Mapping: mapping,
+		Client:  client,
+	}
+}
+
+// row is a validated csv row waiting for upload
+type row struct {
+	number int
+	member *mailchimp.CreateMember
+}
+
+// Import reads the csv from r, validates each row against the list's merge
+// fields and uploads the valid rows. Every rejected row is written to
+// rejects as a csv with the columns row, email_address and reason. Members
+// mailchimp rejects that can't be matched to a row get an empty row and a
+// reason starting with "unknown row". rejects may be nil.
+func (i *Importer) Import(ctx context.Context, r io.Reader, rejects io.Writer) (*Result, error) {
+	if i.Client == nil {
+		return nil, mailchimp.ErrorNoClient
+	}
+	if i.Mapping == nil || i.Mapping.EmailAddress == "" {
+		return nil, fmt.Errorf("missing field: Mapping.EmailAddress")
+	}
+
+	list := &mailchimp.List{ID: i.ListID, Client: i.Client}
+
+	fields, err := list.GetMergeFields(ctx, mailchimp.Parameters{"count": 1000})
+	if err != nil {
+		return nil, err
+	}
+
+	definitions := map[string]*mailchimp.MergeField{}
+	for _, field := range fields {
+		definitions[field.Tag] = field
+	}
+
+	for _, tag := range i.Mapping.MergeFields {
+		if _, ok := definitions[tag]; !ok {
+			return nil, fmt.Errorf("unknown merge field: %s", tag)
+		}
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for n, name := range header {
+		columns[strings.TrimSpace(name)] = n
+	}
+
+	for _, name := range i.columnNames() {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column: %s", name)
+		}
+	}
+
+	var rejectWriter *csv.Writer
+	if rejects != nil {
+		rejectWriter = csv.NewWriter(rejects)
+		rejectWriter.Write([]string{"row", "email_address", "reason"})
+	}
+
+	result := &Result{}
+	// Rejects that can't be traced back to a row have number 0, their row
+	// is left empty
+	reject := func(number int, email string, reason string) {
+		result.Rejected++
+		if rejectWriter != nil {
+			row := ""
+			if number > 0 {
+				row = strconv.Itoa(number)
+			}
+			rejectWriter.Write([]string{row, email, reason})
+		}
+	}
+
+	rows := []*row{}
+	seen := map[string]int{}
+
+	// The header is row 1, same as a spreadsheet
+	for number := 2; ; number++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Rows++
+
+		if len(record) != len(header) {
+			reject(number, "", fmt.Sprintf("expected %d fields, got %d", len(header), len(record)))
+			continue
+		}
+
+		member, err := i.parseRow(record, columns, definitions)
+		if err != nil {
+			reject(number, member.EmailAddress, err.Error())
+			continue
+		}
+
+		id := mailchimp.MemberEmailToID(member.EmailAddress)
+		if first, ok := seen[id]; ok {
+			reject(number, member.EmailAddress, fmt.Sprintf("duplicate of row %d", first))
+			continue
+		}
+		seen[id] = number
+
+		rows = append(rows, &row{number: number, member: member})
+	}
+
+	err = i.upload(ctx, list, rows, result, reject)
+
+	if rejectWriter != nil {
+		rejectWriter.Flush()
+		if werr := rejectWriter.Error(); werr != nil && err == nil {
+			err = werr
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// upload sends the rows in chunks and rejects the members mailchimp refused
+func (i *Importer) upload(ctx context.Context, list *mailchimp.List, rows []*row, result *Result, reject func(int, string, string)) error {
+	size := i.ChunkSize
+	if size <= 0 || size > 500 {
+		size = 500
+	}
+
+	for start := 0; start < len(rows); start += size {
+		end := start + size
+		if end > len(rows) {
+			end = len(rows)
+		}
+		chunk := rows[start:end]
+
+		data := &mailchimp.BatchSubscribe{
+			UpdateExisting: i.UpdateExisting,
+		}
+		numbers := map[string]int{}
+		for _, r := range chunk {
+			data.Members = append(data.Members, r.member)
+			numbers[mailchimp.MemberEmailToID(r.member.EmailAddress)] = r.number
+		}
+
+		response, err := list.BatchSubscribe(ctx, data)
+		if err != nil {
+			return err
+		}
+
+		result.Created += response.TotalCreated
+		result.Updated += response.TotalUpdated
+
+		for _, e := range response.Errors {
+			number, ok := numbers[mailchimp.MemberEmailToID(strings.TrimSpace(e.EmailAddress))]
+			if !ok {
+				reject(0, e.EmailAddress, "unknown row: "+e.Error)
+				continue
+			}
+			reject(number, e.EmailAddress, e.Error)
+		}
+	}
+
+	return nil
+}
+
+// parseRow maps a record to a member and validates it. The returned member
+// is never nil so the email can be reported for rejected rows.
+func (i *Importer) parseRow(record []string, columns map[string]int, definitions map[string]*mailchimp.MergeField) (*mailchimp.CreateMember, error) {
+	member := &mailchimp.CreateMember{
+		EmailAddress: strings.TrimSpace(record[columns[i.Mapping.EmailAddress]]),
+		Status:       i.Mapping.DefaultStatus,
+	}
+
+	if member.Status == "" {
+		member.Status = mailchimp.Subscribed
+	}
+
+	if member.EmailAddress == "" {
+		return member, fmt.Errorf("missing email address")
+	}
+	if !validEmail(member.EmailAddress) {
+		return member, fmt.Errorf("invalid email address")
+	}
+
+	if i.Mapping.Status != "" {
+		if status := strings.TrimSpace(record[columns[i.Mapping.Status]]); status != "" {
+			member.Status = mailchimp.MemberStatus(strings.ToLower(status))
+		}
+	}
+
+	switch member.Status {
+	case mailchimp.Subscribed, mailchimp.Unsubscribed, mailchimp.Cleaned, mailchimp.Pending:
+	default:
+		return member, fmt.Errorf("invalid status: %s", member.Status)
+	}
+
+	merges := map[string]interface{}{}
+	for _, column := range sortedKeys(i.Mapping.MergeFields) {
+		tag := i.Mapping.MergeFields[column]
+		value := strings.TrimSpace(record[columns[column]])
+		if err := validateMergeValue(definitions[tag], value); err != nil {
+			return member, err
+		}
+		if value != "" {
+			merges[tag] = value
+		}
+	}
+
+	tags := []string{}
+	for tag := range definitions {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	for _, tag := range tags {
+		if definitions[tag].Required && merges[tag] == nil {
+			return member, fmt.Errorf("missing required merge field: %s", tag)
+		}
+	}
+
+	if len(merges) > 0 {
+		member.MergeFields = merges
+	}
+
+	return member, nil
+}
+
+// validateMergeValue checks value against the merge field type. Empty
+// values are always valid here, required fields are checked by the caller.
+func validateMergeValue(field *mailchimp.MergeField, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	switch field.Type {
+	case mailchimp.MergeFieldTypeNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%s: not a number", field.Tag)
+		}
+
+	case mailchimp.MergeFieldTypeEmail:
+		if !validEmail(value) {
+			return fmt.Errorf("%s: invalid email address", field.Tag)
+		}
+
+	case mailchimp.MergeFieldTypeURL, mailchimp.MergeFieldTypeImageurl:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%s: invalid url", field.Tag)
+		}
+
+	case mailchimp.MergeFieldTypeRadio, mailchimp.MergeFieldTypeDropdown:
+		choices, _ := field.Options["choices"].([]interface{})
+		for _, choice := range choices {
+			if fmt.Sprint(choice) == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: %q is not a valid choice", field.Tag, value)
+	}
+
+	return nil
+}
+
+// validEmail accepts bare addresses only, not "Name <address>"
+func validEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
+
+// columnNames returns all headers used by the mapping
+func (i *Importer) columnNames() []string {
+	names := []string{i.Mapping.EmailAddress}
+	if i.Mapping.Status != "" {
+		names = append(names, i.Mapping.Status)
+	}
+	return append(names, sortedKeys(i.Mapping.MergeFields)...)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
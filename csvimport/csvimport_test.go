@@ -0,0 +1,234 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csvimport
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	check "gopkg.in/check.v1"
+
+	"github.com/greatbeyond/mailchimp"
+	t "github.com/greatbeyond/mailchimp/testing"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test_CSVImport(t *testing.T) { check.TestingT(t) }
+
+var _ = check.Suite(&ImportSuite{})
+
+type ImportSuite struct {
+	client *mailchimp.Client
+	server *t.MockServer
+	ctx    context.Context
+}
+
+func (s *ImportSuite) SetUpSuite(c *check.C) {}
+
+func (s *ImportSuite) SetUpTest(c *check.C) {
+	s.server = t.NewMockServer()
+	s.server.SetChecker(c)
+
+	s.client = mailchimp.NewClient()
+	s.client.HTTPClient = s.server.HTTPClient
+
+	s.ctx = mailchimp.NewContextWithToken(context.Background(), os.Getenv("MAILCHIMP_TEST_TOKEN"))
+	// We need http to use the mock server
+	s.ctx = mailchimp.NewContextWithURL(s.ctx, "http://us13.api.mailchimp.com/3.0/")
+}
+
+func (s *ImportSuite) TearDownTest(c *check.C) {}
+
+const mergeFieldsResponse = `{"merge_fields":[
+	{"merge_id":1,"tag":"FNAME","name":"First Name","type":"text","required":true},
+	{"merge_id":2,"tag":"AGE","name":"Age","type":"number"},
+	{"merge_id":3,"tag":"SIZE","name":"Size","type":"dropdown","options":{"choices":["S","M","L"]}}
+],"list_id":"57afe96172","total_items":3}`
+
+func (s *ImportSuite) mapping() *Mapping {
+	return &Mapping{
+		EmailAddress: "E-mail",
+		Status:       "Status",
+		MergeFields: map[string]string{
+			"Name": "FNAME",
+			"Age":  "AGE",
+			"Size": "SIZE",
+		},
+	}
+}
+
+func (s *ImportSuite) Test_Import_Normal(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   mergeFieldsResponse,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(r.RequestURI, check.Equals, "http://us13.api.mailchimp.com/3.0/lists/57afe96172/merge-fields?count=1000")
+		},
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "POST",
+		Code:   200,
+		Body:   `{"new_members":[],"updated_members":[],"errors":[{"email_address":"fake@example.net","error":"looks fake"}],"total_created":1,"total_updated":1,"error_count":1}`,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(r.RequestURI, check.Equals, "http://us13.api.mailchimp.com/3.0/lists/57afe96172")
+			c.Assert(body, check.Equals, `{"members":[`+
+				`{"email_address":"ann@example.net","status":"subscribed","merge_fields":{"AGE":"30","FNAME":"Ann","SIZE":"M"}},`+
+				`{"email_address":"bob@example.net","status":"unsubscribed","merge_fields":{"FNAME":"Bob"}},`+
+				`{"email_address":"fake@example.net","status":"subscribed","merge_fields":{"FNAME":"Fake"}}],`+
+				`"update_existing":true}`)
+		},
+	})
+
+	input := strings.Join([]string{
+		"E-mail,Name,Age,Size,Status",
+		"ann@example.net,Ann,30,M,",
+		"bob@example.net,Bob,,,Unsubscribed",
+		"not an email,Cid,,,",
+		"dan@example.net,Dan,old,,",
+		"eve@example.net,Eve,,XL,",
+		"frank@example.net,,,,",
+		"ANN@example.net,Ann again,,,",
+		"fake@example.net,Fake,,,",
+		"short@example.net,Short",
+		"gus@example.net,Gus,,,gone",
+	}, "\n")
+
+	importer := New(s.client, "57afe96172", s.mapping())
+	importer.UpdateExisting = true
+
+	rejects := &bytes.Buffer{}
+	result, err := importer.Import(s.ctx, strings.NewReader(input), rejects)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, &Result{
+		Rows:     10,
+		Created:  1,
+		Updated:  1,
+		Rejected: 8,
+	})
+	c.Assert(rejects.String(), check.Equals, strings.Join([]string{
+		"row,email_address,reason",
+		"4,not an email,invalid email address",
+		"5,dan@example.net,AGE: not a number",
+		`6,eve@example.net,"SIZE: ""XL"" is not a valid choice"`,
+		"7,frank@example.net,missing required merge field: FNAME",
+		"8,ANN@example.net,duplicate of row 2",
+		"10,,\"expected 5 fields, got 2\"",
+		"11,gus@example.net,invalid status: gone",
+		"9,fake@example.net,looks fake",
+		"",
+	}, "\n"))
+	s.server.VerifyNoMoreRequests(c)
+}
+
+func (s *ImportSuite) Test_Import_Chunks(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   mergeFieldsResponse,
+	})
+	for _, email := range []string{"a", "c"} {
+		email := email
+		s.server.AddResponse(&t.MockResponse{
+			Method: "POST",
+			Code:   200,
+			Body:   `{"total_created":2}`,
+			CheckFn: func(r *http.Request, body string) {
+				c.Assert(strings.HasPrefix(body, `{"members":[{"email_address":"`+email+`@example.net"`), check.Equals, true)
+			},
+		})
+	}
+
+	input := "E-mail,Name\na@example.net,A\nb@example.net,B\nc@example.net,C\n"
+
+	importer := New(s.client, "57afe96172", &Mapping{
+		EmailAddress: "E-mail",
+		MergeFields:  map[string]string{"Name": "FNAME"},
+	})
+	importer.ChunkSize = 2
+
+	result, err := importer.Import(s.ctx, strings.NewReader(input), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Created, check.Equals, 4)
+	s.server.VerifyNoMoreRequests(c)
+}
+
+func (s *ImportSuite) Test_Import_MockClient(c *check.C) {
+	client := t.NewMockClient()
+	client.Stub("GET", "/lists/57afe96172/merge-fields", mergeFieldsResponse, nil)
+	client.Stub("POST", "/lists/57afe96172", `{"errors":[`+
+		`{"email_address":"Ann@Example.net","error":"looks fake"},`+
+		`{"email_address":"who@example.net","error":"looks fake"}],"total_created":0}`, nil)
+
+	importer := New(client, "57afe96172", &Mapping{
+		EmailAddress: "E-mail",
+		MergeFields:  map[string]string{"Name": "FNAME"},
+	})
+
+	rejects := &bytes.Buffer{}
+	result, err := importer.Import(s.ctx, strings.NewReader("E-mail,Name\nann@example.net,Ann\n"), rejects)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Rejected, check.Equals, 2)
+	c.Assert(rejects.String(), check.Equals, strings.Join([]string{
+		"row,email_address,reason",
+		"2,Ann@Example.net,looks fake",
+		",who@example.net,unknown row: looks fake",
+		"",
+	}, "\n"))
+
+	call := client.AssertCalled(c, "POST", "/lists/57afe96172")
+	c.Assert(call.JSON(), check.Equals, `{"members":[{"email_address":"ann@example.net","status":"subscribed","merge_fields":{"FNAME":"Ann"}}],"update_existing":false}`)
+}
+
+func (s *ImportSuite) Test_Import_UnknownMergeField(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   mergeFieldsResponse,
+	})
+
+	importer := New(s.client, "57afe96172", &Mapping{
+		EmailAddress: "E-mail",
+		MergeFields:  map[string]string{"Phone": "PHONE"},
+	})
+
+	_, err := importer.Import(s.ctx, strings.NewReader("E-mail,Phone\n"), nil)
+	c.Assert(err, check.ErrorMatches, "unknown merge field: PHONE")
+}
+
+func (s *ImportSuite) Test_Import_MissingColumn(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   mergeFieldsResponse,
+	})
+
+	_, err := New(s.client, "57afe96172", s.mapping()).Import(s.ctx, strings.NewReader("E-mail,Name,Status\n"), nil)
+	c.Assert(err, check.ErrorMatches, "missing column: Age")
+}
+
+func (s *ImportSuite) Test_Import_MissingMapping(c *check.C) {
+	_, err := New(s.client, "57afe96172", &Mapping{}).Import(s.ctx, strings.NewReader(""), nil)
+	c.Assert(err, check.ErrorMatches, "missing field: Mapping.EmailAddress")
+}
+
+func (s *ImportSuite) Test_Import_NoClient(c *check.C) {
+	_, err := New(nil, "57afe96172", s.mapping()).Import(s.ctx, strings.NewReader(""), nil)
+	c.Assert(err, check.ErrorMatches, "no client assigned by parent")
+}
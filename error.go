@@ -15,7 +15,10 @@
 package mailchimp
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"strings"
 )
 
@@ -25,15 +28,43 @@ import (
 // at creation with Object{client: myClient}.
 var ErrorNoClient = fmt.Errorf("no client assigned by parent")
 
+// Sentinel errors matching classes of api errors with errors.Is.
+//
+//  if errors.Is(err, mailchimp.ErrorMemberExists) {
+//      return member.Update(ctx, data)
+//  }
+var (
+	// ErrorNotFound matches 404 responses
+	ErrorNotFound = errors.New("mailchimp: not found")
+
+	// ErrorRateLimited matches 429 responses
+	ErrorRateLimited = errors.New("mailchimp: rate limited")
+
+	// ErrorMemberExists matches adding a member already on the list
+	ErrorMemberExists = errors.New("mailchimp: member exists")
+
+	// ErrorCompliance matches adding addresses Mailchimp refuses for
+	// compliance reasons, such as forgotten, fake or previously
+	// unsubscribed and cleaned addresses.
+	ErrorCompliance = errors.New("mailchimp: compliance")
+
+	// ErrorRetryable matches errors that may succeed if the request is
+	// sent again later, such as rate limits and 5xx responses.
+	ErrorRetryable = errors.New("mailchimp: retryable")
+)
+
 // Error holds information about a failed call.
-// You should cast this to a mailchimp.Error to gain more information
-// about the status of your request.
+// Use the Is functions or errors.Is with the sentinel errors to check
+// for common errors, or errors.As to gain more information about the
+// status of your request.
 //
 //  if err != nil {
-//      if err, ok := err.(mailchimp.Error); ok {
-//          if err.Status == http.StatusNotFound {
-//              return nil // we don't care about this error
-//          }
+//      if mailchimp.IsNotFound(err) {
+//          return nil // we don't care about this error
+//      }
+//      var e mailchimp.Error
+//      if errors.As(err, &e) {
+//          log.Printf("request %s failed: %s", e.RequestID, e.Body)
 //      }
 //      return err
 //  }
@@ -60,6 +91,17 @@ type Error struct {
 
 	// A collection of error messages
 	Errors []*ErrorMessage `json:"errors"`
+
+	// The raw response body, kept even if it is not valid JSON.
+	Body []byte `json:"-"`
+
+	// The method and url of the failed request.
+	Method string `json:"-"`
+	URL    string `json:"-"`
+
+	// The X-Request-Id response header. Please provide this ID,
+	// together with Instance, when contacting support.
+	RequestID string `json:"-"`
 }
 
 // ErrorMessage contains field specific error information
@@ -79,3 +121,68 @@ func (e Error) Error() string {
 	}
 	return fmt.Sprintf("%s (%d): %s%s", e.Title, e.Status, e.Detail, errorstr)
 }
+
+// Is fulfills errors.Is for the sentinel errors
+func (e Error) Is(target error) bool {
+	switch target {
+	case ErrorNotFound:
+		return e.Status == http.StatusNotFound
+	case ErrorRateLimited:
+		return e.Status == http.StatusTooManyRequests
+	case ErrorMemberExists:
+		return e.Status == http.StatusBadRequest && e.Title == "Member Exists"
+	case ErrorCompliance:
+		return e.isCompliance()
+	case ErrorRetryable:
+		switch e.Status {
+		case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+	}
+	return false
+}
+
+func (e Error) isCompliance() bool {
+	if e.Status != http.StatusBadRequest {
+		return false
+	}
+	switch e.Title {
+	case "Forgotten Email Not Subscribed", "Member In Compliance State":
+		return true
+	case "Invalid Resource":
+		return strings.Contains(e.Detail, "looks fake or invalid")
+	}
+	return false
+}
+
+// IsNotFound reports whether the resource does not exist
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrorNotFound)
+}
+
+// IsRateLimited reports whether too many requests were made
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrorRateLimited)
+}
+
+// IsMemberExists reports whether a member being added already is on the list
+func IsMemberExists(err error) bool {
+	return errors.Is(err, ErrorMemberExists)
+}
+
+// IsCompliance reports whether a address was refused because it was
+// forgotten, looks fake or is in a compliance state
+func IsCompliance(err error) bool {
+	return errors.Is(err, ErrorCompliance)
+}
+
+// IsRetryable reports whether the request may succeed if sent again later.
+// Besides api errors, network timeouts are retryable.
+func IsRetryable(err error) bool {
+	if errors.Is(err, ErrorRetryable) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,113 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+
+	check "gopkg.in/check.v1"
+)
+
+var _ = check.Suite(&ErrorSuite{})
+
+type ErrorSuite struct{}
+
+func (s *ErrorSuite) SetUpSuite(c *check.C) {}
+
+func (s *ErrorSuite) SetUpTest(c *check.C) {}
+
+func (s *ErrorSuite) TearDownTest(c *check.C) {}
+
+func (s *ErrorSuite) Test_handleError_RequestContext(c *check.C) {
+	req, _ := http.NewRequest("DELETE", "http://us13.api.mailchimp.com/3.0/lists/1/members/2", nil)
+	resp := &http.Response{
+		StatusCode: 404,
+		Header:     http.Header{"X-Request-Id": {"a1b2c3"}},
+		Body:       ioutil.NopCloser(strings.NewReader(`{"type":"http://developer.mailchimp.com/documentation/mailchimp/guides/error-glossary/","title":"Resource Not Found","status":404,"detail":"The requested resource could not be found.","instance":"995c5cb0-3280-4a6e-808b-3b096d0bb219"}`)),
+	}
+
+	e := (&Client{}).handleError(req, resp)
+	c.Assert(e.Title, check.Equals, "Resource Not Found")
+	c.Assert(e.Status, check.Equals, 404)
+	c.Assert(e.Instance, check.Equals, "995c5cb0-3280-4a6e-808b-3b096d0bb219")
+	c.Assert(e.Method, check.Equals, "DELETE")
+	c.Assert(e.URL, check.Equals, "http://us13.api.mailchimp.com/3.0/lists/1/members/2")
+	c.Assert(e.RequestID, check.Equals, "a1b2c3")
+	c.Assert(string(e.Body), check.Matches, `\{"type".*`)
+}
+
+func (s *ErrorSuite) Test_Is(c *check.C) {
+	cases := []struct {
+		err      Error
+		sentinel error
+		fn       func(error) bool
+	}{
+		{Error{Status: 404, Title: "Resource Not Found"}, ErrorNotFound, IsNotFound},
+		{Error{Status: 429, Title: "Too Many Requests"}, ErrorRateLimited, IsRateLimited},
+		{Error{Status: 400, Title: "Member Exists"}, ErrorMemberExists, IsMemberExists},
+		{Error{Status: 400, Title: "Forgotten Email Not Subscribed"}, ErrorCompliance, IsCompliance},
+		{Error{Status: 400, Title: "Member In Compliance State"}, ErrorCompliance, IsCompliance},
+		{Error{Status: 400, Title: "Invalid Resource", Detail: "test@example.com looks fake or invalid, please enter a real email address."}, ErrorCompliance, IsCompliance},
+		{Error{Status: 429}, ErrorRetryable, IsRetryable},
+		{Error{Status: 500}, ErrorRetryable, IsRetryable},
+		{Error{Status: 503}, ErrorRetryable, IsRetryable},
+	}
+
+	for _, tc := range cases {
+		comment := check.Commentf("%s: %v", tc.sentinel, tc.err)
+		c.Assert(errors.Is(tc.err, tc.sentinel), check.Equals, true, comment)
+		c.Assert(tc.fn(tc.err), check.Equals, true, comment)
+
+		// Wrapped errors match too
+		c.Assert(tc.fn(fmt.Errorf("sync: %w", tc.err)), check.Equals, true, comment)
+	}
+}
+
+func (s *ErrorSuite) Test_Is_NoMatch(c *check.C) {
+	invalid := Error{Status: 400, Title: "Invalid Resource", Detail: "Your merge fields were invalid."}
+	c.Assert(IsCompliance(invalid), check.Equals, false)
+	c.Assert(IsMemberExists(invalid), check.Equals, false)
+	c.Assert(IsRetryable(invalid), check.Equals, false)
+	c.Assert(IsNotFound(Error{Status: 400}), check.Equals, false)
+	c.Assert(IsRateLimited(Error{Status: 503}), check.Equals, false)
+	c.Assert(IsNotFound(nil), check.Equals, false)
+	c.Assert(IsNotFound(errors.New("not found")), check.Equals, false)
+	c.Assert(errors.Is(Error{Status: 404}, ErrorNoClient), check.Equals, false)
+}
+
+func (s *ErrorSuite) Test_IsRetryable_Timeout(c *check.C) {
+	var err error = &net.OpError{Op: "dial", Err: timeoutError{}}
+	c.Assert(IsRetryable(err), check.Equals, true)
+	c.Assert(IsRetryable(errors.New("connection refused")), check.Equals, false)
+}
+
+func (s *ErrorSuite) Test_As(c *check.C) {
+	err := fmt.Errorf("sync: %w", Error{Status: 404, RequestID: "a1b2c3"})
+
+	var e Error
+	c.Assert(errors.As(err, &e), check.Equals, true)
+	c.Assert(e.RequestID, check.Equals, "a1b2c3")
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
@@ -0,0 +1,234 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// ExportFormat is the output format of ExportMembers
+type ExportFormat string
+
+const (
+	// ExportFormatCSV writes a header row followed by one row per member
+	ExportFormatCSV ExportFormat = "csv"
+	// ExportFormatJSONLines writes one flat JSON object per line, keyed by the same headers as the CSV
+	ExportFormatJSONLines ExportFormat = "jsonl"
+)
+
+// exportPageSize is the number of members fetched per request, 1000 is the api max
+var exportPageSize = 1000
+
+// addressFields are the sub keys of address merge fields
+var addressFields = []string{"addr1", "addr2", "city", "state", "zip", "country"}
+
+// exportColumn is a single flattened value of a member
+type exportColumn struct {
+	header string
+	value  func(m *Member) interface{}
+}
+
+// ExportMembers pages through all members on a list and streams them to w.
+// Merge fields get a column each, headed by the field name and tag, like
+// "First Name (FNAME)". Address fields are split into one column per part.
+// Interests, location and stats are flattened into "interests.<id>",
+// "location.<key>" and "stats.<key>" columns. Interest columns are taken
+// from the first member, since Mailchimp returns every interest on every member.
+// Optional params such as status are passed on to GetMembers.
+func (c *Client) ExportMembers(ctx context.Context, listID string, w io.Writer, format ExportFormat, params ...Parameters) error {
+	if format != ExportFormatCSV && format != ExportFormatJSONLines {
+		return fmt.Errorf("unknown export format: %s", format)
+	}
+
+	fields, err := c.GetMergeFields(ctx, listID, Parameters{"count": 1000})
+	if err != nil {
+		return err
+	}
+
+	var columns []*exportColumn
+	var csvWriter *csv.Writer
+	encoder := json.NewEncoder(w)
+	if format == ExportFormatCSV {
+		csvWriter = csv.NewWriter(w)
+	}
+
+	p := requestParameters(params)
+	for offset := 0; ; offset += exportPageSize {
+		p["count"] = exportPageSize
+		p["offset"] = offset
+
+		members, err := c.GetMembers(ctx, listID, p)
+		if err != nil {
+			return err
+		}
+
+		for _, member := range members {
+			if columns == nil {
+				columns = exportColumns(fields, member)
+				if csvWriter != nil {
+					if err := csvWriter.Write(exportHeaders(columns)); err != nil {
+						return err
+					}
+				}
+			}
+
+			if csvWriter != nil {
+				err = csvWriter.Write(exportCSVRecord(columns, member))
+			} else {
+				err = encoder.Encode(exportJSONRecord(columns, member))
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if csvWriter != nil {
+			csvWriter.Flush()
+			if err := csvWriter.Error(); err != nil {
+				return err
+			}
+		}
+
+		if len(members) < exportPageSize {
+			break
+		}
+	}
+
+	// An empty list still gets a header
+	if columns == nil && csvWriter != nil {
+		csvWriter.Write(exportHeaders(exportColumns(fields, nil)))
+		csvWriter.Flush()
+		return csvWriter.Error()
+	}
+
+	return nil
+}
+
+// exportColumns builds the columns for the list. first is used to find the
+// interest ids and may be nil.
+func exportColumns(fields []*MergeField, first *Member) []*exportColumn {
+	columns := []*exportColumn{
+		{"email_address", func(m *Member) interface{} { return m.EmailAddress }},
+		{"id", func(m *Member) interface{} { return m.ID }},
+		{"status", func(m *Member) interface{} { return string(m.Status) }},
+		{"email_type", func(m *Member) interface{} { return string(m.EmailType) }},
+		{"language", func(m *Member) interface{} { return m.Language }},
+		{"vip", func(m *Member) interface{} { return m.Vip }},
+		{"member_rating", func(m *Member) interface{} { return m.MemberRating }},
+		{"ip_signup", func(m *Member) interface{} { return m.IPSignup }},
+		{"timestamp_signup", func(m *Member) interface{} { return m.TimestampSignup.String() }},
+		{"ip_opt", func(m *Member) interface{} { return m.IPOpt }},
+		{"timestamp_opt", func(m *Member) interface{} { return m.TimestampOpt.String() }},
+		{"last_changed", func(m *Member) interface{} { return m.LastChanged.String() }},
+	}
+
+	sorted := make([]*MergeField, len(fields))
+	copy(sorted, fields)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].DisplayOrder < sorted[j].DisplayOrder })
+
+	for _, field := range sorted {
+		tag := field.Tag
+		header := fmt.Sprintf("%s (%s)", field.Name, tag)
+
+		if field.Type != MergeFieldTypeAddress {
+			columns = append(columns, &exportColumn{header, func(m *Member) interface{} {
+				return m.MergeFields[tag]
+			}})
+			continue
+		}
+
+		for _, key := range addressFields {
+			key := key
+			columns = append(columns, &exportColumn{header + " " + key, func(m *Member) interface{} {
+				address, _ := m.MergeFields[tag].(map[string]interface{})
+				return address[key]
+			}})
+		}
+	}
+
+	if first != nil {
+		interests := []string{}
+		for id := range first.Interests {
+			interests = append(interests, id)
+		}
+		sort.Strings(interests)
+
+		for _, id := range interests {
+			id := id
+			columns = append(columns, &exportColumn{"interests." + id, func(m *Member) interface{} {
+				return m.Interests[id]
+			}})
+		}
+	}
+
+	columns = append(columns,
+		&exportColumn{"location.latitude", func(m *Member) interface{} { return m.Location.Latitude }},
+		&exportColumn{"location.longitude", func(m *Member) interface{} { return m.Location.Longitude }},
+		&exportColumn{"location.gmtoff", func(m *Member) interface{} { return m.Location.GmtOff }},
+		&exportColumn{"location.dstoff", func(m *Member) interface{} { return m.Location.DstOff }},
+		&exportColumn{"location.country_code", func(m *Member) interface{} { return m.Location.CountryCode }},
+		&exportColumn{"location.timezone", func(m *Member) interface{} { return m.Location.Timezone }},
+		&exportColumn{"stats.avg_open_rate", func(m *Member) interface{} { return m.Stats.AvgOpenRate }},
+		&exportColumn{"stats.avg_click_rate", func(m *Member) interface{} { return m.Stats.AvgClickRate }},
+	)
+
+	return columns
+}
+
+func exportHeaders(columns []*exportColumn) []string {
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column.header
+	}
+	return headers
+}
+
+func exportCSVRecord(columns []*exportColumn, m *Member) []string {
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		switch v := column.value(m).(type) {
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			record[i] = mergeValueString(v)
+		}
+	}
+	return record
+}
+
+// exportJSONRecord keeps the order of the columns, which a map would lose
+func exportJSONRecord(columns []*exportColumn, m *Member) json.RawMessage {
+	buf := []byte{'{'}
+	for i, column := range columns {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		key, _ := json.Marshal(column.header)
+		value, err := json.Marshal(column.value(m))
+		if err != nil {
+			value = []byte("null")
+		}
+		buf = append(buf, key...)
+		buf = append(buf, ':')
+		buf = append(buf, value...)
+	}
+	return append(buf, '}')
+}
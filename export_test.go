@@ -0,0 +1,141 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"strings"
+
+	check "gopkg.in/check.v1"
+
+	t "github.com/greatbeyond/mailchimp/testing"
+)
+
+var _ = check.Suite(&ExportSuite{})
+
+type ExportSuite struct {
+	client *Client
+	server *t.MockServer
+	ctx    context.Context
+}
+
+func (s *ExportSuite) SetUpSuite(c *check.C) {}
+
+func (s *ExportSuite) SetUpTest(c *check.C) {
+	s.server = t.NewMockServer()
+	s.server.SetChecker(c)
+
+	s.client = NewClient()
+	s.client.HTTPClient = s.server.HTTPClient
+
+	s.ctx = NewContextWithToken(context.Background(), os.Getenv("MAILCHIMP_TEST_TOKEN"))
+	// We need http to use the mock server
+	s.ctx = NewContextWithURL(s.ctx, "http://us13.api.mailchimp.com/3.0/")
+}
+
+func (s *ExportSuite) TearDownTest(c *check.C) {
+	exportPageSize = 1000
+}
+
+func (s *ExportSuite) addResponses(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"merge_fields":[{"merge_id":2,"tag":"ADDR","name":"Address","type":"address","display_order":3},{"merge_id":1,"tag":"FNAME","name":"First Name","type":"text","display_order":2}],"total_items":2}`,
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"members":[{"id":"a1","email_address":"ann@example.net","status":"subscribed","email_type":"html","member_rating":2,"merge_fields":{"FNAME":"Ann","ADDR":{"addr1":"Main St 1","addr2":"","city":"Atlanta","state":"GA","zip":"30308","country":"US"}},"interests":{"b2":true,"a1":false},"location":{"latitude":33.7,"longitude":-84.3,"country_code":"US"},"stats":{"avg_open_rate":0.5}}],"total_items":2}`,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(r.RequestURI, check.Equals, "http://us13.api.mailchimp.com/3.0/lists/57afe96172/members?count=1&offset=0&status=subscribed")
+		},
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"members":[{"id":"b2","email_address":"bob@example.net","status":"subscribed","vip":true,"merge_fields":{"FNAME":"Bob, Jr","ADDR":""},"interests":{"b2":false,"a1":true}}],"total_items":2}`,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(r.URL.Query().Get("offset"), check.Equals, "1")
+		},
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"members":[],"total_items":2}`,
+	})
+}
+
+func (s *ExportSuite) Test_ExportMembers_CSV(c *check.C) {
+	exportPageSize = 1
+	s.addResponses(c)
+
+	buf := &bytes.Buffer{}
+	err := s.client.ExportMembers(s.ctx, "57afe96172", buf, ExportFormatCSV, Parameters{"status": "subscribed"})
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Equals, strings.Join([]string{
+		"email_address,id,status,email_type,language,vip,member_rating,ip_signup,timestamp_signup,ip_opt,timestamp_opt,last_changed," +
+			"First Name (FNAME),Address (ADDR) addr1,Address (ADDR) addr2,Address (ADDR) city,Address (ADDR) state,Address (ADDR) zip,Address (ADDR) country," +
+			"interests.a1,interests.b2," +
+			"location.latitude,location.longitude,location.gmtoff,location.dstoff,location.country_code,location.timezone,stats.avg_open_rate,stats.avg_click_rate",
+		"ann@example.net,a1,subscribed,html,,false,2,,,,,,Ann,Main St 1,,Atlanta,GA,30308,US,false,true,33.7,-84.3,0,0,US,,0.5,0",
+		`bob@example.net,b2,subscribed,,,true,0,,,,,,"Bob, Jr",,,,,,,true,false,0,0,0,0,,,0,0`,
+		"",
+	}, "\n"))
+	s.server.VerifyNoMoreRequests(c)
+}
+
+func (s *ExportSuite) Test_ExportMembers_JSONLines(c *check.C) {
+	exportPageSize = 1
+	s.addResponses(c)
+
+	buf := &bytes.Buffer{}
+	err := s.client.ExportMembers(s.ctx, "57afe96172", buf, ExportFormatJSONLines, Parameters{"status": "subscribed"})
+	c.Assert(err, check.IsNil)
+
+	lines := strings.Split(buf.String(), "\n")
+	c.Assert(lines, check.HasLen, 3)
+	c.Assert(strings.HasPrefix(lines[0], `{"email_address":"ann@example.net","id":"a1","status":"subscribed","email_type":"html","language":"","vip":false,"member_rating":2,`), check.Equals, true)
+	c.Assert(strings.Contains(lines[0], `"First Name (FNAME)":"Ann","Address (ADDR) addr1":"Main St 1",`), check.Equals, true)
+	c.Assert(strings.Contains(lines[1], `"Address (ADDR) addr1":null,`), check.Equals, true)
+	c.Assert(strings.HasSuffix(lines[1], `"interests.a1":true,"interests.b2":false,"location.latitude":0,"location.longitude":0,"location.gmtoff":0,"location.dstoff":0,"location.country_code":"","location.timezone":"","stats.avg_open_rate":0,"stats.avg_click_rate":0}`), check.Equals, true)
+	c.Assert(lines[2], check.Equals, "")
+}
+
+func (s *ExportSuite) Test_ExportMembers_EmptyList(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"merge_fields":[],"total_items":0}`,
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"members":[],"total_items":0}`,
+	})
+
+	buf := &bytes.Buffer{}
+	err := s.client.ExportMembers(s.ctx, "57afe96172", buf, ExportFormatCSV)
+	c.Assert(err, check.IsNil)
+	c.Assert(strings.HasPrefix(buf.String(), "email_address,id,status,"), check.Equals, true)
+}
+
+func (s *ExportSuite) Test_ExportMembers_UnknownFormat(c *check.C) {
+	err := s.client.ExportMembers(s.ctx, "57afe96172", &bytes.Buffer{}, ExportFormat("xml"))
+	c.Assert(err, check.ErrorMatches, "unknown export format: xml")
+}
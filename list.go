@@ -59,7 +59,7 @@ type List struct {
 	NotifyOnUnsubscribe string `json:"notify_on_unsubscribe,omitempty"`
 
 	// The date and time that this list was created.
-	DateCreated string `json:"date_created,omitempty"`
+	DateCreated Time `json:"date_created"`
 
 	// An auto-generated activity score for the list (0-5).
 	ListRating int `json:"list_rating,omitempty"`
@@ -123,15 +123,15 @@ type ListStats struct {
 	UnsubscribeCountSinceSend int     `json:"unsubscribe_count_since_send"`
 	CleanedCountSinceSend     int     `json:"cleaned_count_since_send"`
 	CampaignCount             int     `json:"campaign_count"`
-	CampaignLastSent          string  `json:"campaign_last_sent"`
+	CampaignLastSent          Time    `json:"campaign_last_sent"`
 	MergeFieldCount           int     `json:"merge_field_count"`
 	AvgSubRate                float64 `json:"avg_sub_rate"`
 	AvgUnsubRate              float64 `json:"avg_unsub_rate"`
 	TargetSubRate             float64 `json:"target_sub_rate"`
 	OpenRate                  float64 `json:"open_rate"`
 	ClickRate                 float64 `json:"click_rate"`
-	LastSubDate               string  `json:"last_sub_date"`
-	LastUnsubDate             string  `json:"last_unsub_date"`
+	LastSubDate               Time    `json:"last_sub_date"`
+	LastUnsubDate             Time    `json:"last_unsub_date"`
 }
 
 // CreateList defines fields neccessary to create a new list.
@@ -298,8 +298,77 @@ func (l *List) Delete(ctx context.Context) error {
 	return l.Client.Delete(ctx, slashJoin(ListsURL, l.ID))
 }
 
-// TimeCreated converts DateCreated to a time.Time object
+// BatchSubscribe adds or updates members on a list in a single call.
+// At most 500 members can be sent at a time.
+type BatchSubscribe struct {
+	// An array of members to add or update.
+	Members []*CreateMember `json:"members"`
+
+	// Whether this batch operation will change existing members’ subscription status.
+	UpdateExisting bool `   json:"update_existing"`
+}
+
+// BatchSubscribeResponse is the result of a BatchSubscribe call
+type BatchSubscribeResponse struct {
+	// An array of members that were successfully added.
+	NewMembers []*Member `                json:"new_members"`
+
+	// An array of members that were successfully updated.
+	UpdatedMembers []*Member `            json:"updated_members"`
+
+	// An array of emails that failed, and the reason.
+	Errors []*BatchSubscribeError `       json:"errors"`
+
+	// The total number of items matching the query, irrespective of pagination.
+	TotalCreated int `                    json:"total_created"`
+
+	// The total number of items updated.
+	TotalUpdated int `                    json:"total_updated"`
+
+	// The number of errors.
+	ErrorCount int `                      json:"error_count"`
+}
+
+// BatchSubscribeError is a member that could not be added or updated
+type BatchSubscribeError struct {
+	EmailAddress string `json:"email_address"`
+	Error        string `json:"error"`
+}
+
+// BatchSubscribe adds or updates up to 500 members on the list
+func (l *List) BatchSubscribe(ctx context.Context, data *BatchSubscribe) (*BatchSubscribeResponse, error) {
+	if l.Client == nil {
+		return nil, ErrorNoClient
+	}
+
+	if len(data.Members) > 500 {
+		return nil, fmt.Errorf("member count over limit (500)")
+	}
+
+	response, err := l.Client.Post(ctx, slashJoin(ListsURL, l.ID), nil, data)
+	if err != nil {
+		Log.Error(err.Error(), caller())
+		return nil, err
+	}
+
+	var batchResponse *BatchSubscribeResponse
+	err = json.Unmarshal(response, &batchResponse)
+	if err != nil {
+		Log.Error(err.Error(), caller())
+		return nil, err
+	}
+
+	for _, member := range batchResponse.NewMembers {
+		member.Client = l.Client
+	}
+	for _, member := range batchResponse.UpdatedMembers {
+		member.Client = l.Client
+	}
+
+	return batchResponse, nil
+}
+
+// TimeCreated returns DateCreated as a time.Time object
 func (l *List) TimeCreated() time.Time {
-	d, _ := StringToTime(l.DateCreated)
-	return d
+	return l.DateCreated.Time
 }
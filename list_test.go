@@ -116,7 +116,7 @@ func (s *ListSuite) Test_CreateList_Normal(c *check.C) {
 		},
 		NotifyOnSubscribe:   "",
 		NotifyOnUnsubscribe: "",
-		DateCreated:         "2015-09-16T14:55:51+00:00",
+		DateCreated:         mustParseTime("2015-09-16T14:55:51+00:00"),
 		ListRating:          0,
 		EmailTypeOption:     true,
 		SubscribeURLShort:   "http://eepurl.com/xxxx",
@@ -132,15 +132,15 @@ func (s *ListSuite) Test_CreateList_Normal(c *check.C) {
 			UnsubscribeCountSinceSend: 0,
 			CleanedCountSinceSend:     0,
 			CampaignCount:             0,
-			CampaignLastSent:          "",
+			CampaignLastSent:          mustParseTime(""),
 			MergeFieldCount:           2,
 			AvgSubRate:                0,
 			AvgUnsubRate:              0,
 			TargetSubRate:             0,
 			OpenRate:                  0,
 			ClickRate:                 0,
-			LastSubDate:               "",
-			LastUnsubDate:             "",
+			LastSubDate:               mustParseTime(""),
+			LastUnsubDate:             mustParseTime(""),
 		},
 
 		Client: s.client,
@@ -360,7 +360,7 @@ func (s *ListSuite) Test_GetLists_Normal(c *check.C) {
 		},
 		NotifyOnSubscribe:   "",
 		NotifyOnUnsubscribe: "",
-		DateCreated:         "2015-09-16T14:55:51+00:00",
+		DateCreated:         mustParseTime("2015-09-16T14:55:51+00:00"),
 		ListRating:          0,
 		EmailTypeOption:     true,
 		SubscribeURLShort:   "http://eepurl.com/xxxx",
@@ -376,15 +376,15 @@ func (s *ListSuite) Test_GetLists_Normal(c *check.C) {
 			UnsubscribeCountSinceSend: 0,
 			CleanedCountSinceSend:     0,
 			CampaignCount:             0,
-			CampaignLastSent:          "",
+			CampaignLastSent:          mustParseTime(""),
 			MergeFieldCount:           2,
 			AvgSubRate:                0,
 			AvgUnsubRate:              0,
 			TargetSubRate:             0,
 			OpenRate:                  0,
 			ClickRate:                 0,
-			LastSubDate:               "",
-			LastUnsubDate:             "",
+			LastSubDate:               mustParseTime(""),
+			LastUnsubDate:             mustParseTime(""),
 		},
 		Client: s.client,
 	})
@@ -455,7 +455,7 @@ func (s *ListSuite) Test_GetList_Normal(c *check.C) {
 		},
 		NotifyOnSubscribe:   "",
 		NotifyOnUnsubscribe: "",
-		DateCreated:         "2015-09-16T14:55:51+00:00",
+		DateCreated:         mustParseTime("2015-09-16T14:55:51+00:00"),
 		ListRating:          0,
 		EmailTypeOption:     true,
 		SubscribeURLShort:   "http://eepurl.com/xxxx",
@@ -471,15 +471,15 @@ func (s *ListSuite) Test_GetList_Normal(c *check.C) {
 			UnsubscribeCountSinceSend: 0,
 			CleanedCountSinceSend:     0,
 			CampaignCount:             0,
-			CampaignLastSent:          "",
+			CampaignLastSent:          mustParseTime(""),
 			MergeFieldCount:           2,
 			AvgSubRate:                0,
 			AvgUnsubRate:              0,
 			TargetSubRate:             0,
 			OpenRate:                  0,
 			ClickRate:                 0,
-			LastSubDate:               "",
-			LastUnsubDate:             "",
+			LastSubDate:               mustParseTime(""),
+			LastUnsubDate:             mustParseTime(""),
 		},
 
 		Client: s.client,
@@ -610,7 +610,7 @@ func (s *ListSuite) Test_Update_Normal(c *check.C) {
 		},
 		NotifyOnSubscribe:   "",
 		NotifyOnUnsubscribe: "",
-		DateCreated:         "2015-09-16T14:55:51+00:00",
+		DateCreated:         mustParseTime("2015-09-16T14:55:51+00:00"),
 		ListRating:          0,
 		EmailTypeOption:     true,
 		SubscribeURLShort:   "http://eepurl.com/xxxx",
@@ -626,15 +626,15 @@ func (s *ListSuite) Test_Update_Normal(c *check.C) {
 			UnsubscribeCountSinceSend: 0,
 			CleanedCountSinceSend:     0,
 			CampaignCount:             0,
-			CampaignLastSent:          "",
+			CampaignLastSent:          mustParseTime(""),
 			MergeFieldCount:           2,
 			AvgSubRate:                0,
 			AvgUnsubRate:              0,
 			TargetSubRate:             0,
 			OpenRate:                  0,
 			ClickRate:                 0,
-			LastSubDate:               "",
-			LastUnsubDate:             "",
+			LastSubDate:               mustParseTime(""),
+			LastUnsubDate:             mustParseTime(""),
 		},
 
 		Client: s.client,
@@ -689,3 +689,54 @@ func (s *ListSuite) Test_Update_UnknownResponse(c *check.C) {
 	c.Assert(err, check.ErrorMatches, "Response error.*")
 	c.Assert(upd, check.IsNil)
 }
+
+// --------------------------------------------------------------
+// BatchSubscribe
+
+func (s *ListSuite) Test_BatchSubscribe_Normal(c *check.C) {
+	list := &List{
+		ID:     "1510500e0b",
+		Client: s.client,
+	}
+
+	s.server.AddResponse(&t.MockResponse{
+		Method: "POST",
+		Code:   200,
+		Body:   `{"new_members":[{"id":"852aaa9532cb36adfb5e9fef7a4206a9","email_address":"urist.mcvankab+3@freddiesjokes.com","status":"subscribed"}],"updated_members":[],"errors":[{"email_address":"bad@example","error":"invalid email"}],"total_created":1,"total_updated":0,"error_count":1}`,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(body, check.Equals, `{"members":[{"email_address":"urist.mcvankab+3@freddiesjokes.com","status":"subscribed"},{"email_address":"bad@example","status":"subscribed"}],"update_existing":true}`)
+			c.Assert(r.RequestURI, check.Equals, "http://us13.api.mailchimp.com/3.0/lists/1510500e0b")
+		},
+	})
+
+	resp, err := list.BatchSubscribe(s.ctx, &BatchSubscribe{
+		Members: []*CreateMember{
+			{EmailAddress: "urist.mcvankab+3@freddiesjokes.com", Status: Subscribed},
+			{EmailAddress: "bad@example", Status: Subscribed},
+		},
+		UpdateExisting: true,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.TotalCreated, check.Equals, 1)
+	c.Assert(resp.NewMembers[0].Client, check.Not(check.IsNil))
+	c.Assert(resp.Errors, check.DeepEquals, []*BatchSubscribeError{
+		{EmailAddress: "bad@example", Error: "invalid email"},
+	})
+}
+
+func (s *ListSuite) Test_BatchSubscribe_TooMany(c *check.C) {
+	list := &List{
+		ID:     "1510500e0b",
+		Client: s.client,
+	}
+
+	members := make([]*CreateMember, 501)
+	_, err := list.BatchSubscribe(s.ctx, &BatchSubscribe{Members: members})
+	c.Assert(err, check.ErrorMatches, "member count over limit \\(500\\)")
+}
+
+func (s *ListSuite) Test_BatchSubscribe_NoClient(c *check.C) {
+	list := &List{ID: "1510500e0b"}
+	_, err := list.BatchSubscribe(s.ctx, &BatchSubscribe{})
+	c.Assert(err, check.ErrorMatches, "no client assigned by parent")
+}
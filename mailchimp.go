@@ -229,7 +229,7 @@ func (c *Client) Do(request *http.Request) ([]byte, error) {
 			"url":    request.URL.String(),
 		}).Info("non success response code", caller())
 
-		err := c.handleError(resp)
+		err := c.handleError(request, resp)
 		return nil, err
 	}
 }
@@ -249,26 +249,31 @@ func (c *Client) addParameters(request *http.Request, params map[string]interfac
 	request.URL.RawQuery = values.Encode()
 }
 
-// handleError translates errors provided by the API to a Error struct
-func (c *Client) handleError(response *http.Response) Error {
+// handleError translates errors provided by the API to a Error struct.
+// The request and raw body are kept on the error, even if the body could
+// not be read or parsed.
+func (c *Client) handleError(request *http.Request, response *http.Response) Error {
+	e := Error{}
+
 	body, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		return Error{
-			Title:  "Unknown error",
-			Detail: err.Error(),
-		}
+		e.Title = "Unknown error"
+		e.Detail = err.Error()
+	} else if err := json.Unmarshal(body, &e); err != nil {
+		Log.Debug(string(body))
+		e.Title = "Response error"
+		e.Detail = err.Error()
 	}
 
-	var e Error
-	err = json.Unmarshal(body, &e)
-	if err != nil {
-		Log.Debug(string(body))
-		return Error{
-			Title:  "Response error",
-			Detail: err.Error(),
-		}
+	if e.Status == 0 {
+		e.Status = response.StatusCode
 	}
 
+	e.Body = body
+	e.Method = request.Method
+	e.URL = request.URL.String()
+	e.RequestID = response.Header.Get("X-Request-Id")
+
 	return e
 }
 
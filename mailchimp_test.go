@@ -253,10 +253,31 @@ func (s *MailchimpTestSuite) Test_Do_NonSuccessResponse(c *check.C) {
 		Status: 501,
 		Type:   "internal error",
 		Title:  "Internal Error",
+		Body:   []byte(`{"type":"internal error","title":"Internal Error","status":501}` + "\n"),
+		Method: "GET",
+		URL:    "http://us13.api.mailchimp.com/3.0/test",
 	})
 	c.Assert(resp, check.IsNil)
 }
 
+func (s *MailchimpTestSuite) Test_Do_MalformedErrorResponse(c *check.C) {
+	req, _ := http.NewRequest("POST", "http://us13.api.mailchimp.com/3.0/test", nil)
+	s.server.AddResponse(&t.MockResponse{
+		Method: "POST",
+		Code:   502,
+		Body:   `<html>Bad Gateway</html>`,
+	})
+	_, err := s.Client.Do(req.WithContext(s.ctx))
+	c.Assert(err, check.ErrorMatches, "Response error \\(502\\): .*")
+
+	e, ok := err.(Error)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(string(e.Body), check.Equals, "<html>Bad Gateway</html>\n")
+	c.Assert(e.Method, check.Equals, "POST")
+	c.Assert(e.URL, check.Equals, "http://us13.api.mailchimp.com/3.0/test")
+	c.Assert(IsRetryable(err), check.Equals, true)
+}
+
 func (s *MailchimpTestSuite) Test_Do_BadRequest(c *check.C) {
 	req, _ := http.NewRequest("GET", "http://example.net", nil)
 	req.URL = nil
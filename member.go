@@ -38,6 +38,7 @@ const (
 	Unsubscribed MemberStatus = "unsubscribed"
 	Cleaned      MemberStatus = "cleaned"
 	Pending      MemberStatus = "pending"
+	Archived     MemberStatus = "archived"
 )
 
 // Member manages members of a specific MailChimp list, including currently subscribed, unsubscribed, and bounced members.
@@ -58,19 +59,19 @@ type Member struct {
 	// The key of this object’s properties is the ID of the interest in question.
 	Interests map[string]bool `         json:"interests,omitempty"`
 	// Open and click rates for this subscriber.
-	Stats MemberStats `                json:"stats,omitempty"`
+	Stats MemberStats `                 json:"stats,omitempty"`
 	// IP address the subscriber signed up from.
 	IPSignup string `                   json:"ip_signup,omitempty"`
 	// The date and time the subscriber signed up for the list.
-	TimestampSignup string `            json:"timestamp_signup,omitempty"`
+	TimestampSignup Time `              json:"timestamp_signup"`
 	// The IP address the subscriber used to confirm their opt-in status.
 	IPOpt string `                      json:"ip_opt,omitempty"`
 	// The date and time the subscribe confirmed their opt-in status.
-	TimestampOpt string `               json:"timestamp_opt,omitempty"`
+	TimestampOpt Time `                 json:"timestamp_opt"`
 	// Star rating for this member, between 1 and 5.
 	MemberRating int `                  json:"member_rating,omitempty"`
 	// The date and time the member’s info was last changed.
-	LastChanged string `                json:"last_changed,omitempty"`
+	LastChanged Time `                  json:"last_changed"`
 	// If set/detected, the subscriber’s language.
 	Language string `                   json:"language,omitempty"`
 	// VIP status for subscriber.
@@ -78,7 +79,7 @@ type Member struct {
 	// The list member’s email client.
 	EmailClient string `                json:"email_client,omitempty"`
 	// Subscriber location information.
-	Location Location `                json:"location,omitempty"`
+	Location Location `                 json:"location,omitempty"`
 	// The most recent Note added about this member.
 	LastNote map[string]interface{} `   json:"last_note,omitempty"`
 	// The list id.
@@ -206,8 +207,17 @@ type getMembers struct {
 }
 
 func (c *Client) GetMembers(ctx context.Context, listID string, params ...Parameters) ([]*Member, error) {
+	membersResponse, err := getMembersPage(ctx, c, listID, params...)
+	if err != nil {
+		return nil, err
+	}
+	return membersResponse.Members, nil
+}
+
+// getMembersPage is shared with helpers that only hold a MailchimpClient
+func getMembersPage(ctx context.Context, client MailchimpClient, listID string, params ...Parameters) (*getMembers, error) {
 	p := requestParameters(params)
-	response, err := c.Get(ctx, slashJoin(ListsURL, listID, MembersURL), p)
+	response, err := client.Get(ctx, slashJoin(ListsURL, listID, MembersURL), p)
 	if err != nil {
 		Log.WithFields(logrus.Fields{
 			"listID": listID,
@@ -226,12 +236,12 @@ func (c *Client) GetMembers(ctx context.Context, listID string, params ...Parame
 	// Add internal client
 	members := []*Member{}
 	for _, member := range membersResponse.Members {
-		member.Client = c
+		member.Client = client
 		members = append(members, member)
 	}
+	membersResponse.Members = members
 
-	return members, nil
-
+	return membersResponse, nil
 }
 
 func (c *Client) GetMember(ctx context.Context, id string, listID string) (*Member, error) {
@@ -108,11 +108,11 @@ func (s *MemberSuite) Test_CreateMember_Normal(c *check.C) {
 			AvgClickRate: 0,
 		},
 		IPSignup:        "",
-		TimestampSignup: "",
+		TimestampSignup: mustParseTime(""),
 		IPOpt:           "198.2.191.34",
-		TimestampOpt:    "2015-09-16 19:24:29",
+		TimestampOpt:    mustParseTime("2015-09-16 19:24:29"),
 		MemberRating:    2,
-		LastChanged:     "2015-09-16 19:24:29",
+		LastChanged:     mustParseTime("2015-09-16 19:24:29"),
 		Language:        "",
 		Vip:             false,
 		EmailClient:     "",
@@ -230,11 +230,11 @@ func (s *MemberSuite) Test_GetMembers_Normal(c *check.C) {
 			AvgClickRate: 0,
 		},
 		IPSignup:        "",
-		TimestampSignup: "",
+		TimestampSignup: mustParseTime(""),
 		IPOpt:           "198.2.191.34",
-		TimestampOpt:    "2015-09-16 19:24:29",
+		TimestampOpt:    mustParseTime("2015-09-16 19:24:29"),
 		MemberRating:    2,
-		LastChanged:     "2015-09-16 19:24:29",
+		LastChanged:     mustParseTime("2015-09-16 19:24:29"),
 		Language:        "",
 		Vip:             false,
 		EmailClient:     "",
@@ -314,11 +314,11 @@ func (s *MemberSuite) Test_GetMember_Normal(c *check.C) {
 			AvgClickRate: 0,
 		},
 		IPSignup:        "",
-		TimestampSignup: "",
+		TimestampSignup: mustParseTime(""),
 		IPOpt:           "198.2.191.34",
-		TimestampOpt:    "2015-09-16 19:24:29",
+		TimestampOpt:    mustParseTime("2015-09-16 19:24:29"),
 		MemberRating:    2,
-		LastChanged:     "2015-09-16 19:24:29",
+		LastChanged:     mustParseTime("2015-09-16 19:24:29"),
 		Language:        "",
 		Vip:             false,
 		EmailClient:     "",
@@ -460,11 +460,11 @@ func (s *MemberSuite) Test_Update_Normal(c *check.C) {
 			AvgClickRate: 0,
 		},
 		IPSignup:        "",
-		TimestampSignup: "",
+		TimestampSignup: mustParseTime(""),
 		IPOpt:           "198.2.191.34",
-		TimestampOpt:    "2015-09-16 19:24:29",
+		TimestampOpt:    mustParseTime("2015-09-16 19:24:29"),
 		MemberRating:    2,
-		LastChanged:     "2015-09-16 19:24:29",
+		LastChanged:     mustParseTime("2015-09-16 19:24:29"),
 		Language:        "",
 		Vip:             false,
 		EmailClient:     "",
@@ -96,6 +96,16 @@ const (
 	MergeFieldTypeZip        MergeFieldType = "zip"
 )
 
+// AddressValue is the value of a address merge field
+type AddressValue struct {
+	Addr1   string `json:"addr1"`
+	Addr2   string `json:"addr2,omitempty"`
+	City    string `json:"city"`
+	State   string `json:"state"`
+	Zip     string `json:"zip"`
+	Country string `json:"country,omitempty"`
+}
+
 // CreateMergeField is a alias for MergeField, the keys are the same.
 type CreateMergeField MergeField
 
@@ -164,8 +174,21 @@ type getMergeField struct {
 
 // GetMergeFields fetches all merge fields
 func (c *Client) GetMergeFields(ctx context.Context, listID string, params ...Parameters) ([]*MergeField, error) {
+	return getMergeFields(ctx, c, listID, params...)
+}
+
+// GetMergeFields fetches the merge fields of the list
+func (l *List) GetMergeFields(ctx context.Context, params ...Parameters) ([]*MergeField, error) {
+	if l.Client == nil {
+		return nil, ErrorNoClient
+	}
+	return getMergeFields(ctx, l.Client, l.ID, params...)
+}
+
+// getMergeFields is shared with helpers that only hold a MailchimpClient
+func getMergeFields(ctx context.Context, client MailchimpClient, listID string, params ...Parameters) ([]*MergeField, error) {
 	p := requestParameters(params)
-	response, err := c.Get(ctx, slashJoin(ListsURL, listID, MergeFieldsURL), p)
+	response, err := client.Get(ctx, slashJoin(ListsURL, listID, MergeFieldsURL), p)
 	if err != nil {
 		Log.WithFields(logrus.Fields{
 			"listID": listID,
@@ -183,7 +206,7 @@ func (c *Client) GetMergeFields(ctx context.Context, listID string, params ...Pa
 	// Add internal client
 	mergefields := []*MergeField{}
 	for _, mergefield := range mergefieldsResponse.MergeField {
-		mergefield.Client = c
+		mergefield.Client = client
 		mergefields = append(mergefields, mergefield)
 	}
 
@@ -0,0 +1,482 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrMergeTagSyntax is returned by MergeTagRenderer for content with
+// unbalanced conditional merge tags.
+var ErrMergeTagSyntax = errors.New("mailchimp: merge tag syntax")
+
+// systemMergeTags are the merge tags Mailchimp fills in without a merge field
+var systemMergeTags = map[string]bool{
+	"EMAIL":              true,
+	"UNIQID":             true,
+	"CURRENT_YEAR":       true,
+	"LIST:NAME":          true,
+	"LIST:COMPANY":       true,
+	"LIST:DESCRIPTION":   true,
+	"LIST:ADDRESS":       true,
+	"LIST:ADDRESSLINE":   true,
+	"LIST:PHONE":         true,
+	"LIST:URL":           true,
+	"LIST:SUBSCRIBE":     true,
+	"LIST:RECENT":        true,
+	"LIST_ADDRESS_HTML":  true,
+	"MC:SUBJECT":         true,
+	"MC:DATE":            true,
+	"MC_PREVIEW_TEXT":    true,
+	"ARCHIVE":            true,
+	"ARCHIVE_LINK_SHORT": true,
+	"UNSUB":              true,
+	"UPDATE_PROFILE":     true,
+	"FORWARD":            true,
+	"ABOUT_LIST":         true,
+	"REWARDS":            true,
+	"REWARDS_TEXT":       true,
+}
+
+// htmlMergeTags are the system tags whose values are HTML, they are not escaped
+var htmlMergeTags = map[string]bool{
+	"LIST_ADDRESS_HTML": true,
+	"REWARDS":           true,
+}
+
+// mergeTagModifiers change how a merge tag value is rendered
+var mergeTagModifiers = []string{"HTML:", "UPPER:", "LOWER:", "TITLE:"}
+
+// MergeTagRenderer renders the merge tags of campaign content for a member,
+// to preview what the member will receive. It handles merge field tags such
+// as *|FNAME|* with the HTML:, UPPER:, LOWER: and TITLE: modifiers, the
+// conditionals *|IF:|*, *|IFNOT:|*, *|ELSEIF:|*, *|ELSE:|* and *|END:IF|*,
+// and system tags. Tags that can not be filled in are left as they are.
+//
+//  fields, err := client.GetMergeFields(ctx, listID)
+//  if err != nil {
+//      return err
+//  }
+//  preview, err := mailchimp.NewMergeTagRenderer(fields).Render(content, member)
+type MergeTagRenderer struct {
+	// MergeFields are the merge field definitions of the list. Tags that
+	// are neither one of them nor a system tag are reported as unknown.
+	// Their default values are used for members without a value.
+	MergeFields []*MergeField
+
+	// System holds values for system tags, such as "LIST:NAME" or "UNSUB",
+	// keyed without the *| |* delimiters. EMAIL, UNIQID and CURRENT_YEAR
+	// are filled in from the member and Now.
+	System map[string]string
+
+	// Now is used for CURRENT_YEAR, defaults to time.Now
+	Now func() time.Time
+}
+
+// NewMergeTagRenderer creates a renderer for a list with the merge fields
+func NewMergeTagRenderer(fields []*MergeField) *MergeTagRenderer {
+	return &MergeTagRenderer{
+		MergeFields: fields,
+		System:      map[string]string{},
+	}
+}
+
+// RenderedContent is campaign content with the merge tags rendered
+type RenderedContent struct {
+	HTML      string
+	PlainText string
+
+	// UnknownTags are the merge tags in the content that are neither a
+	// merge field of the list nor a system tag, in order of appearance.
+	UnknownTags []string
+}
+
+// Render renders the merge tags of the content for member. The plain text
+// is rendered from content.PlainText, or from the rendered HTML if empty.
+func (r *MergeTagRenderer) Render(content *CampaignContent, member *Member) (*RenderedContent, error) {
+	if content == nil {
+		return nil, errors.New("no content to render")
+	}
+	if member == nil {
+		member = &Member{}
+	}
+
+	rendered := &RenderedContent{}
+	unknown := map[string]bool{}
+
+	render := func(text string, escape bool) (string, error) {
+		nodes, tags, err := parseMergeTags(text)
+		if err != nil {
+			return "", err
+		}
+		for _, tag := range tags {
+			if !unknown[tag] && !r.known(tag, member) {
+				unknown[tag] = true
+				rendered.UnknownTags = append(rendered.UnknownTags, tag)
+			}
+		}
+
+		var out strings.Builder
+		r.renderNodes(&out, nodes, member, escape)
+		return out.String(), nil
+	}
+
+	var err error
+	if rendered.HTML, err = render(content.HTML, true); err != nil {
+		return nil, err
+	}
+	if content.PlainText != "" {
+		if rendered.PlainText, err = render(content.PlainText, false); err != nil {
+			return nil, err
+		}
+	} else {
+		rendered.PlainText = htmlToText(rendered.HTML)
+	}
+
+	return rendered, nil
+}
+
+// known reports if tag is a system tag or a merge field of the list. Without
+// merge field definitions the merge fields of the member are used instead.
+func (r *MergeTagRenderer) known(tag string, member *Member) bool {
+	if systemMergeTags[tag] {
+		return true
+	}
+	if _, ok := r.System[tag]; ok {
+		return true
+	}
+	if r.MergeFields == nil {
+		_, ok := member.MergeFields[tag]
+		return ok
+	}
+	return r.field(tag) != nil
+}
+
+func (r *MergeTagRenderer) field(tag string) *MergeField {
+	for _, field := range r.MergeFields {
+		if strings.EqualFold(field.Tag, tag) {
+			return field
+		}
+	}
+	return nil
+}
+
+// value returns the value of tag for member, ok is false if there is none
+func (r *MergeTagRenderer) value(tag string, member *Member) (string, bool) {
+	if v, ok := member.MergeFields[tag]; ok {
+		if value := formatMergeValue(v); value != "" {
+			return value, true
+		}
+	}
+	if field := r.field(tag); field != nil {
+		return field.DefaultValue, true
+	}
+	if value, ok := r.System[tag]; ok {
+		return value, true
+	}
+
+	switch tag {
+	case "EMAIL":
+		return member.EmailAddress, true
+	case "UNIQID":
+		return member.UniqueEmailID, true
+	case "CURRENT_YEAR":
+		now := time.Now
+		if r.Now != nil {
+			now = r.Now
+		}
+		return strconv.Itoa(now().Year()), true
+	}
+
+	if _, ok := member.MergeFields[tag]; ok {
+		return "", true
+	}
+	return "", false
+}
+
+func (r *MergeTagRenderer) renderNodes(out *strings.Builder, nodes []*mergeNode, member *Member, escape bool) {
+	for _, node := range nodes {
+		switch {
+		case node.branches != nil:
+			for _, branch := range node.branches {
+				if branch.cond == nil || r.eval(branch.cond, member) {
+					r.renderNodes(out, branch.body, member, escape)
+					break
+				}
+			}
+
+		case node.tag != "":
+			value, ok := r.value(node.tag, member)
+			if !ok {
+				out.WriteString(node.text)
+				continue
+			}
+			switch node.modifier {
+			case "UPPER:":
+				value = strings.ToUpper(value)
+			case "LOWER:":
+				value = strings.ToLower(value)
+			case "TITLE:":
+				value = titleCase(value)
+			}
+			if escape && node.modifier != "HTML:" && !htmlMergeTags[node.tag] {
+				value = html.EscapeString(value)
+			}
+			out.WriteString(value)
+
+		default:
+			out.WriteString(node.text)
+		}
+	}
+}
+
+func (r *MergeTagRenderer) eval(cond *mergeCondition, member *Member) bool {
+	value, _ := r.value(cond.tag, member)
+
+	var result bool
+	switch cond.op {
+	case "":
+		result = value != ""
+	case "=":
+		result = strings.EqualFold(value, cond.value)
+	case "!=":
+		result = !strings.EqualFold(value, cond.value)
+	default:
+		cmp := strings.Compare(value, cond.value)
+		a, aerr := strconv.ParseFloat(value, 64)
+		b, berr := strconv.ParseFloat(cond.value, 64)
+		if aerr == nil && berr == nil {
+			cmp = 0
+			if a < b {
+				cmp = -1
+			} else if a > b {
+				cmp = 1
+			}
+		}
+		switch cond.op {
+		case ">":
+			result = cmp > 0
+		case "<":
+			result = cmp < 0
+		case ">=":
+			result = cmp >= 0
+		case "<=":
+			result = cmp <= 0
+		}
+	}
+
+	return result != cond.not
+}
+
+// -----------------------------------------------------------------
+// Parsing
+
+var mergeTagPattern = regexp.MustCompile(`\*\|([^|]+)\|\*`)
+
+// mergeNode is text, a merge tag or a conditional
+type mergeNode struct {
+	// The text of a text node, or the original text of a merge tag
+	text string
+
+	tag      string
+	modifier string
+
+	branches []*mergeBranch
+}
+
+// mergeBranch is a IF:, ELSEIF: or ELSE: branch, the ELSE: branch has no condition
+type mergeBranch struct {
+	cond *mergeCondition
+	body []*mergeNode
+}
+
+type mergeCondition struct {
+	tag   string
+	op    string
+	value string
+	not   bool
+}
+
+// parseMergeTags parses text into nodes and returns the merge field and
+// system tags used in it, including the ones in conditions.
+func parseMergeTags(text string) ([]*mergeNode, []string, error) {
+	root := &mergeBranch{}
+	stack := []*mergeNode{}
+	tags := []string{}
+
+	current := func() *mergeBranch {
+		if len(stack) == 0 {
+			return root
+		}
+		branches := stack[len(stack)-1].branches
+		return branches[len(branches)-1]
+	}
+	add := func(node *mergeNode) {
+		branch := current()
+		branch.body = append(branch.body, node)
+	}
+
+	last := 0
+	for _, match := range mergeTagPattern.FindAllStringSubmatchIndex(text, -1) {
+		if match[0] > last {
+			add(&mergeNode{text: text[last:match[0]]})
+		}
+		last = match[1]
+
+		raw := text[match[0]:match[1]]
+		expr := strings.TrimSpace(text[match[2]:match[3]])
+		upper := strings.ToUpper(expr)
+
+		switch {
+		case strings.HasPrefix(upper, "IF:"), strings.HasPrefix(upper, "IFNOT:"):
+			cond := parseMergeCondition(expr)
+			tags = append(tags, cond.tag)
+			node := &mergeNode{text: raw, branches: []*mergeBranch{{cond: cond}}}
+			add(node)
+			stack = append(stack, node)
+
+		case strings.HasPrefix(upper, "ELSEIF:"):
+			if len(stack) == 0 || current().cond == nil {
+				return nil, nil, fmt.Errorf("%w: %s without *|IF:|*", ErrMergeTagSyntax, raw)
+			}
+			cond := parseMergeCondition(expr[len("ELSE"):])
+			tags = append(tags, cond.tag)
+			node := stack[len(stack)-1]
+			node.branches = append(node.branches, &mergeBranch{cond: cond})
+
+		case upper == "ELSE:":
+			if len(stack) == 0 || current().cond == nil {
+				return nil, nil, fmt.Errorf("%w: %s without *|IF:|*", ErrMergeTagSyntax, raw)
+			}
+			node := stack[len(stack)-1]
+			node.branches = append(node.branches, &mergeBranch{})
+
+		case upper == "END:IF":
+			if len(stack) == 0 {
+				return nil, nil, fmt.Errorf("%w: %s without *|IF:|*", ErrMergeTagSyntax, raw)
+			}
+			stack = stack[:len(stack)-1]
+
+		default:
+			node := &mergeNode{text: raw, tag: upper}
+			for _, modifier := range mergeTagModifiers {
+				if strings.HasPrefix(node.tag, modifier) {
+					node.modifier = modifier
+					node.tag = node.tag[len(modifier):]
+					break
+				}
+			}
+			tags = append(tags, node.tag)
+			add(node)
+		}
+	}
+	if last < len(text) {
+		add(&mergeNode{text: text[last:]})
+	}
+
+	if len(stack) > 0 {
+		return nil, nil, fmt.Errorf("%w: %s without *|END:IF|*", ErrMergeTagSyntax, stack[len(stack)-1].text)
+	}
+
+	return root.body, tags, nil
+}
+
+// parseMergeCondition parses "IF:TAG", "IFNOT:TAG" or "IF:TAG op value"
+func parseMergeCondition(expr string) *mergeCondition {
+	cond := &mergeCondition{}
+	if strings.HasPrefix(strings.ToUpper(expr), "IFNOT:") {
+		cond.not = true
+		expr = expr[len("IFNOT:"):]
+	} else {
+		expr = expr[len("IF:"):]
+	}
+
+	if i := strings.IndexAny(expr, "=!<>"); i >= 0 {
+		op := expr[i : i+1]
+		if i+1 < len(expr) && expr[i+1] == '=' {
+			op = expr[i : i+2]
+		}
+		cond.op = op
+		cond.value = strings.TrimSpace(expr[i+len(op):])
+		expr = expr[:i]
+	}
+	cond.tag = strings.ToUpper(strings.TrimSpace(expr))
+
+	return cond
+}
+
+// -----------------------------------------------------------------
+// Formatting
+
+// mergeAddressParts are the parts of a address merge field in display order
+var mergeAddressParts = []string{"addr1", "addr2", "city", "state", "zip", "country"}
+
+func formatMergeValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case map[string]interface{}:
+		parts := []string{}
+		for _, key := range mergeAddressParts {
+			if part := formatMergeValue(value[key]); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		return strings.Join(parts, " ")
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func titleCase(s string) string {
+	runes := []rune(strings.ToLower(s))
+	for i, r := range runes {
+		if i == 0 || unicode.IsSpace(runes[i-1]) || runes[i-1] == '-' {
+			runes[i] = unicode.ToUpper(r)
+		}
+	}
+	return string(runes)
+}
+
+var (
+	htmlBreaks = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|h[1-6]|li|tr)>`)
+	htmlTags   = regexp.MustCompile(`<[^>]*>`)
+)
+
+// htmlToText is a rough plain text version of html, with a line for each
+// paragraph, heading or break.
+func htmlToText(s string) string {
+	s = htmlBreaks.ReplaceAllString(s, "\n")
+	s = htmlTags.ReplaceAllString(s, "")
+
+	lines := []string{}
+	for _, line := range strings.Split(html.UnescapeString(s), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,147 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"errors"
+	"time"
+
+	check "gopkg.in/check.v1"
+)
+
+var _ = check.Suite(&MergeTagSuite{})
+
+type MergeTagSuite struct {
+	renderer *MergeTagRenderer
+	member   *Member
+}
+
+func (s *MergeTagSuite) SetUpSuite(c *check.C) {}
+
+func (s *MergeTagSuite) SetUpTest(c *check.C) {
+	s.renderer = NewMergeTagRenderer([]*MergeField{
+		{Tag: "FNAME", Name: "First Name", Type: MergeFieldTypeText},
+		{Tag: "LNAME", Name: "Last Name", Type: MergeFieldTypeText, DefaultValue: "Customer"},
+		{Tag: "AGE", Name: "Age", Type: MergeFieldTypeNumber},
+		{Tag: "ADDRESS", Name: "Address", Type: MergeFieldTypeAddress},
+	})
+	s.renderer.Now = func() time.Time { return time.Date(2017, 2, 4, 13, 15, 0, 0, time.UTC) }
+
+	s.member = &Member{
+		EmailAddress:  "test@example.com",
+		UniqueEmailID: "882e9bec19",
+		MergeFields: map[string]interface{}{
+			"FNAME":   "Joe & Jane",
+			"LNAME":   "",
+			"AGE":     float64(42),
+			"ADDRESS": map[string]interface{}{"addr1": "1 Main St", "city": "Atlanta", "state": "GA", "zip": "30308", "country": "US"},
+		},
+	}
+}
+
+func (s *MergeTagSuite) TearDownTest(c *check.C) {}
+
+func (s *MergeTagSuite) Test_Render_Fields(c *check.C) {
+	rendered, err := s.renderer.Render(&CampaignContent{
+		HTML: "<p>Hi *|FNAME|* *|LNAME|*, age *|AGE|*</p><p>*|HTML:FNAME|* *|UPPER:LNAME|* *|title:fname|*</p><p>*|ADDRESS|*</p>",
+	}, s.member)
+	c.Assert(err, check.IsNil)
+	c.Assert(rendered.HTML, check.Equals, "<p>Hi Joe &amp; Jane Customer, age 42</p><p>Joe & Jane CUSTOMER Joe &amp; Jane</p><p>1 Main St Atlanta GA 30308 US</p>")
+	c.Assert(rendered.PlainText, check.Equals, "Hi Joe & Jane Customer, age 42\nJoe & Jane CUSTOMER Joe & Jane\n1 Main St Atlanta GA 30308 US")
+	c.Assert(rendered.UnknownTags, check.HasLen, 0)
+}
+
+func (s *MergeTagSuite) Test_Render_System(c *check.C) {
+	s.renderer.System["LIST:NAME"] = "Jokes"
+
+	rendered, err := s.renderer.Render(&CampaignContent{
+		HTML:      "<p>*|EMAIL|* *|UNIQID|* *|LIST:NAME|*</p>",
+		PlainText: "*|LIST:NAME|* (c) *|CURRENT_YEAR|*, unsubscribe: *|UNSUB|*",
+	}, s.member)
+	c.Assert(err, check.IsNil)
+	c.Assert(rendered.HTML, check.Equals, "<p>test@example.com 882e9bec19 Jokes</p>")
+	c.Assert(rendered.PlainText, check.Equals, "Jokes (c) 2017, unsubscribe: *|UNSUB|*")
+	c.Assert(rendered.UnknownTags, check.HasLen, 0)
+}
+
+func (s *MergeTagSuite) Test_Render_SystemHTML(c *check.C) {
+	s.renderer.System["LIST_ADDRESS_HTML"] = "<p>1 Main St<br>Atlanta</p>"
+	s.renderer.System["REWARDS"] = `<a href="http://eep.url">Mailchimp</a>`
+	s.renderer.System["LIST:ADDRESS"] = "1 Main St & Co"
+
+	rendered, err := s.renderer.Render(&CampaignContent{
+		HTML: "*|LIST_ADDRESS_HTML|**|REWARDS|* *|LIST:ADDRESS|*",
+	}, s.member)
+	c.Assert(err, check.IsNil)
+	c.Assert(rendered.HTML, check.Equals, `<p>1 Main St<br>Atlanta</p><a href="http://eep.url">Mailchimp</a> 1 Main St &amp; Co`)
+}
+
+func (s *MergeTagSuite) Test_Render_NilContent(c *check.C) {
+	rendered, err := s.renderer.Render(nil, s.member)
+	c.Assert(err, check.ErrorMatches, "no content to render")
+	c.Assert(rendered, check.IsNil)
+}
+
+func (s *MergeTagSuite) Test_Render_Conditionals(c *check.C) {
+	content := &CampaignContent{
+		PlainText: "*|IF:FNAME|*Hi *|FNAME|**|ELSE:|*Hi there*|END:IF|*." +
+			"*|IFNOT:AGE|* No age.*|END:IF|*" +
+			"*|IF:AGE > 50|* Old.*|ELSEIF:AGE >= 42|* Wise.*|ELSE:|* Young.*|END:IF|*" +
+			"*|IF:FNAME = joe & jane|* Match.*|IF:LNAME != Customer|* Nested.*|END:IF|**|END:IF|*",
+	}
+
+	rendered, err := s.renderer.Render(content, s.member)
+	c.Assert(err, check.IsNil)
+	c.Assert(rendered.PlainText, check.Equals, "Hi Joe & Jane. Wise. Match.")
+
+	rendered, err = s.renderer.Render(content, &Member{MergeFields: map[string]interface{}{"LNAME": "Doe", "AGE": float64(7)}})
+	c.Assert(err, check.IsNil)
+	c.Assert(rendered.PlainText, check.Equals, "Hi there. Young.")
+
+	rendered, err = s.renderer.Render(content, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(rendered.PlainText, check.Equals, "Hi there. No age. Young.")
+}
+
+func (s *MergeTagSuite) Test_Render_Unknown(c *check.C) {
+	rendered, err := s.renderer.Render(&CampaignContent{
+		HTML:      "<p>*|FNAME|* *|COMPANY|* *|IF:PHONE|*call*|END:IF|*</p>",
+		PlainText: "*|COMPANY|* *|UPPER:MMERGE9|*",
+	}, s.member)
+	c.Assert(err, check.IsNil)
+	c.Assert(rendered.HTML, check.Equals, "<p>Joe &amp; Jane *|COMPANY|* </p>")
+	c.Assert(rendered.PlainText, check.Equals, "*|COMPANY|* *|UPPER:MMERGE9|*")
+	c.Assert(rendered.UnknownTags, check.DeepEquals, []string{"COMPANY", "PHONE", "MMERGE9"})
+
+	// Without definitions the merge fields of the member are known
+	rendered, err = (&MergeTagRenderer{}).Render(&CampaignContent{HTML: "*|FNAME|* *|COMPANY|*"}, s.member)
+	c.Assert(err, check.IsNil)
+	c.Assert(rendered.UnknownTags, check.DeepEquals, []string{"COMPANY"})
+}
+
+func (s *MergeTagSuite) Test_Render_Syntax(c *check.C) {
+	for _, html := range []string{
+		"*|IF:FNAME|*Hi",
+		"Hi*|END:IF|*",
+		"*|ELSE:|*",
+		"*|IF:FNAME|*a*|ELSE:|*b*|ELSEIF:LNAME|*c*|END:IF|*",
+	} {
+		_, err := s.renderer.Render(&CampaignContent{HTML: html}, s.member)
+		c.Assert(errors.Is(err, ErrMergeTagSyntax), check.Equals, true, check.Commentf(html))
+	}
+
+	_, err := s.renderer.Render(&CampaignContent{HTML: "*|IF:FNAME|*Hi"}, nil)
+	c.Assert(err, check.ErrorMatches, `.*\*\|IF:FNAME\|\* without \*\|END:IF\|\*`)
+}
@@ -0,0 +1,359 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// MemberIterator yields the desired members of a list. Next returns
+// io.EOF when there are no more members. Nil members are skipped.
+type MemberIterator interface {
+	Next() (*CreateMember, error)
+}
+
+// MemberSliceIterator iterates over a slice of members
+type MemberSliceIterator struct {
+	Members []*CreateMember
+	pos     int
+}
+
+// NewMemberSliceIterator returns a iterator over members
+func NewMemberSliceIterator(members ...*CreateMember) *MemberSliceIterator {
+	return &MemberSliceIterator{Members: members}
+}
+
+// Next fulfills MemberIterator
+func (i *MemberSliceIterator) Next() (*CreateMember, error) {
+	if i.pos >= len(i.Members) {
+		return nil, io.EOF
+	}
+	i.pos++
+	return i.Members[i.pos-1], nil
+}
+
+// ResubscribePolicy controls how the Reconciler treats members that
+// have unsubscribed on the Mailchimp side.
+type ResubscribePolicy int
+
+const (
+	// ResubscribeNever leaves unsubscribed members unsubscribed, even when
+	// the local source wants them subscribed. Merge fields are still updated.
+	ResubscribeNever ResubscribePolicy = iota
+	// ResubscribeAllow sets the status from the local source.
+	ResubscribeAllow
+)
+
+// ReconcileOperationType is the kind of change a ReconcileOperation makes
+type ReconcileOperationType string
+
+const (
+	ReconcileSubscribe   ReconcileOperationType = "subscribe"
+	ReconcileUpdate      ReconcileOperationType = "update"
+	ReconcileArchive     ReconcileOperationType = "archive"
+	ReconcileUnsubscribe ReconcileOperationType = "unsubscribe"
+)
+
+// ReconcileOperation is a single change needed to bring the remote list
+// in line with the local source.
+type ReconcileOperation struct {
+	Type ReconcileOperationType
+
+	// The subscriber hash of the member.
+	MemberID string
+
+	EmailAddress string
+
+	// The request body, nil for archive operations.
+	Data interface{}
+}
+
+// batchOperation converts the operation to a request on the batch endpoint
+func (o *ReconcileOperation) batchOperation(listID string) (*BatchOperation, error) {
+	var op *BatchOperation
+	var err error
+
+	switch o.Type {
+	case ReconcileSubscribe:
+		op, err = NewBatchOperation(http.MethodPost, slashJoin(ListsURL, listID, MembersURL), o.Data)
+	case ReconcileUpdate, ReconcileUnsubscribe:
+		op, err = NewBatchOperation(http.MethodPatch, slashJoin(ListsURL, listID, MembersURL, o.MemberID), o.Data)
+	case ReconcileArchive:
+		op, err = NewBatchOperation(http.MethodDelete, slashJoin(ListsURL, listID, MembersURL, o.MemberID), nil)
+	default:
+		return nil, fmt.Errorf("unknown operation type: %s", o.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	op.OperationID = string(o.Type) + ":" + o.MemberID
+	return op, nil
+}
+
+// Reconciler makes a Mailchimp list match a local source of truth.
+// Members missing from the local source are archived, new members are
+// subscribed and members whose status or merge fields drifted are updated.
+type Reconciler struct {
+	ListID string
+
+	// Resubscribe controls if unsubscribed or cleaned members may be
+	// subscribed again. Defaults to ResubscribeNever.
+	Resubscribe ResubscribePolicy
+
+	// PageSize is the number of remote members fetched per request.
+	// Defaults to 500.
+	PageSize int
+
+	// Internal
+	Client MailchimpClient
+}
+
+// SetClient fulfills ClientType
+func (r *Reconciler) SetClient(c MailchimpClient) { r.Client = c }
+
+// NewReconciler returns a reconciler for a list
+func (c *Client) NewReconciler(listID string) *Reconciler {
+	return &Reconciler{
+		ListID: listID,
+		Client: c,
+	}
+}
+
+// Plan compares the desired members with the remote list and returns
+// the operations needed to reconcile them. Nothing is changed remotely.
+func (r *Reconciler) Plan(ctx context.Context, desired MemberIterator) ([]*ReconcileOperation, error) {
+	if r.Client == nil {
+		return nil, ErrorNoClient
+	}
+
+	remote, err := r.remoteMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ops := []*ReconcileOperation{}
+	seen := map[string]bool{}
+
+	for {
+		member, err := desired.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if member == nil {
+			continue
+		}
+		if err := hasFields(*member, "EmailAddress", "Status"); err != nil {
+			return nil, err
+		}
+
+		id := MemberEmailToID(member.EmailAddress)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if op := r.compare(member, id, remote[id]); op != nil {
+			ops = append(ops, op)
+		}
+	}
+
+	// Remote members without a local counterpart are archived. Sort to
+	// keep the plan stable between runs.
+	missing := []string{}
+	for id, member := range remote {
+		if !seen[id] && member.Status != Archived {
+			missing = append(missing, id)
+		}
+	}
+	sort.Strings(missing)
+
+	for _, id := range missing {
+		ops = append(ops, &ReconcileOperation{
+			Type:         ReconcileArchive,
+			MemberID:     id,
+			EmailAddress: remote[id].EmailAddress,
+		})
+	}
+
+	return ops, nil
+}
+
+// compare returns the operation needed to turn remote into desired, or nil
+// if they already match.
+func (r *Reconciler) compare(desired *CreateMember, id string, remote *Member) *ReconcileOperation {
+	if remote == nil || remote.Status == Archived {
+		if desired.Status == Unsubscribed || desired.Status == Cleaned {
+			return nil
+		}
+		return &ReconcileOperation{
+			Type:         ReconcileSubscribe,
+			MemberID:     id,
+			EmailAddress: desired.EmailAddress,
+			Data:         desired,
+		}
+	}
+
+	if desired.Status == Unsubscribed {
+		if remote.Status == Subscribed || remote.Status == Pending {
+			return &ReconcileOperation{
+				Type:         ReconcileUnsubscribe,
+				MemberID:     id,
+				EmailAddress: remote.EmailAddress,
+				Data:         &UpdateMember{Status: Unsubscribed},
+			}
+		}
+		return nil
+	}
+
+	update := &UpdateMember{}
+	changed := false
+
+	if desired.Status != remote.Status && desired.Status != Cleaned {
+		resubscribe := remote.Status == Unsubscribed || remote.Status == Cleaned
+		if !resubscribe || r.Resubscribe == ResubscribeAllow {
+			update.Status = desired.Status
+			changed = true
+		}
+	}
+
+	if len(desired.MergeFields) > 0 && MergeFieldsHash(desired.MergeFields) != remoteMergeFieldsHash(desired.MergeFields, remote.MergeFields) {
+		update.MergeFields = desired.MergeFields
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return &ReconcileOperation{
+		Type:         ReconcileUpdate,
+		MemberID:     id,
+		EmailAddress: remote.EmailAddress,
+		Data:         update,
+	}
+}
+
+// Apply sends the operations to the batch endpoint. It returns nil and no
+// error when there is nothing to do.
+func (r *Reconciler) Apply(ctx context.Context, ops []*ReconcileOperation) (*Batch, error) {
+	if r.Client == nil {
+		return nil, ErrorNoClient
+	}
+
+	if len(ops) == 0 {
+		return nil, nil
+	}
+
+	data := &CreateBatch{}
+	for _, o := range ops {
+		op, err := o.batchOperation(r.ListID)
+		if err != nil {
+			return nil, err
+		}
+		data.Operations = append(data.Operations, op)
+	}
+
+	return createBatch(ctx, r.Client, data)
+}
+
+// Reconcile plans and applies the changes in one go.
+func (r *Reconciler) Reconcile(ctx context.Context, desired MemberIterator) (*Batch, error) {
+	ops, err := r.Plan(ctx, desired)
+	if err != nil {
+		return nil, err
+	}
+	return r.Apply(ctx, ops)
+}
+
+// remoteMembers pages through all members on the list keyed by subscriber
+// hash, using the same requests as GetMembers
+func (r *Reconciler) remoteMembers(ctx context.Context) (map[string]*Member, error) {
+	count := r.PageSize
+	if count <= 0 {
+		count = 500
+	}
+
+	members := map[string]*Member{}
+	for offset := 0; ; offset += count {
+		membersResponse, err := getMembersPage(ctx, r.Client, r.ListID, Parameters{
+			"count":  count,
+			"offset": offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range membersResponse.Members {
+			members[MemberEmailToID(member.EmailAddress)] = member
+		}
+
+		if len(membersResponse.Members) < count || offset+count >= membersResponse.TotalItems {
+			break
+		}
+	}
+
+	return members, nil
+}
+
+// MergeFieldsHash returns a stable hash of merge field values. Values are
+// compared by their JSON representation, so 5 and 5.0 hash the same.
+func MergeFieldsHash(fields map[string]interface{}) string {
+	keys := []string{}
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	hash := md5.New()
+	for _, k := range keys {
+		fmt.Fprintf(hash, "%s=%s\n", k, mergeValueString(fields[k]))
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// remoteMergeFieldsHash hashes the remote values of the fields present in desired.
+// Mailchimp returns every merge field on the list, most of them empty.
+func remoteMergeFieldsHash(desired map[string]interface{}, remote map[string]interface{}) string {
+	fields := map[string]interface{}{}
+	for k := range desired {
+		fields[k] = remote[k]
+	}
+	return MergeFieldsHash(fields)
+}
+
+func mergeValueString(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	}
+	js, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(js)
+}
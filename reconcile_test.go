@@ -0,0 +1,235 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	check "gopkg.in/check.v1"
+
+	t "github.com/greatbeyond/mailchimp/testing"
+)
+
+var _ = check.Suite(&ReconcileSuite{})
+
+type ReconcileSuite struct {
+	client *Client
+	server *t.MockServer
+	ctx    context.Context
+}
+
+func (s *ReconcileSuite) SetUpSuite(c *check.C) {}
+
+func (s *ReconcileSuite) SetUpTest(c *check.C) {
+	s.server = t.NewMockServer()
+	s.server.SetChecker(c)
+
+	s.client = NewClient()
+	s.client.HTTPClient = s.server.HTTPClient
+
+	s.ctx = NewContextWithToken(context.Background(), os.Getenv("MAILCHIMP_TEST_TOKEN"))
+	// We need http to use the mock server
+	s.ctx = NewContextWithURL(s.ctx, "http://us13.api.mailchimp.com/3.0/")
+}
+
+func (s *ReconcileSuite) TearDownTest(c *check.C) {}
+
+const reconcileRemoteMembers = `{"members":[
+	{"email_address":"same@example.net","status":"subscribed","merge_fields":{"FNAME":"Ann","LNAME":"","AGE":30}},
+	{"email_address":"drift@example.net","status":"subscribed","merge_fields":{"FNAME":"Bob","LNAME":""}},
+	{"email_address":"unsub@example.net","status":"unsubscribed","merge_fields":{"FNAME":"Cid","LNAME":""}},
+	{"email_address":"gone@example.net","status":"subscribed","merge_fields":{"FNAME":"Dan","LNAME":""}},
+	{"email_address":"leaving@example.net","status":"subscribed","merge_fields":{"FNAME":"Eve","LNAME":""}},
+	{"email_address":"archived@example.net","status":"archived","merge_fields":{"FNAME":"","LNAME":""}}
+],"list_id":"57afe96172","total_items":6}`
+
+func reconcileDesired() MemberIterator {
+	return NewMemberSliceIterator(
+		&CreateMember{EmailAddress: "Same@example.net", Status: Subscribed, MergeFields: map[string]interface{}{"FNAME": "Ann", "AGE": 30}},
+		&CreateMember{EmailAddress: "drift@example.net", Status: Subscribed, MergeFields: map[string]interface{}{"FNAME": "Bobby"}},
+		&CreateMember{EmailAddress: "unsub@example.net", Status: Subscribed, MergeFields: map[string]interface{}{"FNAME": "Cid"}},
+		&CreateMember{EmailAddress: "leaving@example.net", Status: Unsubscribed},
+		&CreateMember{EmailAddress: "new@example.net", Status: Subscribed, MergeFields: map[string]interface{}{"FNAME": "Fay"}},
+		&CreateMember{EmailAddress: "new@example.net", Status: Subscribed},
+	)
+}
+
+func (s *ReconcileSuite) Test_Plan_Normal(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   reconcileRemoteMembers,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(r.RequestURI, check.Equals, "http://us13.api.mailchimp.com/3.0/lists/57afe96172/members?count=500&offset=0")
+		},
+	})
+
+	ops, err := s.client.NewReconciler("57afe96172").Plan(s.ctx, reconcileDesired())
+	c.Assert(err, check.IsNil)
+	c.Assert(ops, check.DeepEquals, []*ReconcileOperation{
+		{
+			Type:         ReconcileUpdate,
+			MemberID:     MemberEmailToID("drift@example.net"),
+			EmailAddress: "drift@example.net",
+			Data:         &UpdateMember{MergeFields: map[string]interface{}{"FNAME": "Bobby"}},
+		},
+		{
+			Type:         ReconcileUnsubscribe,
+			MemberID:     MemberEmailToID("leaving@example.net"),
+			EmailAddress: "leaving@example.net",
+			Data:         &UpdateMember{Status: Unsubscribed},
+		},
+		{
+			Type:         ReconcileSubscribe,
+			MemberID:     MemberEmailToID("new@example.net"),
+			EmailAddress: "new@example.net",
+			Data:         &CreateMember{EmailAddress: "new@example.net", Status: Subscribed, MergeFields: map[string]interface{}{"FNAME": "Fay"}},
+		},
+		{
+			Type:         ReconcileArchive,
+			MemberID:     MemberEmailToID("gone@example.net"),
+			EmailAddress: "gone@example.net",
+		},
+	})
+}
+
+func (s *ReconcileSuite) Test_Plan_ResubscribeAllow(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   reconcileRemoteMembers,
+	})
+
+	r := s.client.NewReconciler("57afe96172")
+	r.Resubscribe = ResubscribeAllow
+
+	ops, err := r.Plan(s.ctx, reconcileDesired())
+	c.Assert(err, check.IsNil)
+	c.Assert(len(ops), check.Equals, 5)
+	c.Assert(ops[1], check.DeepEquals, &ReconcileOperation{
+		Type:         ReconcileUpdate,
+		MemberID:     MemberEmailToID("unsub@example.net"),
+		EmailAddress: "unsub@example.net",
+		Data:         &UpdateMember{Status: Subscribed},
+	})
+}
+
+func (s *ReconcileSuite) Test_Plan_Paging(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"members":[{"email_address":"a@example.net","status":"subscribed"},{"email_address":"b@example.net","status":"subscribed"}],"total_items":3}`,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(r.URL.Query().Get("offset"), check.Equals, "0")
+		},
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"members":[{"email_address":"c@example.net","status":"subscribed"}],"total_items":3}`,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(r.URL.Query().Get("offset"), check.Equals, "2")
+		},
+	})
+
+	r := s.client.NewReconciler("57afe96172")
+	r.PageSize = 2
+
+	ops, err := r.Plan(s.ctx, NewMemberSliceIterator(
+		&CreateMember{EmailAddress: "a@example.net", Status: Subscribed},
+		&CreateMember{EmailAddress: "b@example.net", Status: Subscribed},
+		&CreateMember{EmailAddress: "c@example.net", Status: Subscribed},
+	))
+	c.Assert(err, check.IsNil)
+	c.Assert(ops, check.HasLen, 0)
+	s.server.VerifyNoMoreRequests(c)
+}
+
+func (s *ReconcileSuite) Test_Plan_MissingStatus(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"members":[],"total_items":0}`,
+	})
+
+	_, err := s.client.NewReconciler("57afe96172").Plan(s.ctx, NewMemberSliceIterator(
+		&CreateMember{EmailAddress: "a@example.net"},
+	))
+	c.Assert(err, check.ErrorMatches, "missing field: Status")
+}
+
+func (s *ReconcileSuite) Test_Plan_NilMember(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"members":[],"total_items":0}`,
+	})
+
+	ops, err := s.client.NewReconciler("57afe96172").Plan(s.ctx, NewMemberSliceIterator(
+		nil,
+		&CreateMember{EmailAddress: "a@example.net", Status: Subscribed},
+	))
+	c.Assert(err, check.IsNil)
+	c.Assert(ops, check.HasLen, 1)
+	c.Assert(ops[0].Type, check.Equals, ReconcileSubscribe)
+}
+
+func (s *ReconcileSuite) Test_Plan_NoClient(c *check.C) {
+	r := &Reconciler{ListID: "57afe96172"}
+	_, err := r.Plan(s.ctx, NewMemberSliceIterator())
+	c.Assert(err, check.ErrorMatches, "no client assigned by parent")
+}
+
+func (s *ReconcileSuite) Test_Reconcile_Normal(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"members":[{"email_address":"gone@example.net","status":"subscribed"}],"total_items":1}`,
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "POST",
+		Code:   200,
+		Body:   `{"id":"e2b5a6a1b6","status":"pending"}`,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(r.RequestURI, check.Equals, "http://us13.api.mailchimp.com/3.0/batches")
+			c.Assert(body, check.Equals, `{"operations":[`+
+				`{"method":"POST","path":"/lists/57afe96172/members","body":"{\"email_address\":\"new@example.net\",\"status\":\"subscribed\"}","operation_id":"subscribe:`+MemberEmailToID("new@example.net")+`"},`+
+				`{"method":"DELETE","path":"/lists/57afe96172/members/`+MemberEmailToID("gone@example.net")+`","operation_id":"archive:`+MemberEmailToID("gone@example.net")+`"}]}`)
+		},
+	})
+
+	batch, err := s.client.NewReconciler("57afe96172").Reconcile(s.ctx, NewMemberSliceIterator(
+		&CreateMember{EmailAddress: "new@example.net", Status: Subscribed},
+	))
+	c.Assert(err, check.IsNil)
+	c.Assert(batch.ID, check.Equals, "e2b5a6a1b6")
+}
+
+func (s *ReconcileSuite) Test_Apply_NothingToDo(c *check.C) {
+	batch, err := s.client.NewReconciler("57afe96172").Apply(s.ctx, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(batch, check.IsNil)
+}
+
+func (s *ReconcileSuite) Test_MergeFieldsHash(c *check.C) {
+	a := MergeFieldsHash(map[string]interface{}{"FNAME": "Ann", "AGE": 30})
+	b := MergeFieldsHash(map[string]interface{}{"AGE": 30.0, "FNAME": "Ann"})
+	c.Assert(a, check.Equals, b)
+
+	c.Assert(MergeFieldsHash(map[string]interface{}{"FNAME": nil}), check.Equals, MergeFieldsHash(map[string]interface{}{"FNAME": ""}))
+	c.Assert(MergeFieldsHash(map[string]interface{}{"FNAME": "Ann"}), check.Not(check.Equals), MergeFieldsHash(map[string]interface{}{"FNAME": "Anne"}))
+}
@@ -24,6 +24,456 @@ import (
 const ReportURL = "/reports"
 const SentToURL = "/sent-to"
 
+// Report sub resources
+const (
+	OpenDetailsURL       = "/open-details"
+	ClickDetailsURL      = "/click-details"
+	EmailActivityURL     = "/email-activity"
+	UnsubscribedURL      = "/unsubscribed"
+	DomainPerformanceURL = "/domain-performance"
+	LocationsURL         = "/locations"
+	AbuseReportsURL      = "/abuse-reports"
+	AdviceURL            = "/advice"
+	EepurlURL            = "/eepurl"
+	SubReportsURL        = "/sub-reports"
+)
+
+// Report is the summary of a sent campaign
+// https://developer.mailchimp.com/documentation/mailchimp/reference/reports/
+type Report struct {
+	// A string that uniquely identifies this campaign.
+	ID string `                        json:"id"`
+
+	// The title of the campaign.
+	CampaignTitle string `             json:"campaign_title"`
+
+	// The type of campaign (regular, plain-text, ab_split, rss, automation, variate, or auto).
+	Type string `                      json:"type"`
+
+	// The unique list id.
+	ListID string `                    json:"list_id"`
+
+	// The status of the list used, namely if it’s deleted or disabled.
+	ListIsActive bool `                json:"list_is_active"`
+
+	// The name of the list.
+	ListName string `                  json:"list_name"`
+
+	// The subject line for the campaign.
+	SubjectLine string `               json:"subject_line"`
+
+	// The preview text for the campaign.
+	PreviewText string `               json:"preview_text"`
+
+	// The total number of emails sent for this campaign.
+	EmailsSent int `                   json:"emails_sent"`
+
+	// The number of abuse reports generated for this campaign.
+	AbuseReports int `                 json:"abuse_reports"`
+
+	// The total number of unsubscribed members for this campaign.
+	Unsubscribed int `                 json:"unsubscribed"`
+
+	// The date and time a campaign was sent.
+	SendTime Time `                    json:"send_time"`
+
+	// For RSS campaigns, the date and time of the last send.
+	RSSLastSend Time `                 json:"rss_last_send"`
+
+	// An object describing the bounce summary for the campaign.
+	Bounces ReportBounces `            json:"bounces"`
+
+	// An object describing the forwards and forward activity for the campaign.
+	Forwards ReportForwards `          json:"forwards"`
+
+	// An object describing the open activity for the campaign.
+	Opens ReportOpens `                json:"opens"`
+
+	// An object describing the click activity for the campaign.
+	Clicks ReportClicks `              json:"clicks"`
+
+	// An object describing campaign engagement on Facebook.
+	FacebookLikes ReportFacebookLikes `json:"facebook_likes"`
+
+	// The average campaign statistics for your industry.
+	IndustryStats ReportIndustryStats `json:"industry_stats"`
+
+	// The average campaign statistics for your list.
+	ListStats ReportListStats `        json:"list_stats"`
+
+	// General stats about different groups of an A/B Split campaign.
+	ABSplit json.RawMessage `          json:"ab_split,omitempty"`
+
+	// Results for campaigns sent with timewarp.
+	Timewarp []*ReportTimewarp `       json:"timewarp"`
+
+	// An hourly breakdown of the performance of the campaign over the first 24 hours.
+	Timeseries []*ReportTimeseries `   json:"timeseries"`
+
+	// The url and password for the VIP report.
+	ShareReport ReportShare `          json:"share_report"`
+
+	// E-Commerce stats for a campaign.
+	Ecommerce ReportEcommerce `        json:"ecommerce"`
+
+	Links json.RawMessage `            json:"_links,omitempty"`
+}
+
+// ReportBounces is the bounce summary of a campaign
+type ReportBounces struct {
+	HardBounces  int `json:"hard_bounces"`
+	SoftBounces  int `json:"soft_bounces"`
+	SyntaxErrors int `json:"syntax_errors"`
+}
+
+// ReportForwards is the forward summary of a campaign
+type ReportForwards struct {
+	ForwardsCount int `json:"forwards_count"`
+	ForwardsOpens int `json:"forwards_opens"`
+}
+
+// ReportOpens is the open summary of a campaign
+type ReportOpens struct {
+	OpensTotal  int     `json:"opens_total"`
+	UniqueOpens int     `json:"unique_opens"`
+	OpenRate    float64 `json:"open_rate"`
+	LastOpen    Time    `json:"last_open"`
+}
+
+// ReportClicks is the click summary of a campaign
+type ReportClicks struct {
+	ClicksTotal            int     `json:"clicks_total"`
+	UniqueClicks           int     `json:"unique_clicks"`
+	UniqueSubscriberClicks int     `json:"unique_subscriber_clicks"`
+	ClickRate              float64 `json:"click_rate"`
+	LastClick              Time    `json:"last_click"`
+}
+
+// ReportFacebookLikes is the Facebook engagement of a campaign
+type ReportFacebookLikes struct {
+	RecipientLikes int `json:"recipient_likes"`
+	UniqueLikes    int `json:"unique_likes"`
+	FacebookLikes  int `json:"facebook_likes"`
+}
+
+// ReportIndustryStats are the average rates of an industry
+type ReportIndustryStats struct {
+	Type       string  `json:"type"`
+	OpenRate   float64 `json:"open_rate"`
+	ClickRate  float64 `json:"click_rate"`
+	BounceRate float64 `json:"bounce_rate"`
+	UnopenRate float64 `json:"unopen_rate"`
+	UnsubRate  float64 `json:"unsub_rate"`
+	AbuseRate  float64 `json:"abuse_rate"`
+}
+
+// ReportListStats are the average rates of the list
+type ReportListStats struct {
+	SubRate   float64 `json:"sub_rate"`
+	UnsubRate float64 `json:"unsub_rate"`
+	OpenRate  float64 `json:"open_rate"`
+	ClickRate float64 `json:"click_rate"`
+}
+
+// ReportTimewarp are the results for one time zone of a timewarp campaign
+type ReportTimewarp struct {
+	GMTOffset    int  `json:"gmt_offset"`
+	Opens        int  `json:"opens"`
+	LastOpen     Time `json:"last_open"`
+	UniqueOpens  int  `json:"unique_opens"`
+	Clicks       int  `json:"clicks"`
+	LastClick    Time `json:"last_click"`
+	UniqueClicks int  `json:"unique_clicks"`
+	Bounces      int  `json:"bounces"`
+}
+
+// ReportTimeseries is the performance of a campaign during one hour
+type ReportTimeseries struct {
+	Timestamp        Time `json:"timestamp"`
+	EmailsSent       int  `json:"emails_sent"`
+	UniqueOpens      int  `json:"unique_opens"`
+	RecipientsClicks int  `json:"recipients_clicks"`
+}
+
+// ReportShare is the url and password of a VIP report
+type ReportShare struct {
+	ShareURL      string `json:"share_url"`
+	SharePassword string `json:"share_password"`
+}
+
+// ReportEcommerce are the E-Commerce stats of a campaign
+type ReportEcommerce struct {
+	TotalOrders  int     `json:"total_orders"`
+	TotalSpent   float64 `json:"total_spent"`
+	TotalRevenue float64 `json:"total_revenue"`
+}
+
+// GetReports contains reports for sent campaigns
+type GetReports struct {
+	Reports    []*Report       `json:"reports"`
+	CampaignID string          `json:"campaign_id,omitempty"`
+	TotalItems int             `json:"total_items"`
+	Links      json.RawMessage `json:"_links,omitempty"`
+}
+
+// ReportOpen is a single open of a campaign
+type ReportOpen struct {
+	Timestamp Time `json:"timestamp"`
+}
+
+// ReportOpenMember is a member that opened a campaign
+type ReportOpenMember struct {
+	CampaignID    string                 `json:"campaign_id"`
+	ListID        string                 `json:"list_id"`
+	ListIsActive  bool                   `json:"list_is_active"`
+	ContactStatus string                 `json:"contact_status"`
+	EmailID       string                 `json:"email_id"`
+	EmailAddress  string                 `json:"email_address"`
+	MergeFields   map[string]interface{} `json:"merge_fields"`
+	VIP           bool                   `json:"vip"`
+	OpensCount    int                    `json:"opens_count"`
+	Opens         []*ReportOpen          `json:"opens"`
+}
+
+// GetOpenDetails contains the members that opened a campaign
+// https://developer.mailchimp.com/documentation/mailchimp/reference/reports/open-details/
+type GetOpenDetails struct {
+	Members    []*ReportOpenMember `json:"members"`
+	CampaignID string              `json:"campaign_id"`
+	TotalOpens int                 `json:"total_opens"`
+	TotalItems int                 `json:"total_items"`
+	Links      json.RawMessage     `json:"_links,omitempty"`
+}
+
+// ReportURLClicked is the click summary of a link in a campaign
+type ReportURLClicked struct {
+	ID                    string          `json:"id"`
+	URL                   string          `json:"url"`
+	TotalClicks           int             `json:"total_clicks"`
+	ClickPercentage       float64         `json:"click_percentage"`
+	UniqueClicks          int             `json:"unique_clicks"`
+	UniqueClickPercentage float64         `json:"unique_click_percentage"`
+	LastClick             Time            `json:"last_click"`
+	ABSplit               json.RawMessage `json:"ab_split,omitempty"`
+	CampaignID            string          `json:"campaign_id"`
+}
+
+// GetClickDetails contains the click summary of each link in a campaign
+// https://developer.mailchimp.com/documentation/mailchimp/reference/reports/click-details/
+type GetClickDetails struct {
+	URLsClicked []*ReportURLClicked `json:"urls_clicked"`
+	CampaignID  string              `json:"campaign_id"`
+	TotalItems  int                 `json:"total_items"`
+	Links       json.RawMessage     `json:"_links,omitempty"`
+}
+
+// ReportClickMember is a member that clicked a link in a campaign
+type ReportClickMember struct {
+	EmailID       string                 `json:"email_id"`
+	EmailAddress  string                 `json:"email_address"`
+	MergeFields   map[string]interface{} `json:"merge_fields"`
+	VIP           bool                   `json:"vip"`
+	Clicks        int                    `json:"clicks"`
+	CampaignID    string                 `json:"campaign_id"`
+	URLID         string                 `json:"url_id"`
+	ListID        string                 `json:"list_id"`
+	ListIsActive  bool                   `json:"list_is_active"`
+	ContactStatus string                 `json:"contact_status"`
+}
+
+// GetClickDetailsMembers contains the members that clicked a link
+// https://developer.mailchimp.com/documentation/mailchimp/reference/reports/click-details/members/
+type GetClickDetailsMembers struct {
+	Members    []*ReportClickMember `json:"members"`
+	CampaignID string               `json:"campaign_id"`
+	TotalItems int                  `json:"total_items"`
+	Links      json.RawMessage      `json:"_links,omitempty"`
+}
+
+// ReportActivity is a single action by a member, such as a open or click
+type ReportActivity struct {
+	// One of: open, click, bounce.
+	Action string `json:"action"`
+	// For bounces, the type of bounce, hard or soft.
+	Type      string `json:"type"`
+	Timestamp Time   `json:"timestamp"`
+	// For clicks, the url the member clicked.
+	URL string `json:"url"`
+	IP  string `json:"ip"`
+}
+
+// ReportEmailActivity is the activity of a member for a campaign
+type ReportEmailActivity struct {
+	CampaignID   string            `json:"campaign_id"`
+	ListID       string            `json:"list_id"`
+	ListIsActive bool              `json:"list_is_active"`
+	EmailID      string            `json:"email_id"`
+	EmailAddress string            `json:"email_address"`
+	Activity     []*ReportActivity `json:"activity"`
+}
+
+// GetEmailActivity contains the activity of each member for a campaign
+// https://developer.mailchimp.com/documentation/mailchimp/reference/reports/email-activity/
+type GetEmailActivity struct {
+	Emails     []*ReportEmailActivity `json:"emails"`
+	CampaignID string                 `json:"campaign_id"`
+	TotalItems int                    `json:"total_items"`
+	Links      json.RawMessage        `json:"_links,omitempty"`
+}
+
+// ReportUnsubscribe is a member that unsubscribed from a campaign
+type ReportUnsubscribe struct {
+	EmailID      string                 `json:"email_id"`
+	EmailAddress string                 `json:"email_address"`
+	MergeFields  map[string]interface{} `json:"merge_fields"`
+	VIP          bool                   `json:"vip"`
+	Timestamp    Time                   `json:"timestamp"`
+	Reason       string                 `json:"reason"`
+	CampaignID   string                 `json:"campaign_id"`
+	ListID       string                 `json:"list_id"`
+	ListIsActive bool                   `json:"list_is_active"`
+}
+
+// GetUnsubscribed contains the members that unsubscribed from a campaign
+// https://developer.mailchimp.com/documentation/mailchimp/reference/reports/unsubscribed/
+type GetUnsubscribed struct {
+	Unsubscribes []*ReportUnsubscribe `json:"unsubscribes"`
+	CampaignID   string               `json:"campaign_id"`
+	TotalItems   int                  `json:"total_items"`
+	Links        json.RawMessage      `json:"_links,omitempty"`
+}
+
+// ReportDomain is the performance of a campaign for one email domain
+type ReportDomain struct {
+	Domain     string  `json:"domain"`
+	EmailsSent int     `json:"emails_sent"`
+	Bounces    int     `json:"bounces"`
+	Opens      int     `json:"opens"`
+	Clicks     int     `json:"clicks"`
+	Unsubs     int     `json:"unsubs"`
+	Delivered  int     `json:"delivered"`
+	EmailsPct  float64 `json:"emails_pct"`
+	BouncesPct float64 `json:"bounces_pct"`
+	OpensPct   float64 `json:"opens_pct"`
+	ClicksPct  float64 `json:"clicks_pct"`
+	UnsubsPct  float64 `json:"unsubs_pct"`
+}
+
+// GetDomainPerformance contains the performance of a campaign by email domain
+// https://developer.mailchimp.com/documentation/mailchimp/reference/reports/domain-performance/
+type GetDomainPerformance struct {
+	Domains    []*ReportDomain `json:"domains"`
+	TotalSent  int             `json:"total_sent"`
+	CampaignID string          `json:"campaign_id"`
+	TotalItems int             `json:"total_items"`
+	Links      json.RawMessage `json:"_links,omitempty"`
+}
+
+// ReportLocation is the number of opens from a region
+type ReportLocation struct {
+	CountryCode string `json:"country_code"`
+	Region      string `json:"region"`
+	RegionName  string `json:"region_name"`
+	Opens       int    `json:"opens"`
+}
+
+// GetLocations contains the top open locations of a campaign
+// https://developer.mailchimp.com/documentation/mailchimp/reference/reports/locations/
+type GetLocations struct {
+	Locations  []*ReportLocation `json:"locations"`
+	CampaignID string            `json:"campaign_id"`
+	TotalItems int               `json:"total_items"`
+	Links      json.RawMessage   `json:"_links,omitempty"`
+}
+
+// ReportAbuse is a abuse complaint for a campaign
+type ReportAbuse struct {
+	ID           int                    `json:"id"`
+	CampaignID   string                 `json:"campaign_id"`
+	ListID       string                 `json:"list_id"`
+	EmailID      string                 `json:"email_id"`
+	EmailAddress string                 `json:"email_address"`
+	MergeFields  map[string]interface{} `json:"merge_fields"`
+	VIP          bool                   `json:"vip"`
+	Date         Time                   `json:"date"`
+}
+
+// GetAbuseReports contains the abuse complaints for a campaign
+// https://developer.mailchimp.com/documentation/mailchimp/reference/reports/abuse-reports/
+type GetAbuseReports struct {
+	AbuseReports []*ReportAbuse  `json:"abuse_reports"`
+	CampaignID   string          `json:"campaign_id"`
+	TotalItems   int             `json:"total_items"`
+	Links        json.RawMessage `json:"_links,omitempty"`
+}
+
+// ReportAdvice is feedback based on a campaign’s statistics
+type ReportAdvice struct {
+	// One of: negative, positive, neutral.
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// GetAdvice contains feedback for a campaign
+// https://developer.mailchimp.com/documentation/mailchimp/reference/reports/advice/
+type GetAdvice struct {
+	Advice     []*ReportAdvice `json:"advice"`
+	CampaignID string          `json:"campaign_id"`
+	TotalItems int             `json:"total_items"`
+	Links      json.RawMessage `json:"_links,omitempty"`
+}
+
+// ReportEepurl is the activity of the campaign’s short url
+// https://developer.mailchimp.com/documentation/mailchimp/reference/reports/eepurl/
+type ReportEepurl struct {
+	Twitter    ReportEepurlTwitter     `json:"twitter"`
+	Clicks     ReportEepurlClicks      `json:"clicks"`
+	Referrers  []*ReportEepurlReferrer `json:"referrers"`
+	Eepurl     string                  `json:"eepurl"`
+	CampaignID string                  `json:"campaign_id"`
+	Links      json.RawMessage         `json:"_links,omitempty"`
+}
+
+// ReportEepurlTwitter is the Twitter activity of the short url
+type ReportEepurlTwitter struct {
+	Tweets     int                  `json:"tweets"`
+	FirstTweet Time                 `json:"first_tweet"`
+	LastTweet  Time                 `json:"last_tweet"`
+	Retweets   int                  `json:"retweets"`
+	Statuses   []*ReportTweetStatus `json:"statuses"`
+}
+
+// ReportTweetStatus is a tweet with the short url
+type ReportTweetStatus struct {
+	Status     string `json:"status"`
+	ScreenName string `json:"screen_name"`
+	StatusID   string `json:"status_id"`
+	Datetime   Time   `json:"datetime"`
+	IsRetweet  bool   `json:"is_retweet"`
+}
+
+// ReportEepurlClicks are the clicks on the short url
+type ReportEepurlClicks struct {
+	Clicks         int                    `json:"clicks"`
+	FirstClick     Time                   `json:"first_click"`
+	LastClick      Time                   `json:"last_click"`
+	ClickLocations []*ReportClickLocation `json:"click_locations"`
+}
+
+// ReportClickLocation is where a click on the short url came from
+type ReportClickLocation struct {
+	Country string `json:"country"`
+	Region  string `json:"region"`
+}
+
+// ReportEepurlReferrer is a site referring clicks to the short url
+type ReportEepurlReferrer struct {
+	Referrer   string `json:"referrer"`
+	Clicks     int    `json:"clicks"`
+	FirstClick Time   `json:"first_click"`
+	LastClick  Time   `json:"last_click"`
+}
+
 // SentTo contains sent information about a single member for a campaign.
 type SentTo struct {
 	EmailID      string          `json:"email_id,omitempty"`
@@ -32,7 +482,7 @@ type SentTo struct {
 	VIP          bool            `json:"vip,omitempty"`
 	Status       string          `json:"status,omitempty"`
 	OpenCount    int             `json:"open_count,omitempty"`
-	LastOpen     string          `json:"last_open,omitempty"`
+	LastOpen     Time            `json:"last_open,omitempty"`
 	ABSplitGroup json.RawMessage `json:"absplit_group,omitempty"`
 	GMTOffset    int             `json:"gmt_offset,omitempty"`
 	CampaignID   string          `json:"campaign_id,omitempty"`
@@ -71,3 +521,167 @@ func (c *Client) GetSentTo(ctx context.Context, campaignID string, params ...Par
 
 	return sentToResponse, nil
 }
+
+// getReport gets a report resource and decodes it into v
+func (c *Client) getReport(ctx context.Context, resource string, params []Parameters, v interface{}) error {
+	response, err := c.Get(ctx, resource, requestParameters(params))
+	if err != nil {
+		Log.WithFields(logrus.Fields{
+			"resource": resource,
+			"error":    err.Error(),
+		}).Error("response error", caller())
+		return err
+	}
+
+	err = json.Unmarshal(response, v)
+	if err != nil {
+		Log.WithFields(logrus.Fields{
+			"resource": resource,
+			"error":    err.Error(),
+		}).Error("response error", caller())
+		return err
+	}
+
+	return nil
+}
+
+// GetReports returns the reports of all sent campaigns.
+// Optional params: fields, exclude_fields, count, offset, type,
+// before_send_time, since_send_time.
+func (c *Client) GetReports(ctx context.Context, params ...Parameters) (*GetReports, error) {
+	var reports *GetReports
+	if err := c.getReport(ctx, ReportURL, params, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// GetReport returns the report of a sent campaign
+func (c *Client) GetReport(ctx context.Context, campaignID string, params ...Parameters) (*Report, error) {
+	var report *Report
+	if err := c.getReport(ctx, slashJoin(ReportURL, campaignID), params, &report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// GetOpenDetails returns the members that opened a campaign.
+// Optional params: fields, exclude_fields, count, offset, since.
+func (c *Client) GetOpenDetails(ctx context.Context, campaignID string, params ...Parameters) (*GetOpenDetails, error) {
+	var details *GetOpenDetails
+	if err := c.getReport(ctx, slashJoin(ReportURL, campaignID, OpenDetailsURL), params, &details); err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
+// GetClickDetails returns the click summary of each link in a campaign.
+// Optional params: fields, exclude_fields, count, offset.
+func (c *Client) GetClickDetails(ctx context.Context, campaignID string, params ...Parameters) (*GetClickDetails, error) {
+	var details *GetClickDetails
+	if err := c.getReport(ctx, slashJoin(ReportURL, campaignID, ClickDetailsURL), params, &details); err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
+// GetClickDetailsMembers returns the members that clicked a link, linkID
+// is the ID of a ReportURLClicked.
+// Optional params: fields, exclude_fields, count, offset.
+func (c *Client) GetClickDetailsMembers(ctx context.Context, campaignID string, linkID string, params ...Parameters) (*GetClickDetailsMembers, error) {
+	var members *GetClickDetailsMembers
+	if err := c.getReport(ctx, slashJoin(ReportURL, campaignID, ClickDetailsURL, linkID, MembersURL), params, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// GetEmailActivity returns the activity of each member for a campaign.
+// Optional params: fields, exclude_fields, count, offset, since.
+func (c *Client) GetEmailActivity(ctx context.Context, campaignID string, params ...Parameters) (*GetEmailActivity, error) {
+	var activity *GetEmailActivity
+	if err := c.getReport(ctx, slashJoin(ReportURL, campaignID, EmailActivityURL), params, &activity); err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
+
+// GetMemberEmailActivity returns the activity of a single member for a campaign
+func (c *Client) GetMemberEmailActivity(ctx context.Context, campaignID string, email string, params ...Parameters) (*ReportEmailActivity, error) {
+	var activity *ReportEmailActivity
+	if err := c.getReport(ctx, slashJoin(ReportURL, campaignID, EmailActivityURL, MemberEmailToID(email)), params, &activity); err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
+
+// GetUnsubscribed returns the members that unsubscribed from a campaign.
+// Optional params: fields, exclude_fields, count, offset.
+func (c *Client) GetUnsubscribed(ctx context.Context, campaignID string, params ...Parameters) (*GetUnsubscribed, error) {
+	var unsubscribed *GetUnsubscribed
+	if err := c.getReport(ctx, slashJoin(ReportURL, campaignID, UnsubscribedURL), params, &unsubscribed); err != nil {
+		return nil, err
+	}
+	return unsubscribed, nil
+}
+
+// GetDomainPerformance returns the performance of a campaign by email domain.
+// Optional params: fields, exclude_fields.
+func (c *Client) GetDomainPerformance(ctx context.Context, campaignID string, params ...Parameters) (*GetDomainPerformance, error) {
+	var domains *GetDomainPerformance
+	if err := c.getReport(ctx, slashJoin(ReportURL, campaignID, DomainPerformanceURL), params, &domains); err != nil {
+		return nil, err
+	}
+	return domains, nil
+}
+
+// GetLocations returns the top open locations of a campaign.
+// Optional params: fields, exclude_fields, count, offset.
+func (c *Client) GetLocations(ctx context.Context, campaignID string, params ...Parameters) (*GetLocations, error) {
+	var locations *GetLocations
+	if err := c.getReport(ctx, slashJoin(ReportURL, campaignID, LocationsURL), params, &locations); err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
+
+// GetAbuseReports returns the abuse complaints for a campaign.
+// Optional params: fields, exclude_fields.
+func (c *Client) GetAbuseReports(ctx context.Context, campaignID string, params ...Parameters) (*GetAbuseReports, error) {
+	var reports *GetAbuseReports
+	if err := c.getReport(ctx, slashJoin(ReportURL, campaignID, AbuseReportsURL), params, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// GetAdvice returns feedback based on a campaign’s statistics.
+// Optional params: fields, exclude_fields.
+func (c *Client) GetAdvice(ctx context.Context, campaignID string, params ...Parameters) (*GetAdvice, error) {
+	var advice *GetAdvice
+	if err := c.getReport(ctx, slashJoin(ReportURL, campaignID, AdviceURL), params, &advice); err != nil {
+		return nil, err
+	}
+	return advice, nil
+}
+
+// GetEepurl returns the activity of the campaign’s short url.
+// Optional params: fields, exclude_fields.
+func (c *Client) GetEepurl(ctx context.Context, campaignID string, params ...Parameters) (*ReportEepurl, error) {
+	var eepurl *ReportEepurl
+	if err := c.getReport(ctx, slashJoin(ReportURL, campaignID, EepurlURL), params, &eepurl); err != nil {
+		return nil, err
+	}
+	return eepurl, nil
+}
+
+// GetSubReports returns the reports of the child campaigns of a
+// RSS or A/B Testing campaign.
+// Optional params: fields, exclude_fields.
+func (c *Client) GetSubReports(ctx context.Context, campaignID string, params ...Parameters) (*GetReports, error) {
+	var reports *GetReports
+	if err := c.getReport(ctx, slashJoin(ReportURL, campaignID, SubReportsURL), params, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
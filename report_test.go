@@ -0,0 +1,271 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+
+	check "gopkg.in/check.v1"
+
+	t "github.com/greatbeyond/mailchimp/testing"
+)
+
+var _ = check.Suite(&ReportSuite{})
+
+type ReportSuite struct {
+	client *Client
+	server *t.MockServer
+	ctx    context.Context
+}
+
+func (s *ReportSuite) SetUpSuite(c *check.C) {}
+
+func (s *ReportSuite) SetUpTest(c *check.C) {
+	s.server = t.NewMockServer()
+	s.server.SetChecker(c)
+
+	s.client = NewClient()
+	s.client.HTTPClient = s.server.HTTPClient
+
+	s.ctx = NewContextWithToken(context.Background(), os.Getenv("MAILCHIMP_TEST_TOKEN"))
+	s.ctx = NewContextWithURL(s.ctx, "http://us13.api.mailchimp.com/3.0/")
+}
+
+func (s *ReportSuite) TearDownTest(c *check.C) {}
+
+const reportResponse = `{"id":"42694e9e57","campaign_title":"Freddie's Jokes","type":"regular","list_id":"57afe96172","list_is_active":true,"list_name":"Freddie's Jokes","subject_line":"Hello","emails_sent":200,"abuse_reports":1,"unsubscribed":2,"send_time":"2015-09-15T19:15:47+00:00","bounces":{"hard_bounces":3,"soft_bounces":4,"syntax_errors":0},"forwards":{"forwards_count":5,"forwards_opens":1},"opens":{"opens_total":150,"unique_opens":100,"open_rate":0.5,"last_open":"2015-09-15T19:15:47+00:00"},"clicks":{"clicks_total":40,"unique_clicks":30,"unique_subscriber_clicks":20,"click_rate":0.1,"last_click":"2015-09-15T20:00:00+00:00"},"facebook_likes":{"recipient_likes":0,"unique_likes":0,"facebook_likes":0},"industry_stats":{"type":"Social Networks and Online Communities","open_rate":0.1,"click_rate":0.02,"bounce_rate":0.003,"unopen_rate":0.9,"unsub_rate":0.001,"abuse_rate":0.0001},"list_stats":{"sub_rate":1,"unsub_rate":0,"open_rate":50,"click_rate":10},"timewarp":[],"timeseries":[{"timestamp":"2015-09-15T19:00:00+00:00","emails_sent":200,"unique_opens":50,"recipients_clicks":10}],"share_report":{"share_url":"http://us1.campaign-archive.com/?u=1&id=2","share_password":"freddie"},"ecommerce":{"total_orders":1,"total_spent":10.5,"total_revenue":10.5}}`
+
+func (s *ReportSuite) Test_GetReport(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/reports/42694e9e57",
+		Code:   200,
+		Body:   reportResponse,
+	})
+
+	report, err := s.client.GetReport(s.ctx, "42694e9e57")
+	c.Assert(err, check.IsNil)
+	c.Assert(report.ID, check.Equals, "42694e9e57")
+	c.Assert(report.EmailsSent, check.Equals, 200)
+	c.Assert(report.SendTime, check.Equals, mustParseTime("2015-09-15T19:15:47+00:00"))
+	c.Assert(report.Bounces, check.Equals, ReportBounces{HardBounces: 3, SoftBounces: 4})
+	c.Assert(report.Opens.OpenRate, check.Equals, 0.5)
+	c.Assert(report.Clicks.LastClick, check.Equals, mustParseTime("2015-09-15T20:00:00+00:00"))
+	c.Assert(report.IndustryStats.Type, check.Equals, "Social Networks and Online Communities")
+	c.Assert(report.Timeseries, check.DeepEquals, []*ReportTimeseries{{
+		Timestamp:        mustParseTime("2015-09-15T19:00:00+00:00"),
+		EmailsSent:       200,
+		UniqueOpens:      50,
+		RecipientsClicks: 10,
+	}})
+	c.Assert(report.ShareReport.SharePassword, check.Equals, "freddie")
+	c.Assert(report.Ecommerce.TotalRevenue, check.Equals, 10.5)
+	s.server.VerifyNoMoreRequests(c)
+}
+
+func (s *ReportSuite) Test_GetReport_NotFound(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/reports/*",
+		Code:   404,
+		Body:   `{"type":"http://developer.mailchimp.com/documentation/mailchimp/guides/error-glossary/","title":"Resource Not Found","status":404,"detail":"The requested resource could not be found.","instance":""}`,
+	})
+
+	report, err := s.client.GetReport(s.ctx, "missing")
+	c.Assert(IsNotFound(err), check.Equals, true)
+	c.Assert(report, check.IsNil)
+}
+
+func (s *ReportSuite) Test_GetReports_Paginated(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/reports",
+		Query:  url.Values{"count": {"1"}, "offset": {"1"}},
+		Code:   200,
+		Body:   `{"reports":[` + reportResponse + `],"total_items":2}`,
+	})
+
+	reports, err := s.client.GetReports(s.ctx, Parameters{"count": 1, "offset": 1})
+	c.Assert(err, check.IsNil)
+	c.Assert(reports.TotalItems, check.Equals, 2)
+	c.Assert(reports.Reports, check.HasLen, 1)
+	c.Assert(reports.Reports[0].CampaignTitle, check.Equals, "Freddie's Jokes")
+	s.server.VerifyNoMoreRequests(c)
+}
+
+func (s *ReportSuite) Test_GetOpenDetails(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/reports/42694e9e57/open-details",
+		Code:   200,
+		Body:   `{"members":[{"campaign_id":"42694e9e57","list_id":"57afe96172","list_is_active":true,"contact_status":"subscribed","email_id":"62eeb292278cc15f5817cb78f7790b08","email_address":"urist.mcvankab@freddiesjokes.com","merge_fields":{"FNAME":"Urist"},"vip":false,"opens_count":2,"opens":[{"timestamp":"2015-09-15T19:15:47+00:00"},{"timestamp":"2015-09-16T08:00:00+00:00"}]}],"campaign_id":"42694e9e57","total_opens":2,"total_items":1}`,
+	})
+
+	details, err := s.client.GetOpenDetails(s.ctx, "42694e9e57")
+	c.Assert(err, check.IsNil)
+	c.Assert(details.TotalOpens, check.Equals, 2)
+	c.Assert(details.Members[0].OpensCount, check.Equals, 2)
+	c.Assert(details.Members[0].MergeFields["FNAME"], check.Equals, "Urist")
+	c.Assert(details.Members[0].Opens[1].Timestamp, check.Equals, mustParseTime("2015-09-16T08:00:00+00:00"))
+}
+
+func (s *ReportSuite) Test_GetClickDetails(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/reports/42694e9e57/click-details",
+		Code:   200,
+		Body:   `{"urls_clicked":[{"id":"7df2e2bf9e","url":"http://freddiesjokes.com","total_clicks":10,"click_percentage":0.5,"unique_clicks":5,"unique_click_percentage":0.25,"last_click":"2015-09-15T20:00:00+00:00","campaign_id":"42694e9e57"}],"campaign_id":"42694e9e57","total_items":1}`,
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/reports/42694e9e57/click-details/7df2e2bf9e/members",
+		Code:   200,
+		Body:   `{"members":[{"email_id":"62eeb292278cc15f5817cb78f7790b08","email_address":"urist.mcvankab@freddiesjokes.com","clicks":3,"campaign_id":"42694e9e57","url_id":"7df2e2bf9e","list_id":"57afe96172","contact_status":"subscribed"}],"campaign_id":"42694e9e57","total_items":1}`,
+	})
+
+	details, err := s.client.GetClickDetails(s.ctx, "42694e9e57")
+	c.Assert(err, check.IsNil)
+	c.Assert(details.URLsClicked, check.HasLen, 1)
+	c.Assert(details.URLsClicked[0].UniqueClickPercentage, check.Equals, 0.25)
+
+	members, err := s.client.GetClickDetailsMembers(s.ctx, "42694e9e57", details.URLsClicked[0].ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(members.Members[0].Clicks, check.Equals, 3)
+	c.Assert(members.Members[0].URLID, check.Equals, "7df2e2bf9e")
+	s.server.VerifyNoMoreRequests(c)
+}
+
+func (s *ReportSuite) Test_GetEmailActivity(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/reports/42694e9e57/email-activity",
+		Code:   200,
+		Body:   `{"emails":[{"campaign_id":"42694e9e57","list_id":"57afe96172","email_id":"62eeb292278cc15f5817cb78f7790b08","email_address":"urist.mcvankab@freddiesjokes.com","activity":[{"action":"open","timestamp":"2015-09-15T19:15:47+00:00","ip":"127.0.0.1"},{"action":"click","timestamp":"2015-09-15T19:16:00+00:00","url":"http://freddiesjokes.com","ip":"127.0.0.1"}]}],"campaign_id":"42694e9e57","total_items":1}`,
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/reports/42694e9e57/email-activity/62eeb292278cc15f5817cb78f7790b08",
+		Code:   200,
+		Body:   `{"campaign_id":"42694e9e57","email_id":"62eeb292278cc15f5817cb78f7790b08","email_address":"urist.mcvankab@freddiesjokes.com","activity":[{"action":"bounce","type":"hard","timestamp":"2015-09-15T19:15:47+00:00"}]}`,
+	})
+
+	activity, err := s.client.GetEmailActivity(s.ctx, "42694e9e57")
+	c.Assert(err, check.IsNil)
+	c.Assert(activity.Emails[0].Activity, check.HasLen, 2)
+	c.Assert(activity.Emails[0].Activity[1].URL, check.Equals, "http://freddiesjokes.com")
+
+	member, err := s.client.GetMemberEmailActivity(s.ctx, "42694e9e57", "Urist.McVankab@freddiesjokes.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(member.Activity[0].Type, check.Equals, "hard")
+	s.server.VerifyNoMoreRequests(c)
+}
+
+func (s *ReportSuite) Test_GetUnsubscribed(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/reports/42694e9e57/unsubscribed",
+		Code:   200,
+		Body:   `{"unsubscribes":[{"email_id":"62eeb292278cc15f5817cb78f7790b08","email_address":"urist.mcvankab@freddiesjokes.com","timestamp":"2015-09-15T19:15:47+00:00","reason":"N/A (Unsubscribed by admin)","campaign_id":"42694e9e57","list_id":"57afe96172"}],"campaign_id":"42694e9e57","total_items":1}`,
+	})
+
+	unsubscribed, err := s.client.GetUnsubscribed(s.ctx, "42694e9e57")
+	c.Assert(err, check.IsNil)
+	c.Assert(unsubscribed.Unsubscribes[0].Reason, check.Equals, "N/A (Unsubscribed by admin)")
+}
+
+func (s *ReportSuite) Test_GetDomainPerformance(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/reports/42694e9e57/domain-performance",
+		Code:   200,
+		Body:   `{"domains":[{"domain":"gmail.com","emails_sent":100,"bounces":1,"opens":50,"clicks":10,"unsubs":0,"delivered":99,"emails_pct":0.5,"bounces_pct":0.01,"opens_pct":0.5,"clicks_pct":0.1,"unsubs_pct":0}],"total_sent":200,"campaign_id":"42694e9e57","total_items":1}`,
+	})
+
+	domains, err := s.client.GetDomainPerformance(s.ctx, "42694e9e57")
+	c.Assert(err, check.IsNil)
+	c.Assert(domains.TotalSent, check.Equals, 200)
+	c.Assert(domains.Domains[0].Domain, check.Equals, "gmail.com")
+	c.Assert(domains.Domains[0].Delivered, check.Equals, 99)
+}
+
+func (s *ReportSuite) Test_GetLocations_AbuseReports_Advice(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/reports/42694e9e57/locations",
+		Code:   200,
+		Body:   `{"locations":[{"country_code":"SE","region":"AB","region_name":"Stockholm","opens":12}],"campaign_id":"42694e9e57","total_items":1}`,
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/reports/42694e9e57/abuse-reports",
+		Code:   200,
+		Body:   `{"abuse_reports":[{"id":1,"campaign_id":"42694e9e57","list_id":"57afe96172","email_address":"urist.mcvankab@freddiesjokes.com","date":"2015-09-16T08:00:00+00:00"}],"campaign_id":"42694e9e57","total_items":1}`,
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/reports/42694e9e57/advice",
+		Code:   200,
+		Body:   `{"advice":[{"type":"positive","message":"Your open rate is above average."}],"campaign_id":"42694e9e57","total_items":1}`,
+	})
+
+	locations, err := s.client.GetLocations(s.ctx, "42694e9e57")
+	c.Assert(err, check.IsNil)
+	c.Assert(locations.Locations[0], check.DeepEquals, &ReportLocation{CountryCode: "SE", Region: "AB", RegionName: "Stockholm", Opens: 12})
+
+	abuse, err := s.client.GetAbuseReports(s.ctx, "42694e9e57")
+	c.Assert(err, check.IsNil)
+	c.Assert(abuse.AbuseReports[0].Date, check.Equals, mustParseTime("2015-09-16T08:00:00+00:00"))
+
+	advice, err := s.client.GetAdvice(s.ctx, "42694e9e57")
+	c.Assert(err, check.IsNil)
+	c.Assert(advice.Advice[0].Type, check.Equals, "positive")
+	s.server.VerifyNoMoreRequests(c)
+}
+
+func (s *ReportSuite) Test_GetEepurl(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/reports/42694e9e57/eepurl",
+		Code:   200,
+		Body:   `{"twitter":{"tweets":1,"first_tweet":"2015-09-15T19:15:47+00:00","last_tweet":"2015-09-15T19:15:47+00:00","retweets":0,"statuses":[{"status":"Check this out","screen_name":"freddie","status_id":"1","datetime":"2015-09-15T19:15:47+00:00","is_retweet":false}]},"clicks":{"clicks":4,"first_click":"","last_click":"","click_locations":[{"country":"SE","region":"AB"}]},"referrers":[{"referrer":"t.co","clicks":4,"first_click":"","last_click":""}],"eepurl":"http://eepurl.com/abc","campaign_id":"42694e9e57"}`,
+	})
+
+	eepurl, err := s.client.GetEepurl(s.ctx, "42694e9e57")
+	c.Assert(err, check.IsNil)
+	c.Assert(eepurl.Eepurl, check.Equals, "http://eepurl.com/abc")
+	c.Assert(eepurl.Twitter.Statuses[0].ScreenName, check.Equals, "freddie")
+	c.Assert(eepurl.Clicks.FirstClick.IsZero(), check.Equals, true)
+	c.Assert(eepurl.Referrers[0].Referrer, check.Equals, "t.co")
+}
+
+func (s *ReportSuite) Test_GetSubReports(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/reports/42694e9e57/sub-reports",
+		Code:   200,
+		Body:   `{"reports":[` + reportResponse + `],"campaign_id":"42694e9e57","total_items":1}`,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(r.URL.Query().Get("fields"), check.Equals, "reports.id")
+		},
+	})
+
+	reports, err := s.client.GetSubReports(s.ctx, "42694e9e57", Parameters{"fields": "reports.id"})
+	c.Assert(err, check.IsNil)
+	c.Assert(reports.CampaignID, check.Equals, "42694e9e57")
+	c.Assert(reports.Reports, check.HasLen, 1)
+}
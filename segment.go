@@ -43,10 +43,10 @@ type Segment struct {
 	Type string `json:"type,omitempty"`
 
 	// The date and time the segment was created.
-	CreatedAt string `json:"created_at,omitempty"`
+	CreatedAt Time `json:"created_at"`
 
 	// The date and time the segment was last updated.
-	UpdatedAt string `json:"updated_at,omitempty"`
+	UpdatedAt Time `json:"updated_at"`
 
 	// The conditions of the segment. Static and fuzzy segments don’t have conditions.
 	Options map[string]interface{} `json:"options,omitempty"`
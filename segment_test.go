@@ -90,8 +90,8 @@ func (s *SegmentSuite) Test_CreateSegment_Normal(c *check.C) {
 		Name:        "Freddie'sMostPopularJokes",
 		MemberCount: 9,
 		Type:        "static",
-		CreatedAt:   "2015-09-16 21:14:46",
-		UpdatedAt:   "2015-09-16 21:14:47",
+		CreatedAt:   mustParseTime("2015-09-16 21:14:46"),
+		UpdatedAt:   mustParseTime("2015-09-16 21:14:47"),
 		Options: map[string]interface{}{
 			"match":      "any",
 			"conditions": []interface{}{},
@@ -163,7 +163,7 @@ func (s *SegmentSuite) Test_GetSegments_Normal(c *check.C) {
 	s.server.AddResponse(&t.MockResponse{
 		Method: "GET",
 		Code:   200,
-		Body:   `{"segments":[{"id":49377,"name":"Freddie'sMostPopularJokes","member_count":9,"type":"static","created_at":"2015-09-1621:14:46","updated_at":"2015-09-1621:14:47","options":{"match":"any","conditions":[]},"list_id":"57afe96172"}],"list_id":"57afe96172","total_items":1}`,
+		Body:   `{"segments":[{"id":49377,"name":"Freddie'sMostPopularJokes","member_count":9,"type":"static","created_at":"2015-09-16T21:14:46+00:00","updated_at":"2015-09-16T21:14:47+00:00","options":{"match":"any","conditions":[]},"list_id":"57afe96172"}],"list_id":"57afe96172","total_items":1}`,
 		CheckFn: func(r *http.Request, body string) {
 			c.Assert(r.RequestURI, check.Equals, "http://us13.api.mailchimp.com/3.0/lists/57afe96172/segments")
 		},
@@ -178,8 +178,8 @@ func (s *SegmentSuite) Test_GetSegments_Normal(c *check.C) {
 		Name:        "Freddie'sMostPopularJokes",
 		MemberCount: 9,
 		Type:        "static",
-		CreatedAt:   "2015-09-1621:14:46",
-		UpdatedAt:   "2015-09-1621:14:47",
+		CreatedAt:   mustParseTime("2015-09-16T21:14:46+00:00"),
+		UpdatedAt:   mustParseTime("2015-09-16T21:14:47+00:00"),
 		Options: map[string]interface{}{
 			"match":      "any",
 			"conditions": []interface{}{},
@@ -236,8 +236,8 @@ func (s *SegmentSuite) Test_GetSegment_Normal(c *check.C) {
 		Name:        "Freddie'sMostPopularJokes",
 		MemberCount: 9,
 		Type:        "static",
-		CreatedAt:   "2015-09-16 21:14:46",
-		UpdatedAt:   "2015-09-16 21:14:47",
+		CreatedAt:   mustParseTime("2015-09-16 21:14:46"),
+		UpdatedAt:   mustParseTime("2015-09-16 21:14:47"),
 		Options: map[string]interface{}{
 			"match":      "any",
 			"conditions": []interface{}{},
@@ -310,11 +310,11 @@ func (s *SegmentSuite) Test_GetMembers_Normal(c *check.C) {
 			AvgClickRate: 0,
 		},
 		IPSignup:        "",
-		TimestampSignup: "",
+		TimestampSignup: mustParseTime(""),
 		IPOpt:           "198.2.191.34",
-		TimestampOpt:    "2015-09-16 19:24:29",
+		TimestampOpt:    mustParseTime("2015-09-16 19:24:29"),
 		MemberRating:    2,
-		LastChanged:     "2015-09-16 19:24:29",
+		LastChanged:     mustParseTime("2015-09-16 19:24:29"),
 		Language:        "",
 		Vip:             false,
 		EmailClient:     "",
@@ -424,8 +424,8 @@ func (s *SegmentSuite) Test_Update_Normal(c *check.C) {
 		Name:        "Freddie'sMostPopularJokes",
 		MemberCount: 9,
 		Type:        "static",
-		CreatedAt:   "2015-09-16 21:14:46",
-		UpdatedAt:   "2015-09-16 21:14:47",
+		CreatedAt:   mustParseTime("2015-09-16 21:14:46"),
+		UpdatedAt:   mustParseTime("2015-09-16 21:14:47"),
 		Options: map[string]interface{}{
 			"match":      "any",
 			"conditions": []interface{}{},
@@ -0,0 +1,1430 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testing
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errorGlossary is the type of every api error
+const errorGlossary = "http://developer.mailchimp.com/documentation/mailchimp/guides/error-glossary/"
+
+// object is a JSON object as sent and returned by the api
+type object map[string]interface{}
+
+// FakeServer is a in-memory implementation of the main Mailchimp v3
+// resources: lists, members, merge fields, segments, webhooks and
+// campaigns with their content and actions. Unlike MockServer it keeps
+// state between requests, so multi-step flows can be tested end to end.
+//
+//	server := testing.NewFakeServer()
+//	defer server.Close()
+//	client.HTTPClient = server.HTTPClient
+//
+// Errors use the same titles and status codes as the real api, such as
+// 404 "Resource Not Found" and 400 "Member Exists". Saved segment
+// conditions are stored but not evaluated, those segments have no members.
+type FakeServer struct {
+	// The BaseURL of the server is unique with every server start.
+	BaseURL    string
+	Server     *httptest.Server
+	HTTPClient *http.Client
+
+	// Now returns the current time, replace it for deterministic timestamps.
+	Now func() time.Time
+
+	mu        sync.Mutex
+	faults    *Faults
+	nextID    int
+	lists     []*fakeList
+	campaigns []*fakeCampaign
+}
+
+type fakeList struct {
+	data        object
+	members     []object
+	mergeFields []object
+	segments    []*fakeSegment
+	webhooks    []object
+	nextMergeID int
+	nextSegment int
+}
+
+type fakeSegment struct {
+	data    object
+	members []string
+}
+
+type fakeCampaign struct {
+	data    object
+	content object
+}
+
+// NewFakeServer starts a new fake server. All requests made with
+// HTTPClient are sent to it, whatever the host.
+func NewFakeServer() *FakeServer {
+	f := &FakeServer{
+		Now: time.Now,
+	}
+
+	server := httptest.NewServer(f)
+
+	f.BaseURL = server.URL
+	f.Server = server
+	f.HTTPClient = &http.Client{
+		Transport: &http.Transport{
+			Proxy: func(req *http.Request) (*url.URL, error) {
+				return url.Parse(server.URL)
+			},
+		},
+	}
+
+	return f
+}
+
+// Close shuts down the server
+func (f *FakeServer) Close() {
+	f.Server.Close()
+}
+
+// SetCampaignStatus changes the status of a campaign, to test states the
+// fake can't reach by itself, such as sending. It returns false if there
+// is no such campaign.
+func (f *FakeServer) SetCampaignStatus(id string, status string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	campaign := f.campaign(id)
+	if campaign == nil {
+		return false
+	}
+	campaign.data["status"] = status
+	return true
+}
+
+// SetFaults injects faults in front of the fake, nil turns it off. Requests
+// that get a fault are not applied, except for truncated responses.
+func (f *FakeServer) SetFaults(faults *Faults) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.faults = faults
+}
+
+// fakeError is returned by the handlers and written as a api error
+type fakeError struct {
+	status int
+	title  string
+	detail string
+	errors []object
+}
+
+func notFound() *fakeError {
+	return &fakeError{http.StatusNotFound, "Resource Not Found", "The requested resource could not be found.", nil}
+}
+
+func invalidResource(detail string, fields ...string) *fakeError {
+	e := &fakeError{status: http.StatusBadRequest, title: "Invalid Resource", detail: detail}
+	for i := 0; i+1 < len(fields); i += 2 {
+		e.errors = append(e.errors, object{"field": fields[i], "message": fields[i+1]})
+	}
+	return e
+}
+
+func badRequest(detail string) *fakeError {
+	return &fakeError{http.StatusBadRequest, "Bad Request", detail, nil}
+}
+
+const validationDetail = "The resource submitted could not be validated. For field-specific details, see the 'errors' array."
+
+// ServeHTTP fulfills http.Handler
+func (f *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	faults := f.faults
+	f.mu.Unlock()
+
+	faults.Handler(http.HandlerFunc(f.serve)).ServeHTTP(w, r)
+}
+
+func (f *FakeServer) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") == "" {
+		f.writeError(w, &fakeError{http.StatusUnauthorized, "API Key Missing", "Your request did not include an API key.", nil})
+		return
+	}
+
+	var body object
+	if data, _ := ioutil.ReadAll(r.Body); len(data) > 0 {
+		if err := json.Unmarshal(data, &body); err != nil {
+			f.writeError(w, &fakeError{http.StatusBadRequest, "JSON Parse Exception", "We encountered an unspecified JSON parsing error.", nil})
+			return
+		}
+	}
+	if body == nil {
+		body = object{}
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/3.0")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	status, response, ferr := f.handle(r.Method, parts, r.URL.Query(), body)
+	if ferr != nil {
+		f.writeError(w, ferr)
+		return
+	}
+
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(response)
+}
+
+// handle routes the request and encodes the response while holding mu, as
+// responses may hold maps of the live state.
+func (f *FakeServer) handle(method string, parts []string, query url.Values, body object) (int, []byte, *fakeError) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status, response, ferr := f.route(method, parts, query, body)
+	if ferr != nil || status == http.StatusNoContent {
+		return status, nil, ferr
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return 0, nil, &fakeError{http.StatusInternalServerError, "Internal Server Error", err.Error(), nil}
+	}
+	return status, append(data, '\n'), nil
+}
+
+func (f *FakeServer) writeError(w http.ResponseWriter, e *fakeError) {
+	response := object{
+		"type":     errorGlossary,
+		"title":    e.title,
+		"status":   e.status,
+		"detail":   e.detail,
+		"instance": fmt.Sprintf("%08x-0000-0000-0000-000000000000", f.Now().UnixNano()&0xffffffff),
+	}
+	if len(e.errors) > 0 {
+		response["errors"] = e.errors
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(e.status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// route dispatches a request, the caller holds mu
+func (f *FakeServer) route(method string, parts []string, query url.Values, body object) (int, interface{}, *fakeError) {
+	switch {
+	case len(parts) >= 1 && parts[0] == "lists":
+		return f.routeLists(method, parts[1:], query, body)
+	case len(parts) >= 1 && parts[0] == "campaigns":
+		return f.routeCampaigns(method, parts[1:], query, body)
+	}
+	return 0, nil, notFound()
+}
+
+func methodNotAllowed() (int, interface{}, *fakeError) {
+	return 0, nil, &fakeError{http.StatusMethodNotAllowed, "Method Not Allowed", "The requested method and resource are not compatible. See the Allow header for this resource's available methods.", nil}
+}
+
+// ------------------------------------------------------------------------------
+// Lists
+// ------------------------------------------------------------------------------
+
+func (f *FakeServer) routeLists(method string, parts []string, query url.Values, body object) (int, interface{}, *fakeError) {
+	if len(parts) == 0 {
+		switch method {
+		case http.MethodGet:
+			items := []interface{}{}
+			for _, l := range f.lists {
+				items = append(items, f.listView(l))
+			}
+			return page("lists", items, query, nil)
+		case http.MethodPost:
+			return f.createList(body)
+		}
+		return methodNotAllowed()
+	}
+
+	list := f.list(parts[0])
+	if list == nil {
+		return 0, nil, notFound()
+	}
+
+	if len(parts) == 1 {
+		switch method {
+		case http.MethodGet:
+			return http.StatusOK, f.listView(list), nil
+		case http.MethodPatch:
+			for k, v := range body {
+				if k != "id" && k != "stats" && k != "date_created" {
+					list.data[k] = v
+				}
+			}
+			return http.StatusOK, f.listView(list), nil
+		case http.MethodPost:
+			return f.batchSubscribe(list, body)
+		case http.MethodDelete:
+			for i, l := range f.lists {
+				if l == list {
+					f.lists = append(f.lists[:i], f.lists[i+1:]...)
+				}
+			}
+			return http.StatusNoContent, nil, nil
+		}
+		return methodNotAllowed()
+	}
+
+	switch parts[1] {
+	case "members":
+		return f.routeMembers(list, method, parts[2:], query, body)
+	case "merge-fields":
+		return f.routeMergeFields(list, method, parts[2:], query, body)
+	case "segments":
+		return f.routeSegments(list, method, parts[2:], query, body)
+	case "webhooks":
+		return f.routeWebhooks(list, method, parts[2:], query, body)
+	}
+	return 0, nil, notFound()
+}
+
+func (f *FakeServer) createList(body object) (int, interface{}, *fakeError) {
+	fields := []string{}
+	for _, field := range []string{"name", "contact", "permission_reminder", "campaign_defaults"} {
+		if isBlank(body[field]) {
+			fields = append(fields, field, "This value should not be blank.")
+		}
+	}
+	if len(fields) > 0 {
+		return 0, nil, invalidResource(validationDetail, fields...)
+	}
+
+	data := object{
+		"id":                    f.newID(),
+		"visibility":            "pub",
+		"use_archive_bar":       false,
+		"email_type_option":     false,
+		"notify_on_subscribe":   "",
+		"notify_on_unsubscribe": "",
+		"list_rating":           0,
+	}
+	for k, v := range body {
+		data[k] = v
+	}
+	data["date_created"] = f.timestamp()
+
+	list := &fakeList{
+		data:        data,
+		members:     []object{},
+		segments:    []*fakeSegment{},
+		webhooks:    []object{},
+		nextMergeID: 3,
+		nextSegment: 1,
+	}
+	list.mergeFields = []object{
+		mergeField(list, 1, "FNAME", "First Name", "text", 2),
+		mergeField(list, 2, "LNAME", "Last Name", "text", 3),
+	}
+
+	f.lists = append(f.lists, list)
+	return http.StatusOK, f.listView(list), nil
+}
+
+func mergeField(list *fakeList, id int, tag string, name string, fieldType string, order int) object {
+	return object{
+		"merge_id":      id,
+		"tag":           tag,
+		"name":          name,
+		"type":          fieldType,
+		"required":      false,
+		"default_value": "",
+		"public":        true,
+		"display_order": order,
+		"options":       object{},
+		"help_text":     "",
+		"list_id":       list.data["id"],
+	}
+}
+
+func (f *FakeServer) listView(list *fakeList) object {
+	counts := map[string]int{}
+	for _, m := range list.members {
+		counts[m["status"].(string)]++
+	}
+
+	view := object{}
+	for k, v := range list.data {
+		view[k] = v
+	}
+	view["stats"] = object{
+		"member_count":                 counts["subscribed"],
+		"unsubscribe_count":            counts["unsubscribed"],
+		"cleaned_count":                counts["cleaned"],
+		"merge_field_count":            len(list.mergeFields),
+		"campaign_count":               f.campaignCount(list.data["id"].(string)),
+		"avg_sub_rate":                 0,
+		"avg_unsub_rate":               0,
+		"target_sub_rate":              0,
+		"open_rate":                    0,
+		"click_rate":                   0,
+		"campaign_last_sent":           "",
+		"last_sub_date":                "",
+		"last_unsub_date":              "",
+		"member_count_since_send":      0,
+		"unsubscribe_count_since_send": 0,
+		"cleaned_count_since_send":     0,
+	}
+	return view
+}
+
+func (f *FakeServer) campaignCount(listID string) int {
+	count := 0
+	for _, c := range f.campaigns {
+		if recipientsListID(c.data) == listID {
+			count++
+		}
+	}
+	return count
+}
+
+// batchSubscribe implements POST /lists/{id}
+func (f *FakeServer) batchSubscribe(list *fakeList, body object) (int, interface{}, *fakeError) {
+	members, _ := body["members"].([]interface{})
+	if len(members) > 500 {
+		return 0, nil, invalidResource("You may only batch subscribe up to 500 members at a time.")
+	}
+	update, _ := body["update_existing"].(bool)
+
+	created := []interface{}{}
+	updated := []interface{}{}
+	errors := []interface{}{}
+
+	for _, m := range members {
+		data, _ := m.(map[string]interface{})
+		email, _ := data["email_address"].(string)
+
+		existing := list.member(subscriberHash(email))
+		if existing != nil && existing["status"] != "archived" {
+			if !update {
+				errors = append(errors, object{
+					"email_address": email,
+					"error":         email + " is already a list member, do you want to update? please provide update_existing:true in the request body",
+					"error_code":    "ERROR_CONTACT_EXISTS",
+				})
+				continue
+			}
+			if ferr := f.updateMember(list, existing, data); ferr != nil {
+				errors = append(errors, object{"email_address": email, "error": ferr.detail, "error_code": "ERROR_GENERIC"})
+				continue
+			}
+			updated = append(updated, f.memberView(list, existing))
+			continue
+		}
+
+		member, ferr := f.addMember(list, data)
+		if ferr != nil {
+			errors = append(errors, object{"email_address": email, "error": ferr.detail, "error_code": "ERROR_GENERIC"})
+			continue
+		}
+		created = append(created, f.memberView(list, member))
+	}
+
+	return http.StatusOK, object{
+		"new_members":     created,
+		"updated_members": updated,
+		"errors":          errors,
+		"total_created":   len(created),
+		"total_updated":   len(updated),
+		"error_count":     len(errors),
+	}, nil
+}
+
+// ------------------------------------------------------------------------------
+// Members
+// ------------------------------------------------------------------------------
+
+func (f *FakeServer) routeMembers(list *fakeList, method string, parts []string, query url.Values, body object) (int, interface{}, *fakeError) {
+	if len(parts) == 0 {
+		switch method {
+		case http.MethodGet:
+			status := query.Get("status")
+			items := []interface{}{}
+			for _, m := range list.members {
+				if status == "" || m["status"] == status {
+					items = append(items, f.memberView(list, m))
+				}
+			}
+			return page("members", items, query, object{"list_id": list.data["id"]})
+
+		case http.MethodPost:
+			email, _ := body["email_address"].(string)
+			if existing := list.member(subscriberHash(email)); existing != nil && existing["status"] != "archived" {
+				return 0, nil, &fakeError{http.StatusBadRequest, "Member Exists", email + " is already a list member. Use PUT to insert or update list members.", nil}
+			}
+			member, ferr := f.addMember(list, body)
+			if ferr != nil {
+				return 0, nil, ferr
+			}
+			return http.StatusOK, f.memberView(list, member), nil
+		}
+		return methodNotAllowed()
+	}
+
+	hash := strings.ToLower(parts[0])
+	member := list.member(hash)
+
+	if len(parts) == 3 && parts[1] == "actions" && parts[2] == "delete-permanent" && method == http.MethodPost {
+		if member == nil {
+			return 0, nil, notFound()
+		}
+		list.removeMember(hash)
+		return http.StatusNoContent, nil, nil
+	}
+
+	if len(parts) != 1 {
+		return 0, nil, notFound()
+	}
+
+	switch method {
+	case http.MethodGet:
+		if member == nil {
+			return 0, nil, notFound()
+		}
+		return http.StatusOK, f.memberView(list, member), nil
+
+	case http.MethodPatch, http.MethodPut:
+		if email, ok := body["email_address"].(string); ok && email != "" && subscriberHash(email) != hash {
+			return 0, nil, invalidResource(validationDetail, "email_address", "The subscriber hash does not match the email address.")
+		}
+
+		if member == nil {
+			if method == http.MethodPatch {
+				return 0, nil, notFound()
+			}
+			data := object{}
+			for k, v := range body {
+				data[k] = v
+			}
+			if isBlank(data["status"]) {
+				data["status"] = data["status_if_new"]
+			}
+			created, ferr := f.addMember(list, data)
+			if ferr != nil {
+				return 0, nil, ferr
+			}
+			return http.StatusOK, f.memberView(list, created), nil
+		}
+
+		if ferr := f.updateMember(list, member, body); ferr != nil {
+			return 0, nil, ferr
+		}
+		return http.StatusOK, f.memberView(list, member), nil
+
+	case http.MethodDelete:
+		if member == nil || member["status"] == "archived" {
+			return 0, nil, notFound()
+		}
+		member["status"] = "archived"
+		member["last_changed"] = f.timestamp()
+		return http.StatusNoContent, nil, nil
+	}
+	return methodNotAllowed()
+}
+
+var fakeEmailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+var memberStatuses = map[string]bool{"subscribed": true, "unsubscribed": true, "cleaned": true, "pending": true}
+
+// addMember validates data and adds a new member, replacing a archived one
+func (f *FakeServer) addMember(list *fakeList, data object) (object, *fakeError) {
+	email, _ := data["email_address"].(string)
+	status, _ := data["status"].(string)
+
+	fields := []string{}
+	if email == "" {
+		fields = append(fields, "email_address", "This value should not be blank.")
+	}
+	if !memberStatuses[status] {
+		fields = append(fields, "status", "Invalid status value: "+status)
+	}
+	if len(fields) > 0 {
+		return nil, invalidResource(validationDetail, fields...)
+	}
+
+	if !fakeEmailPattern.MatchString(email) {
+		return nil, invalidResource(email + " looks fake or invalid, please enter a real email address.")
+	}
+
+	merges, _ := data["merge_fields"].(map[string]interface{})
+	if ferr := list.validateMerges(merges, true); ferr != nil {
+		return nil, ferr
+	}
+
+	hash := subscriberHash(email)
+	now := f.timestamp()
+
+	member := object{
+		"id":               hash,
+		"email_address":    email,
+		"unique_email_id":  hash[:10],
+		"email_type":       "html",
+		"status":           status,
+		"merge_fields":     object{},
+		"interests":        object{},
+		"stats":            object{"avg_open_rate": 0, "avg_click_rate": 0},
+		"ip_signup":        "",
+		"timestamp_signup": "",
+		"ip_opt":           "",
+		"timestamp_opt":    "",
+		"member_rating":    2,
+		"last_changed":     now,
+		"language":         "",
+		"vip":              false,
+		"email_client":     "",
+		"location":         object{"latitude": 0, "longitude": 0, "gmtoff": 0, "dstoff": 0, "country_code": "", "timezone": ""},
+		"list_id":          list.data["id"],
+	}
+	if status == "subscribed" {
+		member["timestamp_opt"] = now
+	}
+	for _, k := range []string{"email_type", "interests", "language", "vip", "location"} {
+		if v, ok := data[k]; ok {
+			member[k] = toObject(v)
+		}
+	}
+	for k, v := range merges {
+		member["merge_fields"].(object)[k] = v
+	}
+
+	list.removeMember(hash)
+	list.members = append(list.members, member)
+	return member, nil
+}
+
+// updateMember validates data and applies it to member
+func (f *FakeServer) updateMember(list *fakeList, member object, data object) *fakeError {
+	if status, ok := data["status"].(string); ok && status != "" {
+		if !memberStatuses[status] {
+			return invalidResource(validationDetail, "status", "Invalid status value: "+status)
+		}
+		if status == "subscribed" && member["status"] != "subscribed" {
+			member["timestamp_opt"] = f.timestamp()
+		}
+		member["status"] = status
+	}
+
+	merges, _ := data["merge_fields"].(map[string]interface{})
+	if ferr := list.validateMerges(merges, false); ferr != nil {
+		return ferr
+	}
+	for k, v := range merges {
+		member["merge_fields"].(object)[k] = v
+	}
+
+	for _, k := range []string{"email_type", "language", "vip", "location"} {
+		if v, ok := data[k]; ok {
+			member[k] = toObject(v)
+		}
+	}
+	if interests, ok := data["interests"].(map[string]interface{}); ok {
+		existing, ok := member["interests"].(object)
+		if !ok {
+			existing = object{}
+			member["interests"] = existing
+		}
+		for k, v := range interests {
+			existing[k] = v
+		}
+	}
+
+	member["last_changed"] = f.timestamp()
+	return nil
+}
+
+// validateMerges checks required fields, only when creating a member
+func (l *fakeList) validateMerges(merges map[string]interface{}, create bool) *fakeError {
+	fields := []string{}
+	for _, field := range l.mergeFields {
+		tag := field["tag"].(string)
+		if create && field["required"] == true && isBlank(merges[tag]) {
+			fields = append(fields, tag, "Please enter a value")
+		}
+	}
+	if len(fields) > 0 {
+		return invalidResource("Your merge fields were invalid.", fields...)
+	}
+	return nil
+}
+
+// memberView adds every merge field of the list to the member
+func (f *FakeServer) memberView(list *fakeList, member object) object {
+	view := object{}
+	for k, v := range member {
+		view[k] = v
+	}
+
+	merges := object{}
+	for _, field := range list.mergeFields {
+		merges[field["tag"].(string)] = ""
+	}
+	for k, v := range member["merge_fields"].(object) {
+		merges[k] = v
+	}
+	view["merge_fields"] = merges
+	return view
+}
+
+func (l *fakeList) member(hash string) object {
+	for _, m := range l.members {
+		if m["id"] == hash {
+			return m
+		}
+	}
+	return nil
+}
+
+func (l *fakeList) removeMember(hash string) {
+	for i, m := range l.members {
+		if m["id"] == hash {
+			l.members = append(l.members[:i], l.members[i+1:]...)
+			return
+		}
+	}
+}
+
+// ------------------------------------------------------------------------------
+// Merge fields
+// ------------------------------------------------------------------------------
+
+var mergeFieldTypes = map[string]bool{
+	"text": true, "number": true, "address": true, "phone": true, "date": true, "url": true,
+	"imageurl": true, "radio": true, "dropdown": true, "birthday": true, "zip": true, "email": true, "checkboxes": true,
+}
+
+func (f *FakeServer) routeMergeFields(list *fakeList, method string, parts []string, query url.Values, body object) (int, interface{}, *fakeError) {
+	if len(parts) == 0 {
+		switch method {
+		case http.MethodGet:
+			items := []interface{}{}
+			for _, field := range list.mergeFields {
+				items = append(items, field)
+			}
+			return page("merge_fields", items, query, object{"list_id": list.data["id"]})
+
+		case http.MethodPost:
+			name, _ := body["name"].(string)
+			fieldType, _ := body["type"].(string)
+
+			fields := []string{}
+			if name == "" {
+				fields = append(fields, "name", "This value should not be blank.")
+			}
+			if !mergeFieldTypes[fieldType] {
+				fields = append(fields, "type", "Invalid type value: "+fieldType)
+			}
+			if len(fields) > 0 {
+				return 0, nil, invalidResource(validationDetail, fields...)
+			}
+
+			id := list.nextMergeID
+			tag, _ := body["tag"].(string)
+			if tag == "" {
+				tag = fmt.Sprintf("MMERGE%d", id)
+			}
+			tag = strings.ToUpper(tag)
+			if list.mergeFieldByTag(tag) != nil {
+				return 0, nil, invalidResource(fmt.Sprintf("A Merge Field with the tag \"%s\" already exists for this list.", tag))
+			}
+
+			field := mergeField(list, id, tag, name, fieldType, len(list.mergeFields)+2)
+			for _, k := range []string{"required", "default_value", "public", "display_order", "options", "help_text"} {
+				if v, ok := body[k]; ok {
+					field[k] = v
+				}
+			}
+
+			list.nextMergeID++
+			list.mergeFields = append(list.mergeFields, field)
+			return http.StatusOK, field, nil
+		}
+		return methodNotAllowed()
+	}
+
+	if len(parts) != 1 {
+		return 0, nil, notFound()
+	}
+
+	var field object
+	index := -1
+	for i, mf := range list.mergeFields {
+		if fmt.Sprint(mf["merge_id"]) == parts[0] {
+			field, index = mf, i
+		}
+	}
+	if field == nil {
+		return 0, nil, notFound()
+	}
+
+	switch method {
+	case http.MethodGet:
+		return http.StatusOK, field, nil
+	case http.MethodPatch, http.MethodPut:
+		for k, v := range body {
+			if k != "merge_id" && k != "type" && k != "list_id" {
+				field[k] = v
+			}
+		}
+		return http.StatusOK, field, nil
+	case http.MethodDelete:
+		list.mergeFields = append(list.mergeFields[:index], list.mergeFields[index+1:]...)
+		return http.StatusNoContent, nil, nil
+	}
+	return methodNotAllowed()
+}
+
+func (l *fakeList) mergeFieldByTag(tag string) object {
+	for _, field := range l.mergeFields {
+		if field["tag"] == tag {
+			return field
+		}
+	}
+	return nil
+}
+
+// ------------------------------------------------------------------------------
+// Segments
+// ------------------------------------------------------------------------------
+
+func (f *FakeServer) routeSegments(list *fakeList, method string, parts []string, query url.Values, body object) (int, interface{}, *fakeError) {
+	if len(parts) == 0 {
+		switch method {
+		case http.MethodGet:
+			items := []interface{}{}
+			for _, s := range list.segments {
+				items = append(items, s.view())
+			}
+			return page("segments", items, query, object{"list_id": list.data["id"]})
+
+		case http.MethodPost:
+			if isBlank(body["name"]) {
+				return 0, nil, invalidResource(validationDetail, "name", "This value should not be blank.")
+			}
+
+			segment := &fakeSegment{
+				data: object{
+					"id":         list.nextSegment,
+					"created_at": f.timestamp(),
+					"list_id":    list.data["id"],
+				},
+			}
+			if ferr := f.updateSegment(list, segment, body, true); ferr != nil {
+				return 0, nil, ferr
+			}
+
+			list.nextSegment++
+			list.segments = append(list.segments, segment)
+			return http.StatusOK, segment.view(), nil
+		}
+		return methodNotAllowed()
+	}
+
+	var segment *fakeSegment
+	index := -1
+	for i, s := range list.segments {
+		if fmt.Sprint(s.data["id"]) == parts[0] {
+			segment, index = s, i
+		}
+	}
+	if segment == nil {
+		return 0, nil, notFound()
+	}
+
+	if len(parts) == 2 && parts[1] == "members" && method == http.MethodGet {
+		items := []interface{}{}
+		for _, hash := range segment.members {
+			if m := list.member(hash); m != nil {
+				items = append(items, f.memberView(list, m))
+			}
+		}
+		return page("members", items, query, nil)
+	}
+
+	if len(parts) != 1 {
+		return 0, nil, notFound()
+	}
+
+	switch method {
+	case http.MethodGet:
+		return http.StatusOK, segment.view(), nil
+	case http.MethodPatch:
+		if ferr := f.updateSegment(list, segment, body, false); ferr != nil {
+			return 0, nil, ferr
+		}
+		return http.StatusOK, segment.view(), nil
+	case http.MethodDelete:
+		list.segments = append(list.segments[:index], list.segments[index+1:]...)
+		return http.StatusNoContent, nil, nil
+	}
+	return methodNotAllowed()
+}
+
+// updateSegment applies name, static_segment and options to segment
+func (f *FakeServer) updateSegment(list *fakeList, segment *fakeSegment, body object, create bool) *fakeError {
+	emails, static := body["static_segment"].([]interface{})
+	options, saved := body["options"].(map[string]interface{})
+
+	if static && saved {
+		return invalidResource("Static segments can not have conditions.")
+	}
+	if create && !static && !saved {
+		return invalidResource(validationDetail, "static_segment", "Either static_segment or options must be provided.")
+	}
+
+	if name, ok := body["name"].(string); ok && name != "" {
+		segment.data["name"] = name
+	}
+
+	if static {
+		segment.data["type"] = "static"
+		segment.data["options"] = object{}
+		segment.members = []string{}
+		for _, e := range emails {
+			email, _ := e.(string)
+			hash := subscriberHash(email)
+			if m := list.member(hash); m != nil && m["status"] != "archived" {
+				segment.members = append(segment.members, hash)
+			}
+		}
+	}
+	if saved {
+		segment.data["type"] = "saved"
+		segment.data["options"] = options
+		segment.members = []string{}
+	}
+
+	segment.data["updated_at"] = f.timestamp()
+	return nil
+}
+
+func (s *fakeSegment) view() object {
+	view := object{}
+	for k, v := range s.data {
+		view[k] = v
+	}
+	view["member_count"] = len(s.members)
+	return view
+}
+
+// ------------------------------------------------------------------------------
+// Webhooks
+// ------------------------------------------------------------------------------
+
+func (f *FakeServer) routeWebhooks(list *fakeList, method string, parts []string, query url.Values, body object) (int, interface{}, *fakeError) {
+	if len(parts) == 0 {
+		switch method {
+		case http.MethodGet:
+			items := []interface{}{}
+			for _, w := range list.webhooks {
+				items = append(items, w)
+			}
+			return page("webhooks", items, query, object{"list_id": list.data["id"]})
+
+		case http.MethodPost:
+			webhookURL, _ := body["url"].(string)
+			if u, err := url.Parse(webhookURL); err != nil || u.Scheme == "" || u.Host == "" {
+				return 0, nil, invalidResource(validationDetail, "url", "This value should be a valid URL.")
+			}
+			for _, w := range list.webhooks {
+				if w["url"] == webhookURL {
+					return 0, nil, invalidResource("Sorry, you can't use the same webhook URL twice.")
+				}
+			}
+
+			webhook := object{
+				"id":  f.newID(),
+				"url": webhookURL,
+				"events": object{
+					"subscribe": true, "unsubscribe": true, "profile": true,
+					"cleaned": true, "upemail": true, "campaign": true,
+				},
+				"sources": object{"user": true, "admin": true, "api": false},
+				"list_id": list.data["id"],
+			}
+			mergeFlags(webhook, body)
+
+			list.webhooks = append(list.webhooks, webhook)
+			return http.StatusOK, webhook, nil
+		}
+		return methodNotAllowed()
+	}
+
+	if len(parts) != 1 {
+		return 0, nil, notFound()
+	}
+
+	var webhook object
+	index := -1
+	for i, w := range list.webhooks {
+		if w["id"] == parts[0] {
+			webhook, index = w, i
+		}
+	}
+	if webhook == nil {
+		return 0, nil, notFound()
+	}
+
+	switch method {
+	case http.MethodGet:
+		return http.StatusOK, webhook, nil
+	case http.MethodPatch:
+		if webhookURL, ok := body["url"].(string); ok && webhookURL != "" {
+			webhook["url"] = webhookURL
+		}
+		mergeFlags(webhook, body)
+		return http.StatusOK, webhook, nil
+	case http.MethodDelete:
+		list.webhooks = append(list.webhooks[:index], list.webhooks[index+1:]...)
+		return http.StatusNoContent, nil, nil
+	}
+	return methodNotAllowed()
+}
+
+// mergeFlags copies the events and sources set in body to webhook
+func mergeFlags(webhook object, body object) {
+	for _, key := range []string{"events", "sources"} {
+		flags, _ := body[key].(map[string]interface{})
+		for k, v := range flags {
+			webhook[key].(object)[k] = v
+		}
+	}
+}
+
+// ------------------------------------------------------------------------------
+// Campaigns
+// ------------------------------------------------------------------------------
+
+var campaignTypes = map[string]bool{"regular": true, "plaintext": true, "absplit": true, "rss": true, "variate": true}
+
+func (f *FakeServer) routeCampaigns(method string, parts []string, query url.Values, body object) (int, interface{}, *fakeError) {
+	if len(parts) == 0 {
+		switch method {
+		case http.MethodGet:
+			status := query.Get("status")
+			items := []interface{}{}
+			for _, c := range f.campaigns {
+				if status == "" || c.data["status"] == status {
+					items = append(items, c.data)
+				}
+			}
+			return page("campaigns", items, query, nil)
+
+		case http.MethodPost:
+			campaignType, _ := body["type"].(string)
+			if !campaignTypes[campaignType] {
+				return 0, nil, invalidResource(validationDetail, "type", "Invalid type value: "+campaignType)
+			}
+
+			id := f.newID()
+			data := object{
+				"id":               id,
+				"web_id":           f.nextID,
+				"type":             campaignType,
+				"create_time":      f.timestamp(),
+				"archive_url":      "http://eepurl.com/" + id,
+				"long_archive_url": "https://us13.campaign-archive.com/?u=0&id=" + id,
+				"status":           "save",
+				"emails_sent":      0,
+				"send_time":        "",
+				"content_type":     "template",
+				"recipients":       object{},
+				"settings":         object{},
+				"tracking":         object{"opens": true, "html_clicks": true, "text_clicks": false},
+				"delivery_status":  object{"enabled": false},
+			}
+			if ferr := f.updateCampaign(data, body); ferr != nil {
+				return 0, nil, ferr
+			}
+
+			f.campaigns = append(f.campaigns, &fakeCampaign{
+				data:    data,
+				content: object{"plain_text": "", "html": ""},
+			})
+			return http.StatusOK, data, nil
+		}
+		return methodNotAllowed()
+	}
+
+	campaign := f.campaign(parts[0])
+	if campaign == nil {
+		return 0, nil, notFound()
+	}
+
+	if len(parts) == 1 {
+		switch method {
+		case http.MethodGet:
+			return http.StatusOK, campaign.data, nil
+		case http.MethodPatch:
+			if campaign.data["status"] == "sent" || campaign.data["status"] == "sending" {
+				return 0, nil, badRequest("Cannot update a campaign that has already been sent.")
+			}
+			if ferr := f.updateCampaign(campaign.data, body); ferr != nil {
+				return 0, nil, ferr
+			}
+			return http.StatusOK, campaign.data, nil
+		case http.MethodDelete:
+			for i, c := range f.campaigns {
+				if c == campaign {
+					f.campaigns = append(f.campaigns[:i], f.campaigns[i+1:]...)
+				}
+			}
+			return http.StatusNoContent, nil, nil
+		}
+		return methodNotAllowed()
+	}
+
+	if len(parts) == 2 && parts[1] == "content" {
+		switch method {
+		case http.MethodGet:
+			return http.StatusOK, campaign.content, nil
+		case http.MethodPut:
+			if html, ok := body["html"].(string); ok {
+				campaign.content["html"] = html
+				campaign.content["plain_text"] = plainText(html)
+				campaign.data["content_type"] = "html"
+			}
+			if text, ok := body["plain_text"].(string); ok && text != "" {
+				campaign.content["plain_text"] = text
+			}
+			return http.StatusOK, campaign.content, nil
+		}
+		return methodNotAllowed()
+	}
+
+	if len(parts) == 3 && parts[1] == "actions" {
+		if method != http.MethodPost {
+			return methodNotAllowed()
+		}
+		return f.campaignAction(campaign, parts[2], body)
+	}
+
+	return 0, nil, notFound()
+}
+
+// updateCampaign merges recipients, settings and tracking from body into data
+func (f *FakeServer) updateCampaign(data object, body object) *fakeError {
+	if recipients, ok := body["recipients"].(map[string]interface{}); ok {
+		if listID, ok := recipients["list_id"].(string); ok && listID != "" && f.list(listID) == nil {
+			return invalidResource(validationDetail, "recipients.list_id", "Invalid list ID")
+		}
+	}
+
+	for _, key := range []string{"recipients", "settings", "tracking", "rss_opts", "variate_settings", "social_card"} {
+		values, ok := body[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		current, ok := data[key].(object)
+		if !ok {
+			current = object{}
+			data[key] = current
+		}
+		for k, v := range values {
+			current[k] = toObject(v)
+		}
+	}
+
+	if list := f.list(recipientsListID(data)); list != nil {
+		recipients := data["recipients"].(object)
+		recipients["list_name"] = list.data["name"]
+		recipients["recipient_count"] = len(f.recipients(data))
+	}
+	return nil
+}
+
+// campaignTransitions are the statuses the api accepts each campaign action
+// from. A scheduled campaign can also be sent right away, or canceled.
+var campaignTransitions = map[string][]string{
+	"send":        {"save", "schedule"},
+	"schedule":    {"save", "paused"},
+	"unschedule":  {"schedule"},
+	"cancel-send": {"sending", "schedule"},
+	"pause":       {"sending"},
+	"resume":      {"paused"},
+}
+
+// campaignAction implements POST /campaigns/{id}/actions/{action}
+func (f *FakeServer) campaignAction(campaign *fakeCampaign, action string, body object) (int, interface{}, *fakeError) {
+	status, _ := campaign.data["status"].(string)
+	invalidState := func() (int, interface{}, *fakeError) {
+		return 0, nil, badRequest(fmt.Sprintf("This campaign cannot be %s because it has a status of %s.", action, status))
+	}
+
+	if from, ok := campaignTransitions[action]; ok && !contains(from, status) {
+		return invalidState()
+	}
+
+	switch action {
+	case "schedule":
+		if ferr := f.readyToSend(campaign); ferr != nil {
+			return 0, nil, ferr
+		}
+
+		str, _ := body["schedule_time"].(string)
+		scheduled, err := time.Parse(time.RFC3339, str)
+		if err != nil {
+			return 0, nil, invalidResource(validationDetail, "schedule_time", "This value is not a valid datetime.")
+		}
+		if scheduled.Minute()%15 != 0 || scheduled.Second() != 0 {
+			return 0, nil, invalidResource("Schedule time must be on the quarter-hour (:00, :15, :30, :45).")
+		}
+		if !scheduled.After(f.Now()) {
+			return 0, nil, invalidResource("Schedule time must be in the future.")
+		}
+		if timewarp, _ := body["timewarp"].(bool); timewarp && body["batch_delivery"] != nil {
+			return 0, nil, invalidResource("Timewarp cannot be used with Batch Delivery.")
+		}
+
+		campaign.data["status"] = "schedule"
+		campaign.data["send_time"] = scheduled.UTC().Format(time.RFC3339)
+
+	case "unschedule":
+		campaign.data["status"] = "save"
+		campaign.data["send_time"] = ""
+
+	case "send":
+		if ferr := f.readyToSend(campaign); ferr != nil {
+			return 0, nil, ferr
+		}
+		campaign.data["status"] = "sent"
+		campaign.data["send_time"] = f.timestamp()
+		campaign.data["emails_sent"] = len(f.recipients(campaign.data))
+
+	case "cancel-send":
+		campaign.data["status"] = "canceled"
+
+	case "pause":
+		if campaign.data["type"] != "rss" {
+			return invalidState()
+		}
+		campaign.data["status"] = "paused"
+
+	case "resume":
+		if campaign.data["type"] != "rss" {
+			return invalidState()
+		}
+		campaign.data["status"] = "sending"
+
+	case "test":
+		emails, _ := body["test_emails"].([]interface{})
+		sendType, _ := body["send_type"].(string)
+		if len(emails) == 0 || (sendType != "html" && sendType != "plaintext") {
+			return 0, nil, invalidResource(validationDetail, "test_emails", "This value should not be blank.", "send_type", "Invalid send_type value: "+sendType)
+		}
+
+	default:
+		return 0, nil, notFound()
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// readyToSend checks the campaign has everything needed to send
+func (f *FakeServer) readyToSend(campaign *fakeCampaign) *fakeError {
+	settings, _ := campaign.data["settings"].(object)
+	missing := f.list(recipientsListID(campaign.data)) == nil
+	for _, key := range []string{"subject_line", "from_name", "reply_to"} {
+		missing = missing || isBlank(settings[key])
+	}
+	if missing || campaign.content["html"] == "" && campaign.content["plain_text"] == "" {
+		return badRequest("Your Campaign is not ready to send.")
+	}
+	return nil
+}
+
+// recipients returns the subscribed members a campaign is sent to
+func (f *FakeServer) recipients(data object) []object {
+	list := f.list(recipientsListID(data))
+	if list == nil {
+		return nil
+	}
+
+	var segment *fakeSegment
+	recipients, _ := data["recipients"].(object)
+	if opts, ok := recipients["segment_opts"].(map[string]interface{}); ok {
+		id := fmt.Sprint(opts["saved_segment_id"])
+		for _, s := range list.segments {
+			if fmt.Sprint(s.data["id"]) == id {
+				segment = s
+			}
+		}
+	}
+
+	members := []object{}
+	for _, m := range list.members {
+		if m["status"] != "subscribed" {
+			continue
+		}
+		if segment != nil && !contains(segment.members, m["id"].(string)) {
+			continue
+		}
+		members = append(members, m)
+	}
+	return members
+}
+
+func (f *FakeServer) campaign(id string) *fakeCampaign {
+	for _, c := range f.campaigns {
+		if c.data["id"] == id {
+			return c
+		}
+	}
+	return nil
+}
+
+func recipientsListID(data object) string {
+	recipients, _ := data["recipients"].(object)
+	id, _ := recipients["list_id"].(string)
+	return id
+}
+
+// ------------------------------------------------------------------------------
+// Helpers
+// ------------------------------------------------------------------------------
+
+func (f *FakeServer) list(id string) *fakeList {
+	for _, l := range f.lists {
+		if l.data["id"] == id {
+			return l
+		}
+	}
+	return nil
+}
+
+// newID returns a unique 10 character hex id, like the ones the api uses
+func (f *FakeServer) newID() string {
+	f.nextID++
+	return fmt.Sprintf("%010x", f.nextID)
+}
+
+func (f *FakeServer) timestamp() string {
+	return f.Now().UTC().Format(time.RFC3339)
+}
+
+// page applies count and offset to items and wraps them like the api does.
+// count defaults to 10 and can't exceed 1000.
+func page(key string, items []interface{}, query url.Values, extra object) (int, interface{}, *fakeError) {
+	count := 10
+	if c, err := strconv.Atoi(query.Get("count")); err == nil && c > 0 {
+		count = c
+	}
+	if count > 1000 {
+		count = 1000
+	}
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	total := len(items)
+	if offset > total {
+		offset = total
+	}
+	end := offset + count
+	if end > total {
+		end = total
+	}
+
+	response := object{
+		key:           items[offset:end],
+		"total_items": total,
+	}
+	for k, v := range extra {
+		response[k] = v
+	}
+	return http.StatusOK, response, nil
+}
+
+// toObject converts decoded json objects to object, so nested values can
+// be type asserted the same way as the ones created by the fake.
+func toObject(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		o := object{}
+		for k, v := range value {
+			o[k] = toObject(v)
+		}
+		return o
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for i, v := range value {
+			list[i] = toObject(v)
+		}
+		return list
+	default:
+		return v
+	}
+}
+
+func subscriberHash(email string) string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(strings.ToLower(email))))
+}
+
+func isBlank(v interface{}) bool {
+	switch value := v.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+var htmlTags = regexp.MustCompile(`<[^>]*>`)
+
+// plainText is a crude version of the text Mailchimp generates from html
+func plainText(html string) string {
+	lines := []string{}
+	for _, line := range strings.Split(htmlTags.ReplaceAllString(html, "\n"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,479 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testing_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	check "gopkg.in/check.v1"
+
+	"github.com/greatbeyond/mailchimp"
+	t "github.com/greatbeyond/mailchimp/testing"
+)
+
+var _ = check.Suite(&FakeSuite{})
+
+type FakeSuite struct {
+	client *mailchimp.Client
+	server *t.FakeServer
+	ctx    context.Context
+}
+
+func (s *FakeSuite) SetUpSuite(c *check.C) {}
+
+func (s *FakeSuite) SetUpTest(c *check.C) {
+	s.server = t.NewFakeServer()
+	s.server.Now = func() time.Time {
+		return time.Date(2017, 2, 4, 12, 0, 0, 0, time.UTC)
+	}
+
+	s.client = mailchimp.NewClient()
+	s.client.HTTPClient = s.server.HTTPClient
+
+	s.ctx = mailchimp.NewContextWithToken(context.Background(), os.Getenv("MAILCHIMP_TEST_TOKEN"))
+	s.ctx = mailchimp.NewContextWithURL(s.ctx, "http://us13.api.mailchimp.com/3.0/")
+}
+
+func (s *FakeSuite) TearDownTest(c *check.C) {
+	s.server.Close()
+}
+
+func (s *FakeSuite) createList(c *check.C) *mailchimp.List {
+	list, err := s.client.CreateList(s.ctx, &mailchimp.CreateList{
+		Name:               "Test list",
+		Contact:            &mailchimp.Contact{Company: "Great Beyond", Country: "SE"},
+		PermissionReminder: "You signed up",
+		CampaignDefaults:   &mailchimp.CampaignDefaults{FromName: "Test", FromEmail: "test@example.com"},
+	})
+	c.Assert(err, check.IsNil)
+	return list
+}
+
+func (s *FakeSuite) Test_Lists(c *check.C) {
+	list := s.createList(c)
+	c.Assert(list.ID, check.Not(check.Equals), "")
+	c.Assert(list.DateCreated.Equal(s.server.Now()), check.Equals, true)
+
+	got, err := s.client.GetList(s.ctx, list.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(got.Name, check.Equals, "Test list")
+	c.Assert(got.Stats.MergeFieldCount, check.Equals, 2)
+
+	updated, err := got.Update(s.ctx, &mailchimp.UpdateList{Name: "Renamed"})
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Name, check.Equals, "Renamed")
+	c.Assert(updated.PermissionReminder, check.Equals, "You signed up")
+
+	lists, err := s.client.GetLists(s.ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(lists, check.HasLen, 1)
+
+	c.Assert(list.Delete(s.ctx), check.IsNil)
+	_, err = s.client.GetList(s.ctx, list.ID)
+	c.Assert(mailchimp.IsNotFound(err), check.Equals, true)
+}
+
+func (s *FakeSuite) Test_CreateList_Invalid(c *check.C) {
+	_, err := s.client.NewList().Client.Post(s.ctx, mailchimp.ListsURL, nil, map[string]string{"name": "x"})
+	c.Assert(err, check.NotNil)
+
+	apiErr, ok := err.(mailchimp.Error)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(apiErr.Status, check.Equals, 400)
+	c.Assert(apiErr.Title, check.Equals, "Invalid Resource")
+	c.Assert(apiErr.Errors, check.HasLen, 3)
+}
+
+func (s *FakeSuite) Test_Members(c *check.C) {
+	list := s.createList(c)
+
+	member, err := s.client.CreateMember(s.ctx, &mailchimp.CreateMember{
+		EmailAddress: "Ann@example.com",
+		Status:       mailchimp.Subscribed,
+		MergeFields:  map[string]interface{}{"FNAME": "Ann"},
+	}, list.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(member.ID, check.Equals, "257c57037d384ae37ea27a07e8a01665")
+	c.Assert(member.MergeFields, check.DeepEquals, map[string]interface{}{"FNAME": "Ann", "LNAME": ""})
+
+	_, err = s.client.CreateMember(s.ctx, &mailchimp.CreateMember{
+		EmailAddress: "ann@example.com",
+		Status:       mailchimp.Subscribed,
+	}, list.ID)
+	c.Assert(mailchimp.IsMemberExists(err), check.Equals, true)
+
+	_, err = s.client.CreateMember(s.ctx, &mailchimp.CreateMember{
+		EmailAddress: "not an email",
+		Status:       mailchimp.Subscribed,
+	}, list.ID)
+	c.Assert(mailchimp.IsCompliance(err), check.Equals, true)
+
+	updated, err := member.Update(s.ctx, &mailchimp.UpdateMember{Status: mailchimp.Unsubscribed})
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Status, check.Equals, mailchimp.Unsubscribed)
+
+	got, err := s.client.GetList(s.ctx, list.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(got.Stats.MemberCount, check.Equals, 0)
+	c.Assert(got.Stats.UnsubscribeCount, check.Equals, 1)
+
+	c.Assert(member.Delete(s.ctx), check.IsNil)
+	archived, err := s.client.GetMember(s.ctx, member.ID, list.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(archived.Status, check.Equals, mailchimp.MemberStatus("archived"))
+
+	_, err = s.client.GetMember(s.ctx, "0000", list.ID)
+	c.Assert(mailchimp.IsNotFound(err), check.Equals, true)
+}
+
+func (s *FakeSuite) Test_Members_Nested(c *check.C) {
+	list := s.createList(c)
+
+	member, err := s.client.CreateMember(s.ctx, &mailchimp.CreateMember{
+		EmailAddress: "ann@example.com",
+		Status:       mailchimp.Subscribed,
+		Interests:    map[string]bool{"a1": true},
+	}, list.ID)
+	c.Assert(err, check.IsNil)
+
+	updated, err := member.Update(s.ctx, &mailchimp.UpdateMember{Interests: map[string]bool{"b2": true}})
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Interests, check.DeepEquals, map[string]bool{"a1": true, "b2": true})
+
+	// The fake still answers after the update
+	_, err = s.client.GetMember(s.ctx, member.ID, list.ID)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *FakeSuite) Test_Concurrent(c *check.C) {
+	list := s.createList(c)
+	member, err := s.client.CreateMember(s.ctx, &mailchimp.CreateMember{
+		EmailAddress: "ann@example.com",
+		Status:       mailchimp.Subscribed,
+	}, list.ID)
+	c.Assert(err, check.IsNil)
+
+	// Requests go straight to the fake so that only its locking is exercised.
+	url := s.server.BaseURL + "/3.0/lists/" + list.ID + "/members/" + member.ID
+	send := func(method, body string) error {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth("OAuthToken", "token")
+		resp, err := s.server.HTTPClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if _, err := ioutil.ReadAll(resp.Body); err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return errors.New(resp.Status)
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			errs <- send("GET", "")
+		}()
+		go func() {
+			defer wg.Done()
+			errs <- send("PATCH", `{"interests":{"a1":true}}`)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func (s *FakeSuite) Test_Members_RequiredMergeField(c *check.C) {
+	list := s.createList(c)
+
+	_, err := s.client.CreateMergeField(s.ctx, &mailchimp.CreateMergeField{
+		Name:     "Age",
+		Type:     mailchimp.MergeFieldTypeNumber,
+		Required: true,
+	}, list.ID)
+	c.Assert(err, check.IsNil)
+
+	fields, err := s.client.GetMergeFields(s.ctx, list.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(fields, check.HasLen, 3)
+	c.Assert(fields[2].Tag, check.Equals, "MMERGE3")
+
+	_, err = s.client.CreateMember(s.ctx, &mailchimp.CreateMember{
+		EmailAddress: "ann@example.com",
+		Status:       mailchimp.Subscribed,
+	}, list.ID)
+	c.Assert(err, check.ErrorMatches, ".*Your merge fields were invalid.*")
+}
+
+func (s *FakeSuite) Test_BatchSubscribe(c *check.C) {
+	list := s.createList(c)
+
+	response, err := list.BatchSubscribe(s.ctx, &mailchimp.BatchSubscribe{
+		Members: []*mailchimp.CreateMember{
+			{EmailAddress: "ann@example.com", Status: mailchimp.Subscribed},
+			{EmailAddress: "bob@example.com", Status: mailchimp.Subscribed},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(response.TotalCreated, check.Equals, 2)
+
+	response, err = list.BatchSubscribe(s.ctx, &mailchimp.BatchSubscribe{
+		Members: []*mailchimp.CreateMember{
+			{EmailAddress: "ann@example.com", Status: mailchimp.Unsubscribed},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(response.ErrorCount, check.Equals, 1)
+	c.Assert(response.Errors[0].EmailAddress, check.Equals, "ann@example.com")
+
+	response, err = list.BatchSubscribe(s.ctx, &mailchimp.BatchSubscribe{
+		Members: []*mailchimp.CreateMember{
+			{EmailAddress: "ann@example.com", Status: mailchimp.Unsubscribed},
+		},
+		UpdateExisting: true,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(response.TotalUpdated, check.Equals, 1)
+	c.Assert(response.UpdatedMembers[0].Status, check.Equals, mailchimp.Unsubscribed)
+}
+
+func (s *FakeSuite) Test_Segments(c *check.C) {
+	list := s.createList(c)
+	for _, email := range []string{"ann@example.com", "bob@example.com"} {
+		_, err := s.client.CreateMember(s.ctx, &mailchimp.CreateMember{EmailAddress: email, Status: mailchimp.Subscribed}, list.ID)
+		c.Assert(err, check.IsNil)
+	}
+
+	_, err := s.client.CreateSegment(s.ctx, &mailchimp.CreateSegment{Name: "Empty"}, list.ID)
+	c.Assert(err, check.ErrorMatches, ".*static_segment.*")
+
+	emails := []string{"ann@example.com", "unknown@example.com"}
+	segment, err := s.client.CreateSegment(s.ctx, &mailchimp.CreateSegment{Name: "Ann", StaticSegment: &emails}, list.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(segment.Type, check.Equals, "static")
+	c.Assert(segment.MemberCount, check.Equals, 1)
+
+	members, err := segment.GetMembers(s.ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(members, check.HasLen, 1)
+	c.Assert(members[0].EmailAddress, check.Equals, "ann@example.com")
+
+	c.Assert(segment.Delete(s.ctx), check.IsNil)
+	_, err = s.client.GetSegment(s.ctx, "1", list.ID)
+	c.Assert(mailchimp.IsNotFound(err), check.Equals, true)
+}
+
+func (s *FakeSuite) Test_Webhooks(c *check.C) {
+	list := s.createList(c)
+
+	webhook, err := s.client.CreateWebhook(s.ctx, &mailchimp.CreateWebhook{
+		ListID: list.ID,
+		URL:    "https://example.com/webhook",
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(webhook.Events.Subscribe, check.Equals, true)
+	c.Assert(webhook.Sources.API, check.Equals, false)
+
+	_, err = s.client.CreateWebhook(s.ctx, &mailchimp.CreateWebhook{
+		ListID: list.ID,
+		URL:    "https://example.com/webhook",
+	})
+	c.Assert(err, check.ErrorMatches, ".*same webhook URL twice.*")
+
+	webhooks, err := s.client.GetWebhooks(s.ctx, list.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(webhooks, check.HasLen, 1)
+
+	c.Assert(webhook.DeleteWebhook(s.ctx), check.IsNil)
+	_, err = s.client.GetWebhook(s.ctx, list.ID, webhook.ID)
+	c.Assert(mailchimp.IsNotFound(err), check.Equals, true)
+}
+
+func (s *FakeSuite) Test_Campaigns(c *check.C) {
+	list := s.createList(c)
+	_, err := s.client.CreateMember(s.ctx, &mailchimp.CreateMember{EmailAddress: "ann@example.com", Status: mailchimp.Subscribed}, list.ID)
+	c.Assert(err, check.IsNil)
+
+	_, err = s.client.CreateCampaign(s.ctx, &mailchimp.CreateCampaign{
+		Type:       "regular",
+		Recipients: &mailchimp.CampaignRecipients{ListID: "missing"},
+	})
+	c.Assert(err, check.ErrorMatches, ".*Invalid Resource.*")
+
+	campaign, err := s.client.CreateCampaign(s.ctx, &mailchimp.CreateCampaign{
+		Type:       "regular",
+		Recipients: &mailchimp.CampaignRecipients{ListID: list.ID},
+		Settings: &mailchimp.CampaignCreateSettings{
+			SubjectLine: "Hello",
+			FromName:    "Test",
+			ReplyTo:     "test@example.com",
+		},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(campaign.Status, check.Equals, mailchimp.CampaignStatusSave)
+	c.Assert(campaign.Recipients.RecipientCount, check.Equals, 1)
+
+	// No content yet
+	c.Assert(campaign.Send(s.ctx), check.ErrorMatches, ".*not ready to send.*")
+
+	content, err := campaign.SetContent(s.ctx, &mailchimp.CampaignContentEdit{HTML: "<p>Hello</p>"})
+	c.Assert(err, check.IsNil)
+	c.Assert(content.PlainText, check.Equals, "Hello")
+
+	// Invalid schedules are rejected by the client, post them directly to
+	// reach the fake.
+	action := "campaigns/" + campaign.ID + "/actions/schedule"
+	schedule := &mailchimp.CampaignScheduleData{ScheduleTime: mailchimp.NewTime(time.Date(2017, 2, 4, 13, 10, 0, 0, time.UTC))}
+	c.Assert(errors.Is(campaign.Schedule(s.ctx, schedule), mailchimp.ErrInvalidSchedule), check.Equals, true)
+	_, err = s.client.Post(s.ctx, action, nil, schedule)
+	c.Assert(err, check.ErrorMatches, ".*quarter-hour.*")
+
+	schedule = &mailchimp.CampaignScheduleData{
+		ScheduleTime:  mailchimp.NewTime(time.Date(2017, 2, 4, 13, 15, 0, 0, time.UTC)),
+		Timewarp:      true,
+		BatchDelivery: &mailchimp.CampaignBatchDelivery{BatchDelay: 10, BatchCount: 2},
+	}
+	c.Assert(errors.Is(campaign.Schedule(s.ctx, schedule), mailchimp.ErrInvalidSchedule), check.Equals, true)
+	_, err = s.client.Post(s.ctx, action, nil, schedule)
+	c.Assert(err, check.ErrorMatches, ".*Timewarp cannot be used with Batch Delivery.*")
+
+	schedule, err = mailchimp.NewSchedule(time.Date(2017, 2, 4, 13, 15, 0, 0, time.UTC), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(campaign.Schedule(s.ctx, schedule), check.IsNil)
+	c.Assert(campaign.Status, check.Equals, mailchimp.CampaignStatusSchedule)
+	c.Assert(errors.Is(campaign.Schedule(s.ctx, schedule), mailchimp.ErrInvalidTransition), check.Equals, true)
+
+	// Without a known status the check is left to the server
+	unknown := s.client.NewCampaign(s.ctx, campaign.ID)
+	c.Assert(unknown.Schedule(s.ctx, schedule), check.ErrorMatches, ".*status of schedule.*")
+
+	c.Assert(campaign.Unschedule(s.ctx), check.IsNil)
+	c.Assert(campaign.Send(s.ctx), check.IsNil)
+
+	sent, err := s.client.GetCampaign(s.ctx, campaign.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(sent.Status, check.Equals, mailchimp.CampaignStatusSent)
+	c.Assert(sent.EmailsSent, check.Equals, 1)
+
+	c.Assert(campaign.Pause(s.ctx), check.NotNil)
+
+	c.Assert(s.server.SetCampaignStatus(campaign.ID, "sending"), check.Equals, true)
+	c.Assert(campaign.Cancel(s.ctx), check.IsNil)
+
+	c.Assert(campaign.Delete(s.ctx), check.IsNil)
+	_, err = s.client.GetCampaign(s.ctx, campaign.ID)
+	c.Assert(mailchimp.IsNotFound(err), check.Equals, true)
+}
+
+func (s *FakeSuite) Test_CampaignTransitions(c *check.C) {
+	list := s.createList(c)
+	campaign, err := s.client.CreateCampaign(s.ctx, &mailchimp.CreateCampaign{
+		Type:       "rss",
+		Recipients: &mailchimp.CampaignRecipients{ListID: list.ID},
+		Settings: &mailchimp.CampaignCreateSettings{
+			SubjectLine: "Hello",
+			FromName:    "Test",
+			ReplyTo:     "test@example.com",
+		},
+	})
+	c.Assert(err, check.IsNil)
+	_, err = campaign.SetContent(s.ctx, &mailchimp.CampaignContentEdit{HTML: "<p>Hello</p>"})
+	c.Assert(err, check.IsNil)
+
+	schedule, err := mailchimp.NewSchedule(s.server.Now().Add(time.Hour), nil)
+	c.Assert(err, check.IsNil)
+
+	actions := map[string]func(*mailchimp.Campaign) error{
+		mailchimp.CampaignActionSend:       func(cp *mailchimp.Campaign) error { return cp.Send(s.ctx) },
+		mailchimp.CampaignActionSchedule:   func(cp *mailchimp.Campaign) error { return cp.Schedule(s.ctx, schedule) },
+		mailchimp.CampaignActionUnschedule: func(cp *mailchimp.Campaign) error { return cp.Unschedule(s.ctx) },
+		mailchimp.CampaignActionCancel:     func(cp *mailchimp.Campaign) error { return cp.Cancel(s.ctx) },
+		mailchimp.CampaignActionPause:      func(cp *mailchimp.Campaign) error { return cp.Pause(s.ctx) },
+		mailchimp.CampaignActionResume:     func(cp *mailchimp.Campaign) error { return cp.Resume(s.ctx) },
+	}
+	statuses := []mailchimp.CampaignStatus{
+		mailchimp.CampaignStatusSave,
+		mailchimp.CampaignStatusPaused,
+		mailchimp.CampaignStatusSchedule,
+		mailchimp.CampaignStatusSending,
+		mailchimp.CampaignStatusSent,
+		mailchimp.CampaignStatusCanceling,
+		mailchimp.CampaignStatusCanceled,
+		mailchimp.CampaignStatusArchived,
+	}
+
+	// The statuses the api accepts each action from, rss campaigns can also
+	// be paused and resumed
+	accepted := map[string][]mailchimp.CampaignStatus{
+		mailchimp.CampaignActionSend:       {mailchimp.CampaignStatusSave, mailchimp.CampaignStatusSchedule},
+		mailchimp.CampaignActionSchedule:   {mailchimp.CampaignStatusSave, mailchimp.CampaignStatusPaused},
+		mailchimp.CampaignActionUnschedule: {mailchimp.CampaignStatusSchedule},
+		mailchimp.CampaignActionCancel:     {mailchimp.CampaignStatusSending, mailchimp.CampaignStatusSchedule},
+		mailchimp.CampaignActionPause:      {mailchimp.CampaignStatusSending},
+		mailchimp.CampaignActionResume:     {mailchimp.CampaignStatusPaused},
+	}
+
+	for action, act := range actions {
+		for _, status := range statuses {
+			c.Assert(s.server.SetCampaignStatus(campaign.ID, string(status)), check.Equals, true)
+
+			// Without a status the client leaves the check to the fake
+			err := act(&mailchimp.Campaign{ID: campaign.ID, Client: s.client})
+			comment := check.Commentf("%s from %s", action, status)
+
+			ok := false
+			for _, from := range accepted[action] {
+				ok = ok || from == status
+			}
+			if ok {
+				c.Assert(err, check.IsNil, comment)
+			} else {
+				c.Assert(err, check.ErrorMatches, ".*status of "+string(status)+".*", comment)
+			}
+
+			// The client may be stricter than the api, never more lenient
+			if status.CanTransition(action) {
+				c.Assert(ok, check.Equals, true, comment)
+			}
+		}
+	}
+}
+
+func (s *FakeSuite) Test_Unauthorized(c *check.C) {
+	response, err := s.server.HTTPClient.Get("http://us13.api.mailchimp.com/3.0/lists")
+	c.Assert(err, check.IsNil)
+	response.Body.Close()
+	c.Assert(response.StatusCode, check.Equals, http.StatusUnauthorized)
+}
@@ -0,0 +1,212 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testing
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// FaultKind is a failure Faults can inject
+type FaultKind string
+
+const (
+	// FaultNone passes the request through, use it in schedules
+	FaultNone FaultKind = ""
+	// FaultRateLimit responds 429 Too Many Requests with a Retry-After header
+	FaultRateLimit FaultKind = "rate_limit"
+	// FaultUnavailable responds 503 Service Unavailable
+	FaultUnavailable FaultKind = "unavailable"
+	// FaultSlow waits Delay before passing the request through
+	FaultSlow FaultKind = "slow"
+	// FaultTruncate passes the request through but cuts the body in half
+	FaultTruncate FaultKind = "truncate"
+	// FaultReset closes the connection without a response. Note that
+	// http.Transport silently retries idempotent requests on a reused
+	// connection, disable keep-alives to see the error.
+	FaultReset FaultKind = "reset"
+	// FaultTimeout doesn't respond until the client gives up, or Hang has
+	// passed and the connection is closed
+	FaultTimeout FaultKind = "timeout"
+)
+
+// Faults injects failures in front of MockServer and FakeServer, to test
+// retry and backoff logic. Scheduled faults are used first, one per
+// request, after that each request gets a random fault based on the
+// probabilities added with Add. The same seed gives the same faults.
+//
+//  server.SetFaults(testing.NewFaults(1).
+//      Schedule(testing.FaultRateLimit, testing.FaultNone, testing.FaultReset).
+//      Add(testing.FaultUnavailable, 0.1))
+type Faults struct {
+	// RetryAfter is sent with FaultRateLimit. Defaults to 1s.
+	RetryAfter time.Duration
+	// Delay of FaultSlow. Defaults to 100ms.
+	Delay time.Duration
+	// Hang is the longest FaultTimeout waits. Defaults to 1m.
+	Hang time.Duration
+
+	mu       sync.Mutex
+	rand     *rand.Rand
+	schedule []FaultKind
+	random   []randomFault
+	injected []FaultKind
+}
+
+type randomFault struct {
+	kind        FaultKind
+	probability float64
+}
+
+// NewFaults returns a Faults without any faults, seeded with seed
+func NewFaults(seed int64) *Faults {
+	return &Faults{
+		RetryAfter: time.Second,
+		Delay:      100 * time.Millisecond,
+		Hang:       time.Minute,
+		rand:       rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Add injects kind in the given share of requests, 0.1 is one in ten.
+// The probabilities of all added faults should not exceed 1.
+func (f *Faults) Add(kind FaultKind, probability float64) *Faults {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.random = append(f.random, randomFault{kind, probability})
+	return f
+}
+
+// Schedule queues faults for the next requests, one each, in order
+func (f *Faults) Schedule(kinds ...FaultKind) *Faults {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.schedule = append(f.schedule, kinds...)
+	return f
+}
+
+// Injected returns the fault picked for each request so far, FaultNone
+// for requests passed through
+func (f *Faults) Injected() []FaultKind {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]FaultKind{}, f.injected...)
+}
+
+// next picks the fault for a request
+func (f *Faults) next() FaultKind {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	kind := FaultNone
+	if len(f.schedule) > 0 {
+		kind = f.schedule[0]
+		f.schedule = f.schedule[1:]
+	} else if len(f.random) > 0 {
+		// Always draw, so the sequence only depends on the seed
+		n := f.rand.Float64()
+		for _, fault := range f.random {
+			if n < fault.probability {
+				kind = fault.kind
+				break
+			}
+			n -= fault.probability
+		}
+	}
+
+	f.injected = append(f.injected, kind)
+	return kind
+}
+
+// Handler returns next with faults injected. A nil Faults returns next.
+func (f *Faults) Handler(next http.Handler) http.Handler {
+	if f == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch f.next() {
+		case FaultRateLimit:
+			w.Header().Set("Retry-After", strconv.Itoa(int(f.RetryAfter.Seconds()+0.5)))
+			writeFault(w, http.StatusTooManyRequests, "Too Many Requests", "You have exceeded the limit of 10 simultaneous connections.")
+
+		case FaultUnavailable:
+			writeFault(w, http.StatusServiceUnavailable, "Service Unavailable", "The service is temporarily unavailable.")
+
+		case FaultSlow:
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(f.Delay):
+			}
+			next.ServeHTTP(w, r)
+
+		case FaultTruncate:
+			recorder := httptest.NewRecorder()
+			next.ServeHTTP(recorder, r)
+			for k, v := range recorder.Header() {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(recorder.Code)
+			body := recorder.Body.Bytes()
+			w.Write(body[:len(body)/2])
+
+		case FaultReset:
+			closeConn(w, true)
+
+		case FaultTimeout:
+			select {
+			case <-r.Context().Done():
+			case <-time.After(f.Hang):
+				closeConn(w, false)
+			}
+
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// closeConn closes the connection of the request without a response. With
+// reset unsent data is discarded, so closing sends a RST.
+func closeConn(w http.ResponseWriter, reset bool) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic(http.ErrAbortHandler)
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok && reset {
+		tcp.SetLinger(0)
+	}
+	conn.Close()
+}
+
+func writeFault(w http.ResponseWriter, status int, title string, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"type":"%s","title":"%s","status":%d,"detail":"%s","instance":""}`+"\n", errorGlossary, title, status, detail)
+}
@@ -0,0 +1,168 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testing
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	check "gopkg.in/check.v1"
+)
+
+var _ = check.Suite(&FaultsSuite{})
+
+type FaultsSuite struct {
+	server *MockServer
+	client *http.Client
+}
+
+func (s *FaultsSuite) SetUpSuite(c *check.C) {}
+
+func (s *FaultsSuite) SetUpTest(c *check.C) {
+	s.server = NewMockServer()
+	s.server.AddResponse(&MockResponse{Method: "GET", Code: 200, Body: `{"id":"1","name":"Test list"}`, Persistant: true})
+
+	// Without keep-alives, so the transport doesn't retry requests on a reset connection
+	transport := s.server.HTTPClient.Transport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = true
+	s.client = &http.Client{Transport: transport}
+}
+
+func (s *FaultsSuite) TearDownTest(c *check.C) {
+	s.server.Close()
+}
+
+func (s *FaultsSuite) get(client *http.Client) (*http.Response, string, error) {
+	resp, err := client.Get("http://us13.api.mailchimp.com/3.0/lists/1")
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	return resp, string(data), err
+}
+
+func (s *FaultsSuite) Test_Schedule(c *check.C) {
+	faults := NewFaults(1).Schedule(FaultRateLimit, FaultUnavailable, FaultNone, FaultTruncate, FaultReset)
+	faults.RetryAfter = 2 * time.Second
+	s.server.SetFaults(faults)
+
+	resp, body, err := s.get(s.client)
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.StatusCode, check.Equals, http.StatusTooManyRequests)
+	c.Assert(resp.Header.Get("Retry-After"), check.Equals, "2")
+	c.Assert(body, check.Matches, `\{.*"status":429.*\}\n`)
+
+	resp, _, err = s.get(s.client)
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.StatusCode, check.Equals, http.StatusServiceUnavailable)
+
+	resp, body, err = s.get(s.client)
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.StatusCode, check.Equals, http.StatusOK)
+	c.Assert(body, check.Equals, "{\"id\":\"1\",\"name\":\"Test list\"}\n")
+
+	_, body, err = s.get(s.client)
+	c.Assert(err, check.IsNil)
+	c.Assert(body, check.Equals, `{"id":"1","name`)
+
+	_, _, err = s.get(s.client)
+	c.Assert(err, check.NotNil)
+
+	// Schedule used up
+	resp, _, err = s.get(s.client)
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.StatusCode, check.Equals, http.StatusOK)
+
+	c.Assert(faults.Injected(), check.DeepEquals, []FaultKind{
+		FaultRateLimit, FaultUnavailable, FaultNone, FaultTruncate, FaultReset, FaultNone,
+	})
+	c.Assert(s.server.Responses[0].Hits, check.Equals, 3)
+}
+
+func (s *FaultsSuite) Test_Slow_Timeout(c *check.C) {
+	faults := NewFaults(1).Schedule(FaultSlow, FaultTimeout)
+	faults.Delay = 50 * time.Millisecond
+	s.server.SetFaults(faults)
+
+	start := time.Now()
+	resp, _, err := s.get(s.client)
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.StatusCode, check.Equals, http.StatusOK)
+	c.Assert(time.Since(start) >= faults.Delay, check.Equals, true)
+
+	client := *s.client
+	client.Timeout = 50 * time.Millisecond
+	_, _, err = s.get(&client)
+	c.Assert(err, check.ErrorMatches, ".*Client.Timeout exceeded.*")
+}
+
+func (s *FaultsSuite) Test_Timeout_Hang(c *check.C) {
+	faults := NewFaults(1).Schedule(FaultTimeout)
+	faults.Hang = 50 * time.Millisecond
+	s.server.SetFaults(faults)
+
+	// The connection is closed without a response once Hang has passed
+	start := time.Now()
+	_, _, err := s.get(s.client)
+	c.Assert(err, check.NotNil)
+	c.Assert(time.Since(start) >= faults.Hang, check.Equals, true)
+}
+
+func (s *FaultsSuite) Test_Random_Seeded(c *check.C) {
+	run := func(seed int64) []FaultKind {
+		faults := NewFaults(seed).Add(FaultRateLimit, 0.3).Add(FaultUnavailable, 0.2)
+		s.server.SetFaults(faults)
+		for i := 0; i < 50; i++ {
+			_, _, err := s.get(s.client)
+			c.Assert(err, check.IsNil)
+		}
+		return faults.Injected()
+	}
+
+	first := run(42)
+	c.Assert(run(42), check.DeepEquals, first)
+	c.Assert(run(7), check.Not(check.DeepEquals), first)
+
+	counts := map[FaultKind]int{}
+	for _, kind := range first {
+		counts[kind]++
+	}
+	c.Assert(counts[FaultRateLimit] > 5, check.Equals, true)
+	c.Assert(counts[FaultUnavailable] > 2, check.Equals, true)
+	c.Assert(counts[FaultNone] > 10, check.Equals, true)
+}
+
+func (s *FaultsSuite) Test_FakeServer(c *check.C) {
+	fake := NewFakeServer()
+	defer fake.Close()
+	fake.SetFaults(NewFaults(1).Schedule(FaultUnavailable))
+
+	req, _ := http.NewRequest("GET", "http://us13.api.mailchimp.com/3.0/lists", nil)
+	req = req.WithContext(context.Background())
+	req.SetBasicAuth("OAuthToken", "b12824bd84759ef84abc67fd789e7570-us13")
+
+	resp, err := fake.HTTPClient.Do(req)
+	c.Assert(err, check.IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, check.Equals, http.StatusServiceUnavailable)
+
+	resp, err = fake.HTTPClient.Do(req)
+	c.Assert(err, check.IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, check.Equals, http.StatusOK)
+}
@@ -15,41 +15,220 @@
 package testing
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
-
-	"github.com/sirupsen/logrus"
+	"path"
+	"strings"
+	"sync"
 )
 
-type MockClient struct{}
+// MockCall is a call made to MockClient
+type MockCall struct {
+	// Method is the http method, GET, POST, PATCH, PUT or DELETE.
+	Method     string
+	Resource   string
+	Parameters map[string]interface{}
+	// Data is the body passed to Post, Patch and Put.
+	Data interface{}
+	// Request is only set for calls to Do.
+	Request *http.Request
+}
 
-func (m *MockClient) Debug(set ...bool) bool {
-	return false
+// JSON returns Data encoded as JSON, or "" if there is none
+func (c *MockCall) JSON() string {
+	if c.Data == nil {
+		return ""
+	}
+	js, err := json.Marshal(c.Data)
+	if err != nil {
+		return fmt.Sprintf("!error: %s", err)
+	}
+	return string(js)
 }
 
-func (m *MockClient) Log(level ...logrus.Level) *logrus.Logger {
-	return logrus.New()
+// MockStub is a canned response for calls matching Method and Resource
+type MockStub struct {
+	// Method to match, empty matches any.
+	Method string
+	// Resource is a path.Match pattern, for example "/lists/*/members".
+	// A leading slash is optional.
+	Resource string
+
+	Response string
+	Err      error
+
+	// Hits increments each time the stub is used
+	Hits int
 }
 
-func (m *MockClient) Get(resource string, parameters map[string]interface{}) ([]byte, error) {
-	return []byte("{}"), nil
+// MockClient implements mailchimp.MailchimpClient without doing any requests.
+// Every call is recorded in Calls and answered by the last matching stub,
+// or with "{}" if no stub matches. It is safe for concurrent use.
+//
+//  client := testing.NewMockClient()
+//  client.Stub("GET", "/lists/*", `{"id":"1"}`, nil)
+//  list := &mailchimp.List{ID: "1", Client: client}
+type MockClient struct {
+	Calls []*MockCall
+	Stubs []*MockStub
+
+	mu sync.Mutex
 }
 
-func (m *MockClient) Post(resource string, parameters map[string]interface{}, data interface{}) ([]byte, error) {
-	return []byte("{}"), nil
+// NewMockClient returns a client without stubs
+func NewMockClient() *MockClient {
+	return &MockClient{
+		Calls: []*MockCall{},
+		Stubs: []*MockStub{},
+	}
 }
 
-func (m *MockClient) Patch(resource string, parameters map[string]interface{}, data interface{}) ([]byte, error) {
-	return []byte("{}"), nil
+// Stub adds a response for method and resource. It overrides stubs added
+// before it that match the same calls, stubs can be used any number of times.
+func (m *MockClient) Stub(method string, resource string, response string, err error) *MockStub {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stub := &MockStub{
+		Method:   method,
+		Resource: resource,
+		Response: response,
+		Err:      err,
+	}
+	m.Stubs = append(m.Stubs, stub)
+	return stub
+}
+
+// Reset removes all calls and stubs
+func (m *MockClient) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Calls = []*MockCall{}
+	m.Stubs = []*MockStub{}
+}
+
+// CallsTo returns the recorded calls matching method and resource, which
+// may be a path.Match pattern
+func (m *MockClient) CallsTo(method string, resource string) []*MockCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	calls := []*MockCall{}
+	for _, call := range m.Calls {
+		if matchCall(method, resource, call.Method, call.Resource) {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// AssertCalled reports an error if no call matched method and resource.
+// It returns the last matching call.
+func (m *MockClient) AssertCalled(t TestingT, method string, resource string) *MockCall {
+	calls := m.CallsTo(method, resource)
+	if len(calls) == 0 {
+		t.Errorf("mock client: expected a call to %s %s, got:\n%s", method, resource, m.describe())
+		return nil
+	}
+	return calls[len(calls)-1]
+}
+
+// AssertNotCalled reports an error if any call matched method and resource
+func (m *MockClient) AssertNotCalled(t TestingT, method string, resource string) {
+	if calls := m.CallsTo(method, resource); len(calls) > 0 {
+		t.Errorf("mock client: expected no call to %s %s, got %d", method, resource, len(calls))
+	}
 }
 
-func (m *MockClient) Put(resource string, parameters map[string]interface{}, data interface{}) ([]byte, error) {
+// AssertCallCount reports an error unless exactly n calls were made
+func (m *MockClient) AssertCallCount(t TestingT, n int) {
+	m.mu.Lock()
+	count := len(m.Calls)
+	m.mu.Unlock()
+
+	if count != n {
+		t.Errorf("mock client: expected %d calls, got %d:\n%s", n, count, m.describe())
+	}
+}
+
+func (m *MockClient) describe() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.Calls) == 0 {
+		return "  no calls"
+	}
+	lines := []string{}
+	for _, call := range m.Calls {
+		line := "  " + call.Method + " " + call.Resource
+		if js := call.JSON(); js != "" {
+			line += " " + js
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// call records a call and returns the stubbed response
+func (m *MockClient) call(call *MockCall) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Calls = append(m.Calls, call)
+
+	for n := len(m.Stubs) - 1; n >= 0; n-- {
+		stub := m.Stubs[n]
+		if matchCall(stub.Method, stub.Resource, call.Method, call.Resource) {
+			stub.Hits++
+			return []byte(stub.Response), stub.Err
+		}
+	}
 	return []byte("{}"), nil
 }
 
-func (m *MockClient) Delete(resource string) error {
-	return nil
+func matchCall(method string, resource string, callMethod string, callResource string) bool {
+	if method != "" && method != callMethod {
+		return false
+	}
+	if resource == "" {
+		return true
+	}
+	// The client joins resources without a leading slash
+	ok, _ := path.Match(strings.TrimPrefix(resource, "/"), strings.TrimPrefix(callResource, "/"))
+	return ok
+}
+
+// Get fulfills mailchimp.MailchimpClient
+func (m *MockClient) Get(ctx context.Context, resource string, parameters map[string]interface{}) ([]byte, error) {
+	return m.call(&MockCall{Method: http.MethodGet, Resource: resource, Parameters: parameters})
 }
 
+// Post fulfills mailchimp.MailchimpClient
+func (m *MockClient) Post(ctx context.Context, resource string, parameters map[string]interface{}, data interface{}) ([]byte, error) {
+	return m.call(&MockCall{Method: http.MethodPost, Resource: resource, Parameters: parameters, Data: data})
+}
+
+// Patch fulfills mailchimp.MailchimpClient
+func (m *MockClient) Patch(ctx context.Context, resource string, parameters map[string]interface{}, data interface{}) ([]byte, error) {
+	return m.call(&MockCall{Method: http.MethodPatch, Resource: resource, Parameters: parameters, Data: data})
+}
+
+// Put fulfills mailchimp.MailchimpClient
+func (m *MockClient) Put(ctx context.Context, resource string, parameters map[string]interface{}, data interface{}) ([]byte, error) {
+	return m.call(&MockCall{Method: http.MethodPut, Resource: resource, Parameters: parameters, Data: data})
+}
+
+// Delete fulfills mailchimp.MailchimpClient
+func (m *MockClient) Delete(ctx context.Context, resource string) error {
+	_, err := m.call(&MockCall{Method: http.MethodDelete, Resource: resource})
+	return err
+}
+
+// Do fulfills mailchimp.MailchimpClient, the call is recorded with the
+// request method and url path
 func (m *MockClient) Do(request *http.Request) ([]byte, error) {
-	return []byte("{}"), nil
+	return m.call(&MockCall{Method: request.Method, Resource: request.URL.Path, Request: request})
 }
@@ -0,0 +1,97 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	check "gopkg.in/check.v1"
+)
+
+var _ = check.Suite(&MockClientSuite{})
+
+type MockClientSuite struct {
+	client *MockClient
+}
+
+func (s *MockClientSuite) SetUpSuite(c *check.C) {}
+
+func (s *MockClientSuite) SetUpTest(c *check.C) {
+	s.client = NewMockClient()
+}
+
+func (s *MockClientSuite) TearDownTest(c *check.C) {}
+
+func (s *MockClientSuite) Test_Stubs(c *check.C) {
+	s.client.Stub("", "/lists/*", `"any"`, nil)
+	first := s.client.Stub("GET", "/lists/1", `"one"`, nil)
+
+	response, err := s.client.Get(context.Background(), "/lists/1", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(response), check.Equals, `"one"`)
+	c.Assert(first.Hits, check.Equals, 1)
+
+	response, _ = s.client.Post(context.Background(), "/lists/2", nil, map[string]string{"name": "x"})
+	c.Assert(string(response), check.Equals, `"any"`)
+
+	response, _ = s.client.Get(context.Background(), "/campaigns", nil)
+	c.Assert(string(response), check.Equals, "{}")
+
+	request, _ := http.NewRequest("GET", "http://us13.api.mailchimp.com/3.0/lists/2", nil)
+	response, _ = s.client.Do(request)
+	c.Assert(string(response), check.Equals, "{}")
+
+	c.Assert(s.client.CallsTo("POST", "/lists/*")[0].JSON(), check.Equals, `{"name":"x"}`)
+	c.Assert(s.client.CallsTo("GET", ""), check.HasLen, 3)
+
+	s.client.Reset()
+	c.Assert(s.client.Calls, check.HasLen, 0)
+	c.Assert(s.client.Stubs, check.HasLen, 0)
+}
+
+func (s *MockClientSuite) Test_Stub_Override(c *check.C) {
+	s.client.Stub("GET", "/lists/*", `"list"`, nil)
+	s.client.Stub("GET", "/lists/abc/members/*", `"member"`, nil)
+
+	response, _ := s.client.Get(context.Background(), "lists/abc/members/1", nil)
+	c.Assert(string(response), check.Equals, `"member"`)
+	response, _ = s.client.Get(context.Background(), "lists/abc", nil)
+	c.Assert(string(response), check.Equals, `"list"`)
+
+	// A later stub for the same calls replaces the earlier one
+	override := s.client.Stub("GET", "/lists/*", `"other list"`, errors.New("down"))
+	response, err := s.client.Get(context.Background(), "lists/abc", nil)
+	c.Assert(string(response), check.Equals, `"other list"`)
+	c.Assert(err, check.ErrorMatches, "down")
+	c.Assert(override.Hits, check.Equals, 1)
+	c.Assert(s.client.Stubs[0].Hits, check.Equals, 1)
+}
+
+func (s *MockClientSuite) Test_Assertions(c *check.C) {
+	t := &recorder{}
+	s.client.Delete(context.Background(), "/lists/1")
+
+	c.Assert(s.client.AssertCalled(t, "GET", "/lists/1"), check.IsNil)
+	s.client.AssertNotCalled(t, "DELETE", "/lists/*")
+	s.client.AssertCallCount(t, 2)
+
+	c.Assert(t.failures, check.DeepEquals, []string{
+		"mock client: expected a call to GET /lists/1, got:\n  DELETE /lists/1",
+		"mock client: expected no call to DELETE /lists/*, got 1",
+		"mock client: expected 2 calls, got 1:\n  DELETE /lists/1",
+	})
+}
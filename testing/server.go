@@ -15,21 +15,32 @@
 package testing
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
 
 	"github.com/kr/pretty"
-	. "gopkg.in/check.v1"
 )
 
+// TestingT is the part of *testing.T and *check.C that MockServer uses to
+// report failures. Both can be passed to SetChecker and VerifyNoMoreRequests.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
 // MockServer holds queries of mock responses and stores the requests made
-// to it.
+// to it. It is safe for concurrent use, so it can serve parallel requests.
 type MockServer struct {
 	// Checker are invalidated on every new function call. Update before every usage.
-	Checker *C
+	Checker TestingT
 
 	Responses []*MockResponse
 	Requests  []*http.Request
@@ -39,13 +50,26 @@ type MockServer struct {
 	BaseURL    string
 	Server     *httptest.Server
 	HTTPClient *http.Client
+
+	mu     sync.Mutex
+	faults *Faults
 }
 
-// MockResponse defines a response to a matching request. Requests are matched based on
-// Method and order of when they were added to the response queue.
+// MockResponse defines a response to a matching request. Requests are matched
+// on Method, Path, Query, JSON and Match, the ones left empty match anything.
+// When several responses match, the first one added wins.
 type MockResponse struct {
 	// Method matches against a incomming request
 	Method string
+	// Path is a path.Match pattern for the request path, without the api
+	// version prefix. For example "/lists/*/members".
+	Path string
+	// Query holds values the request query must contain, other keys are ignored.
+	Query url.Values
+	// JSON must be equal to the request body, compared as decoded JSON.
+	JSON string
+	// Match is called with the request and its body, return false to skip this response.
+	Match func(*http.Request, string) bool
 	// use http.Status<something> to reponde to a request.
 	Code int
 	// the response body to send back.
@@ -73,7 +97,7 @@ func NewMockServer() *MockServer {
 		Requests:  []*http.Request{},
 	}
 
-	server := httptest.NewServer(http.HandlerFunc(m.HandleRequest))
+	server := httptest.NewServer(m)
 
 	client := &http.Client{
 		Transport: &http.Transport{
@@ -92,28 +116,55 @@ func NewMockServer() *MockServer {
 
 // AddResponse adds a mock response that HandleRequest will look foor
 func (m *MockServer) AddResponse(r *MockResponse) *MockResponse {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.Responses = append(m.Responses, r)
 	return r
 }
 
 // VerifyNoMoreRequests checks that no requests are unmet
-func (m *MockServer) VerifyNoMoreRequests(c *C) {
-	unsatisified := []*MockResponse{}
+func (m *MockServer) VerifyNoMoreRequests(t TestingT) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	unsatisified := []string{}
 	for _, r := range m.Responses {
 		if !r.satisfied && !r.Persistant {
-			pretty.Println("Unsatisfied response:", r)
-			unsatisified = append(unsatisified, r)
+			unsatisified = append(unsatisified, "  "+r.describe())
 		}
 	}
 
 	if len(unsatisified) > 0 {
-		c.Fatal("server has unsatisfied responses")
-		c.Fail()
+		t.Fatalf("server has unsatisfied responses:\n%s", strings.Join(unsatisified, "\n"))
 	}
 }
 
 // SetChecker sets the checker for the next request handler
-func (m *MockServer) SetChecker(c *C) { m.Checker = c }
+func (m *MockServer) SetChecker(t TestingT) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Checker = t
+}
+
+// SetFaults injects faults in front of the mock responses, nil turns it off.
+// Requests that get a fault don't use up a response.
+func (m *MockServer) SetFaults(f *Faults) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.faults = f
+}
+
+// ServeHTTP fulfills http.Handler, it calls HandleRequest unless a fault is injected
+func (m *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	faults := m.faults
+	m.mu.Unlock()
+
+	faults.Handler(http.HandlerFunc(m.HandleRequest)).ServeHTTP(w, r)
+}
 
 // Close shuts down the server
 func (m *MockServer) Close() {
@@ -123,31 +174,51 @@ func (m *MockServer) Close() {
 // HandleRequest is a HTTP handler that matches the request to the mock responses
 // If a response with a matching url is found, the response body is written
 // to the writer and the CheckFn is called.
+// Requests without a match are answered with 418 I'm a teapot, and the
+// Checker gets a report of how the request differs from each response.
 func (m *MockServer) HandleRequest(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
 
 	var response *MockResponse
-	for _, resp := range m.Responses {
-		if !resp.satisfied && resp.Method == r.Method {
-			response = resp
-			break
+	for response == nil {
+		m.mu.Lock()
+		responses := append([]*MockResponse{}, m.Responses...)
+		satisfied := make([]bool, len(responses))
+		for i, resp := range responses {
+			satisfied[i] = resp.satisfied
 		}
-	}
+		checker := m.Checker
+		m.mu.Unlock()
 
-	if response == nil {
-
-		if m.Checker != nil {
-			errstr := fmt.Sprintf("Mock server: no matching response to request for %s:%s\n", r.Method, r.RequestURI)
-			m.Checker.Fatal(errstr)
+		// Matched without the lock, Match may use the server
+		var match *MockResponse
+		for i, resp := range responses {
+			if !satisfied[i] && len(resp.mismatch(r, string(body))) == 0 {
+				match = resp
+				break
+			}
 		}
 
-		w.WriteHeader(http.StatusTeapot)
-		w.Header().Set("Content-Type", "text/plain")
-		fmt.Fprintf(w, "no matching response to request for %s:%s\n", r.Method, r.RequestURI)
+		if match == nil {
+			if checker != nil {
+				checker.Errorf("%s", report(responses, satisfied, r, string(body)))
+			}
 
-		return
-	}
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusTeapot)
+			fmt.Fprintf(w, "no matching response to request for %s:%s\n", r.Method, r.RequestURI)
 
-	body, _ := ioutil.ReadAll(r.Body)
+			return
+		}
+
+		// Another request may have used the response in the meantime
+		m.mu.Lock()
+		if !match.satisfied {
+			response = match
+		} else {
+			m.mu.Unlock()
+		}
+	}
 
 	response.Hits++
 	if !response.Persistant {
@@ -157,13 +228,98 @@ func (m *MockServer) HandleRequest(w http.ResponseWriter, r *http.Request) {
 	response.Request = r
 	response.RequestBody = string(body)
 
+	m.Requests = append(m.Requests, r)
+
+	m.mu.Unlock()
+
+	// Called without the lock, CheckFn may use the server
 	if response.CheckFn != nil {
-		response.CheckFn(r, response.RequestBody)
+		response.CheckFn(r, string(body))
 	}
 
-	m.Requests = append(m.Requests, r)
-
-	w.WriteHeader(response.Code)
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.Code)
 	fmt.Fprintln(w, response.Body)
 }
+
+// report describes a unmatched request and why each of the responses that
+// weren't satisfied didn't match it
+func report(responses []*MockResponse, satisfied []bool, r *http.Request, body string) string {
+	lines := []string{fmt.Sprintf("Mock server: no matching response to request for %s:%s", r.Method, r.RequestURI)}
+	if body != "" {
+		lines = append(lines, "  body: "+body)
+	}
+
+	for i, resp := range responses {
+		if satisfied[i] {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  response %d: %s", i, resp.describe()))
+		for _, diff := range resp.mismatch(r, body) {
+			lines = append(lines, "    "+diff)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// describe returns a short summary of what the response matches
+func (resp *MockResponse) describe() string {
+	parts := []string{resp.Method}
+	if resp.Path != "" {
+		parts = append(parts, resp.Path)
+	}
+	if len(resp.Query) > 0 {
+		parts = append(parts, "?"+resp.Query.Encode())
+	}
+	if resp.JSON != "" {
+		parts = append(parts, resp.JSON)
+	}
+	if resp.Match != nil {
+		parts = append(parts, "(Match)")
+	}
+	return strings.Join(parts, " ")
+}
+
+// mismatch returns the differences between the response and the request,
+// it is empty if the response matches
+func (resp *MockResponse) mismatch(r *http.Request, body string) []string {
+	diffs := []string{}
+
+	if resp.Method != "" && resp.Method != r.Method {
+		diffs = append(diffs, fmt.Sprintf("method: expected %s, got %s", resp.Method, r.Method))
+	}
+
+	if resp.Path != "" {
+		requestPath := strings.TrimPrefix(r.URL.Path, "/3.0")
+		if ok, _ := path.Match(resp.Path, requestPath); !ok {
+			diffs = append(diffs, fmt.Sprintf("path: expected %s, got %s", resp.Path, requestPath))
+		}
+	}
+
+	query := r.URL.Query()
+	for key, values := range resp.Query {
+		if !reflect.DeepEqual(query[key], values) {
+			diffs = append(diffs, fmt.Sprintf("query %s: expected %q, got %q", key, values, query[key]))
+		}
+	}
+
+	if resp.JSON != "" {
+		var expected, actual interface{}
+		if err := json.Unmarshal([]byte(resp.JSON), &expected); err != nil {
+			diffs = append(diffs, fmt.Sprintf("json: invalid expected body: %s", err))
+		} else if err := json.Unmarshal([]byte(body), &actual); err != nil {
+			diffs = append(diffs, fmt.Sprintf("json: invalid request body: %s", err))
+		} else {
+			for _, diff := range pretty.Diff(expected, actual) {
+				diffs = append(diffs, "json: "+diff)
+			}
+		}
+	}
+
+	if resp.Match != nil && len(diffs) == 0 && !resp.Match(r, body) {
+		diffs = append(diffs, "Match returned false")
+	}
+
+	return diffs
+}
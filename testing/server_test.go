@@ -0,0 +1,176 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	stdtesting "testing"
+
+	check "gopkg.in/check.v1"
+)
+
+// Both test runners can report failures
+var (
+	_ TestingT = (*stdtesting.T)(nil)
+	_ TestingT = (*check.C)(nil)
+)
+
+var _ = check.Suite(&ServerSuite{})
+
+type ServerSuite struct {
+	server *MockServer
+}
+
+func (s *ServerSuite) SetUpSuite(c *check.C) {}
+
+func (s *ServerSuite) SetUpTest(c *check.C) {
+	s.server = NewMockServer()
+}
+
+func (s *ServerSuite) TearDownTest(c *check.C) {
+	s.server.Close()
+}
+
+// recorder is a TestingT that keeps the reported failures
+type recorder struct {
+	mu       sync.Mutex
+	failures []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+}
+
+func (s *ServerSuite) do(c *check.C, method string, uri string, body string) (int, string) {
+	req, err := http.NewRequest(method, "http://us13.api.mailchimp.com/3.0"+uri, strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+
+	resp, err := s.server.HTTPClient.Do(req)
+	c.Assert(err, check.IsNil)
+	defer resp.Body.Close()
+
+	data, _ := ioutil.ReadAll(resp.Body)
+	return resp.StatusCode, string(data)
+}
+
+func (s *ServerSuite) Test_Match_Path(c *check.C) {
+	s.server.AddResponse(&MockResponse{Method: "GET", Path: "/lists/*/members", Code: 200, Body: `"members"`})
+	s.server.AddResponse(&MockResponse{Method: "GET", Path: "/lists/*", Code: 200, Body: `"list"`})
+
+	// Out of order, matched by path
+	_, body := s.do(c, "GET", "/lists/1", "")
+	c.Assert(body, check.Equals, "\"list\"\n")
+	_, body = s.do(c, "GET", "/lists/1/members", "")
+	c.Assert(body, check.Equals, "\"members\"\n")
+
+	s.server.VerifyNoMoreRequests(c)
+}
+
+func (s *ServerSuite) Test_Match_Query(c *check.C) {
+	s.server.AddResponse(&MockResponse{Method: "GET", Query: url.Values{"offset": {"10"}}, Code: 200, Body: `2`})
+	s.server.AddResponse(&MockResponse{Method: "GET", Query: url.Values{"offset": {"0"}}, Code: 200, Body: `1`})
+
+	_, body := s.do(c, "GET", "/lists?count=10&offset=0", "")
+	c.Assert(body, check.Equals, "1\n")
+	_, body = s.do(c, "GET", "/lists?count=10&offset=10", "")
+	c.Assert(body, check.Equals, "2\n")
+}
+
+func (s *ServerSuite) Test_Match_JSON(c *check.C) {
+	s.server.AddResponse(&MockResponse{Method: "POST", JSON: `{"status":"subscribed","email_address":"b@example.com"}`, Code: 200, Body: `"b"`})
+	s.server.AddResponse(&MockResponse{
+		Method: "POST",
+		Match: func(r *http.Request, body string) bool {
+			return strings.Contains(body, "a@example.com")
+		},
+		Code: 200,
+		Body: `"a"`,
+	})
+
+	_, body := s.do(c, "POST", "/lists/1/members", `{"email_address":"a@example.com"}`)
+	c.Assert(body, check.Equals, "\"a\"\n")
+	_, body = s.do(c, "POST", "/lists/1/members", `{"email_address":"b@example.com","status":"subscribed"}`)
+	c.Assert(body, check.Equals, "\"b\"\n")
+}
+
+func (s *ServerSuite) Test_Match_UsesServer(c *check.C) {
+	t := &recorder{}
+	s.server.SetChecker(t)
+	s.server.AddResponse(&MockResponse{
+		Method: "GET",
+		// Match may add responses, it is called without the lock
+		Match: func(r *http.Request, body string) bool {
+			s.server.AddResponse(&MockResponse{Method: "POST", Code: 200, Body: `"added"`})
+			return false
+		},
+		Code: 200,
+	})
+
+	code, _ := s.do(c, "GET", "/lists", "")
+	c.Assert(code, check.Equals, http.StatusTeapot)
+	_, body := s.do(c, "POST", "/lists", "")
+	c.Assert(body, check.Equals, "\"added\"\n")
+}
+
+func (s *ServerSuite) Test_Mismatch_Report(c *check.C) {
+	t := &recorder{}
+	s.server.SetChecker(t)
+	s.server.AddResponse(&MockResponse{Method: "POST", Path: "/lists/*/members", JSON: `{"status":"subscribed"}`, Code: 200})
+
+	code, _ := s.do(c, "POST", "/lists/1/members", `{"status":"pending"}`)
+	c.Assert(code, check.Equals, http.StatusTeapot)
+	c.Assert(t.failures, check.HasLen, 1)
+	c.Assert(t.failures[0], check.Equals, strings.Join([]string{
+		"Mock server: no matching response to request for POST:http://us13.api.mailchimp.com/3.0/lists/1/members",
+		`  body: {"status":"pending"}`,
+		`  response 0: POST /lists/*/members {"status":"subscribed"}`,
+		`    json: ["status"]: "subscribed" != "pending"`,
+	}, "\n"))
+
+	s.server.VerifyNoMoreRequests(t)
+	c.Assert(t.failures, check.HasLen, 2)
+	c.Assert(t.failures[1], check.Matches, `(?s)server has unsatisfied responses:.*POST /lists/\*/members.*`)
+}
+
+func (s *ServerSuite) Test_Concurrent(c *check.C) {
+	s.server.AddResponse(&MockResponse{Method: "GET", Code: 200, Body: `{}`, Persistant: true})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := s.server.HTTPClient.Get("http://us13.api.mailchimp.com/3.0/lists")
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	c.Assert(s.server.Responses[0].Hits, check.Equals, 10)
+	c.Assert(s.server.Requests, check.HasLen, 10)
+}
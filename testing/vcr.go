@@ -0,0 +1,395 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// apiKeyPattern matches Mailchimp api keys, the datacenter suffix is kept
+// since it is part of the api url anyway.
+var apiKeyPattern = regexp.MustCompile(`[0-9a-f]{32}-(us[0-9]+)`)
+
+// ScrubbedKey replaces api keys in recorded cassettes
+const ScrubbedKey = "00000000000000000000000000000000"
+
+// Headers never written to a cassette
+var scrubbedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// Cassette is a recorded set of http interactions
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a single request and its response
+type Interaction struct {
+	Request  *RecordedRequest  `json:"request"`
+	Response *RecordedResponse `json:"response"`
+
+	used bool
+}
+
+// RecordedRequest is the part of a request used for matching
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is replayed as is
+type RecordedResponse struct {
+	Code   int         `json:"code"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// LoadCassette reads a cassette written by Recorder.Save
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cassette := &Cassette{}
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return cassette, nil
+}
+
+// Save writes the cassette to path as indented JSON, to keep diffs readable
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Recorder is a http.RoundTripper that sends requests on to Transport and
+// records them. Api keys, and any string in Secrets, are scrubbed from
+// urls, headers and bodies before they are recorded.
+//
+//  recorder := testing.NewRecorder(nil)
+//  client.HTTPClient = &http.Client{Transport: recorder}
+//  ...
+//  recorder.Save("testdata/lists.json")
+type Recorder struct {
+	// Transport sends the requests, defaults to http.DefaultTransport
+	Transport http.RoundTripper
+
+	// Secrets are replaced with "SCRUBBED" in the cassette
+	Secrets []string
+
+	mu       sync.Mutex
+	cassette *Cassette
+}
+
+// NewRecorder returns a recorder with a empty cassette
+func NewRecorder(transport http.RoundTripper) *Recorder {
+	return &Recorder{
+		Transport: transport,
+		cassette:  &Cassette{Interactions: []*Interaction{}},
+	}
+}
+
+// RoundTrip fulfills http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	interaction := &Interaction{
+		Request: &RecordedRequest{
+			Method: req.Method,
+			URL:    r.scrub(req.URL.String()),
+			Header: r.scrubHeader(req.Header),
+			Body:   r.scrub(string(reqBody)),
+		},
+		Response: &RecordedResponse{
+			Code:   resp.StatusCode,
+			Header: r.scrubHeader(resp.Header),
+			Body:   r.scrub(string(respBody)),
+		},
+	}
+
+	r.mu.Lock()
+	if r.cassette == nil {
+		r.cassette = &Cassette{}
+	}
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// Cassette returns the interactions recorded so far
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	interactions := []*Interaction{}
+	if r.cassette != nil {
+		interactions = append(interactions, r.cassette.Interactions...)
+	}
+	return &Cassette{Interactions: interactions}
+}
+
+// Save writes the recorded interactions to path
+func (r *Recorder) Save(path string) error {
+	return r.Cassette().Save(path)
+}
+
+func (r *Recorder) scrub(s string) string {
+	return scrub(s, r.Secrets)
+}
+
+// scrub replaces api keys and secrets in s, the same way for recording and
+// replaying so live requests match their recorded interactions
+func scrub(s string, secrets []string) string {
+	s = apiKeyPattern.ReplaceAllString(s, ScrubbedKey+"-$1")
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.Replace(s, secret, "SCRUBBED", -1)
+		}
+	}
+	return s
+}
+
+func (r *Recorder) scrubHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+
+	scrubbed := http.Header{}
+	for key, values := range header {
+		for _, v := range values {
+			scrubbed.Add(key, r.scrub(v))
+		}
+	}
+	for _, key := range scrubbedHeaders {
+		scrubbed.Del(key)
+	}
+	return scrubbed
+}
+
+// Replayer is a http.RoundTripper serving responses from a cassette.
+// Requests are matched on method, path, query and body. JSON bodies are
+// compared by value, so key order and whitespace do not matter. Each
+// interaction is replayed once, in the order they were recorded.
+//
+// Requests without a matching interaction are sent to Fallback, or fail
+// if Strict is set or Fallback is nil.
+type Replayer struct {
+	// Strict fails every request that was not recorded
+	Strict bool
+
+	// Fallback sends unrecorded requests when not Strict
+	Fallback http.RoundTripper
+
+	// Secrets are scrubbed from requests before they are matched, set
+	// them to the Secrets of the Recorder that made the cassette
+	Secrets []string
+
+	mu       sync.Mutex
+	cassette *Cassette
+}
+
+// NewReplayer returns a strict replayer for cassette
+func NewReplayer(cassette *Cassette) *Replayer {
+	return &Replayer{
+		Strict:   true,
+		cassette: cassette,
+	}
+}
+
+// LoadReplayer loads the cassette at path and returns a strict replayer for it
+func LoadReplayer(path string) (*Replayer, error) {
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(cassette), nil
+}
+
+// RoundTrip fulfills http.RoundTripper
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	interaction, closest := r.match(req, string(body))
+	if interaction != nil {
+		header := http.Header{}
+		for key, values := range interaction.Response.Header {
+			header[key] = append([]string{}, values...)
+		}
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.Code, http.StatusText(interaction.Response.Code)),
+			StatusCode:    interaction.Response.Code,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader([]byte(interaction.Response.Body))),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	if r.Strict || r.Fallback == nil {
+		return nil, fmt.Errorf("vcr: no recorded interaction for %s %s, %s", req.Method, req.URL, closest)
+	}
+	return r.Fallback.RoundTrip(req)
+}
+
+// Unused returns the interactions that have not been replayed
+func (r *Replayer) Unused() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unused := []*Interaction{}
+	if r.cassette == nil {
+		return unused
+	}
+	for _, i := range r.cassette.Interactions {
+		if !i.used {
+			unused = append(unused, i)
+		}
+	}
+	return unused
+}
+
+// match returns the first unused interaction matching req and marks it used.
+// Without a match it describes how the closest unused interaction differs.
+func (r *Replayer) match(req *http.Request, body string) (*Interaction, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cassette == nil {
+		return nil, "the cassette is empty"
+	}
+
+	live, err := url.Parse(scrub(req.URL.String(), r.Secrets))
+	if err != nil {
+		return nil, err.Error()
+	}
+	body = scrub(body, r.Secrets)
+
+	closest := -1
+	var closestDiffs []string
+	for n, i := range r.cassette.Interactions {
+		if i.used {
+			continue
+		}
+
+		diffs := i.mismatch(req.Method, live, body)
+		if len(diffs) == 0 {
+			i.used = true
+			return i, ""
+		}
+		if closest < 0 || len(diffs) < len(closestDiffs) {
+			closest = n
+			closestDiffs = diffs
+		}
+	}
+
+	if closest < 0 {
+		return nil, "all interactions were used"
+	}
+	i := r.cassette.Interactions[closest]
+	return nil, fmt.Sprintf("closest unused is interaction %d %s %s, %s", closest, i.Request.Method, i.Request.URL, strings.Join(closestDiffs, ", "))
+}
+
+// mismatch returns how a scrubbed request differs from the recorded one,
+// it is empty if they match
+func (i *Interaction) mismatch(method string, live *url.URL, body string) []string {
+	diffs := []string{}
+
+	if i.Request.Method != method {
+		diffs = append(diffs, fmt.Sprintf("method: got %s", method))
+	}
+
+	recorded, err := url.Parse(i.Request.URL)
+	if err != nil {
+		return append(diffs, fmt.Sprintf("invalid recorded url: %s", err))
+	}
+	if recorded.Path != live.Path {
+		diffs = append(diffs, fmt.Sprintf("path: got %s", live.Path))
+	}
+	if !reflect.DeepEqual(recorded.Query(), live.Query()) {
+		diffs = append(diffs, fmt.Sprintf("query: got %s", live.RawQuery))
+	}
+	if !bodiesEqual(i.Request.Body, body) {
+		diffs = append(diffs, fmt.Sprintf("body: got %s", body))
+	}
+
+	return diffs
+}
+
+// bodiesEqual compares JSON by value and anything else byte by byte
+func bodiesEqual(a string, b string) bool {
+	if a == b {
+		return true
+	}
+
+	var av, bv interface{}
+	if json.Unmarshal([]byte(a), &av) != nil || json.Unmarshal([]byte(b), &bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
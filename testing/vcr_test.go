@@ -0,0 +1,176 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testing
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	stdtesting "testing"
+
+	check "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test_Testing(t *stdtesting.T) { check.TestingT(t) }
+
+var _ = check.Suite(&VCRSuite{})
+
+type VCRSuite struct {
+	server *httptest.Server
+	hits   int
+}
+
+func (s *VCRSuite) SetUpTest(c *check.C) {
+	s.hits = 0
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.hits++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=1")
+		w.Header().Set("X-Echo-Key", "b12824bd84759ef84abc67fd789e7570-us13")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"path":"` + r.URL.Path + `"}`))
+	}))
+}
+
+func (s *VCRSuite) TearDownTest(c *check.C) {
+	s.server.Close()
+}
+
+func (s *VCRSuite) do(c *check.C, client *http.Client, method string, path string, body string) (*http.Response, string, error) {
+	req, _ := http.NewRequest(method, s.server.URL+path, strings.NewReader(body))
+	req.SetBasicAuth("OAuthToken", "b12824bd84759ef84abc67fd789e7570-us13")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	data, _ := ioutil.ReadAll(resp.Body)
+	return resp, string(data), nil
+}
+
+func (s *VCRSuite) record(c *check.C) string {
+	recorder := NewRecorder(nil)
+	recorder.Secrets = []string{"s3cret"}
+	client := &http.Client{Transport: recorder}
+
+	_, body, err := s.do(c, client, "GET", "/lists?count=10&offset=0", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(body, check.Equals, `{"path":"/lists"}`)
+
+	_, _, err = s.do(c, client, "POST", "/lists/1/members", `{"email_address":"s3cret@example.com","status":"subscribed"}`)
+	c.Assert(err, check.IsNil)
+
+	path := filepath.Join(c.MkDir(), "cassette.json")
+	c.Assert(recorder.Save(path), check.IsNil)
+	return path
+}
+
+func (s *VCRSuite) Test_Record_Scrubs(c *check.C) {
+	path := s.record(c)
+
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, check.IsNil)
+	c.Assert(strings.Contains(string(data), "b12824bd84759ef84abc67fd789e7570"), check.Equals, false)
+	c.Assert(strings.Contains(string(data), "s3cret"), check.Equals, false)
+	c.Assert(strings.Contains(string(data), "Authorization"), check.Equals, false)
+	c.Assert(strings.Contains(string(data), "Set-Cookie"), check.Equals, false)
+
+	cassette, err := LoadCassette(path)
+	c.Assert(err, check.IsNil)
+	c.Assert(cassette.Interactions, check.HasLen, 2)
+	c.Assert(cassette.Interactions[0].Response.Header.Get("X-Echo-Key"), check.Equals, ScrubbedKey+"-us13")
+	c.Assert(cassette.Interactions[1].Request.Body, check.Equals, `{"email_address":"SCRUBBED@example.com","status":"subscribed"}`)
+}
+
+func (s *VCRSuite) Test_Replay(c *check.C) {
+	path := s.record(c)
+	s.hits = 0
+
+	replayer, err := LoadReplayer(path)
+	c.Assert(err, check.IsNil)
+	client := &http.Client{Transport: replayer}
+
+	// Query order and JSON formatting do not matter
+	resp, body, err := s.do(c, client, "GET", "/lists?offset=0&count=10", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.StatusCode, check.Equals, http.StatusOK)
+	c.Assert(resp.Header.Get("Content-Type"), check.Equals, "application/json")
+	c.Assert(body, check.Equals, `{"path":"/lists"}`)
+
+	c.Assert(replayer.Unused(), check.HasLen, 1)
+
+	_, _, err = s.do(c, client, "POST", "/lists/1/members", `{"status": "subscribed", "email_address": "SCRUBBED@example.com"}`)
+	c.Assert(err, check.IsNil)
+
+	c.Assert(replayer.Unused(), check.HasLen, 0)
+	c.Assert(s.hits, check.Equals, 0)
+}
+
+func (s *VCRSuite) Test_Replay_Strict(c *check.C) {
+	replayer, err := LoadReplayer(s.record(c))
+	c.Assert(err, check.IsNil)
+	client := &http.Client{Transport: replayer}
+
+	_, _, err = s.do(c, client, "GET", "/lists?count=20&offset=0", "")
+	c.Assert(err, check.ErrorMatches, ".*vcr: no recorded interaction for GET .*/lists\\?count=20&offset=0, "+
+		"closest unused is interaction 0 GET .*/lists\\?count=10&offset=0, query: got count=20&offset=0")
+
+	_, _, err = s.do(c, client, "POST", "/lists/1/members", `{"email_address":"other@example.com","status":"subscribed"}`)
+	c.Assert(err, check.ErrorMatches, ".*vcr: no recorded interaction for POST .*, closest unused is interaction 1 POST .*/lists/1/members, body: got .*other@example.com.*")
+
+	// Each interaction is only replayed once
+	_, _, err = s.do(c, client, "GET", "/lists?count=10&offset=0", "")
+	c.Assert(err, check.IsNil)
+	_, _, err = s.do(c, client, "GET", "/lists?count=10&offset=0", "")
+	c.Assert(err, check.ErrorMatches, ".*closest unused is interaction 1 POST .*, method: got GET, path: got /lists, query: got count=10&offset=0, body: got ")
+}
+
+func (s *VCRSuite) Test_Replay_Secrets(c *check.C) {
+	replayer, err := LoadReplayer(s.record(c))
+	c.Assert(err, check.IsNil)
+	client := &http.Client{Transport: replayer}
+
+	// The live request still has the secret the cassette was scrubbed of
+	_, _, err = s.do(c, client, "POST", "/lists/1/members", `{"email_address":"s3cret@example.com","status":"subscribed"}`)
+	c.Assert(err, check.ErrorMatches, ".*no recorded interaction.*")
+
+	replayer.Secrets = []string{"s3cret"}
+	_, _, err = s.do(c, client, "POST", "/lists/1/members", `{"email_address":"s3cret@example.com","status":"subscribed"}`)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *VCRSuite) Test_Replay_ZeroValue(c *check.C) {
+	replayer := &Replayer{}
+	c.Assert(replayer.Unused(), check.HasLen, 0)
+
+	_, _, err := s.do(c, &http.Client{Transport: replayer}, "GET", "/lists", "")
+	c.Assert(err, check.ErrorMatches, ".*no recorded interaction for GET .*/lists, the cassette is empty")
+}
+
+func (s *VCRSuite) Test_Replay_Fallback(c *check.C) {
+	replayer, err := LoadReplayer(s.record(c))
+	c.Assert(err, check.IsNil)
+	replayer.Strict = false
+	replayer.Fallback = http.DefaultTransport
+	s.hits = 0
+
+	_, body, err := s.do(c, &http.Client{Transport: replayer}, "GET", "/campaigns", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(body, check.Equals, `{"path":"/campaigns"}`)
+	c.Assert(s.hits, check.Equals, 1)
+}
@@ -0,0 +1,83 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// Time is a timestamp from the api. API v3 sends RFC 3339, but older
+// resources and webhooks use TimeFormat in UTC, so both are accepted.
+// Empty strings and null unmarshal to the zero time, which marshals back
+// to an empty string. Other times marshal as RFC 3339 in UTC.
+//
+// Time is a struct, so omitempty never leaves out the zero time, it is
+// sent as "". Fields that are sent and may be unset use *Time instead.
+type Time struct {
+	time.Time
+}
+
+// NewTime wraps t
+func NewTime(t time.Time) Time {
+	return Time{t}
+}
+
+// ParseTime parses a RFC 3339 or TimeFormat timestamp
+func ParseTime(str string) (Time, error) {
+	if str == "" {
+		return Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, str); err == nil {
+		return Time{t.UTC()}, nil
+	}
+	t, err := time.ParseInLocation(TimeFormat, str, time.UTC)
+	if err != nil {
+		return Time{}, fmt.Errorf("invalid time: %q", str)
+	}
+	return Time{t}, nil
+}
+
+// UnmarshalJSON fulfills json.Unmarshaler
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*t = Time{}
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return fmt.Errorf("invalid time: %s", data)
+	}
+
+	parsed, err := ParseTime(string(data[1 : len(data)-1]))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// MarshalJSON fulfills json.Marshaler
+func (t Time) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + t.String() + `"`), nil
+}
+
+// String returns the time as RFC 3339 in UTC, or "" for the zero time
+func (t Time) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,101 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"encoding/json"
+	"time"
+
+	check "gopkg.in/check.v1"
+)
+
+var _ = check.Suite(&TimeSuite{})
+
+type TimeSuite struct{}
+
+func (s *TimeSuite) SetUpSuite(c *check.C) {}
+
+func (s *TimeSuite) SetUpTest(c *check.C) {}
+
+func (s *TimeSuite) TearDownTest(c *check.C) {}
+
+// mustParseTime is used for expected values in tests
+func mustParseTime(str string) Time {
+	t, err := ParseTime(str)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func (s *TimeSuite) Test_Unmarshal(c *check.C) {
+	expected := time.Date(2015, 9, 16, 14, 55, 51, 0, time.UTC)
+
+	for _, js := range []string{
+		`"2015-09-16T14:55:51+00:00"`,
+		`"2015-09-16T14:55:51Z"`,
+		`"2015-09-16T16:55:51+02:00"`,
+		`"2015-09-16 14:55:51"`,
+	} {
+		var t Time
+		c.Assert(json.Unmarshal([]byte(js), &t), check.IsNil, check.Commentf(js))
+		c.Assert(t.Equal(expected), check.Equals, true, check.Commentf(js))
+		c.Assert(t.Location(), check.Equals, time.UTC, check.Commentf(js))
+	}
+}
+
+func (s *TimeSuite) Test_Unmarshal_Empty(c *check.C) {
+	for _, js := range []string{`""`, `null`} {
+		t := NewTime(time.Now())
+		c.Assert(json.Unmarshal([]byte(js), &t), check.IsNil)
+		c.Assert(t.IsZero(), check.Equals, true)
+	}
+}
+
+func (s *TimeSuite) Test_Unmarshal_Invalid(c *check.C) {
+	var t Time
+	c.Assert(json.Unmarshal([]byte(`"2015-09-1621:14:46"`), &t), check.ErrorMatches, `invalid time: "2015-09-1621:14:46"`)
+	c.Assert(json.Unmarshal([]byte(`12`), &t), check.ErrorMatches, `invalid time: 12`)
+}
+
+func (s *TimeSuite) Test_Marshal(c *check.C) {
+	data := &CampaignScheduleData{
+		ScheduleTime: NewTime(time.Date(2017, 2, 4, 20, 15, 0, 0, time.FixedZone("CET", 3600))),
+	}
+	js, err := json.Marshal(data)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(js), check.Matches, `\{"schedule_time":"2017-02-04T19:15:00Z".*`)
+
+	js, err = json.Marshal(Time{})
+	c.Assert(err, check.IsNil)
+	c.Assert(string(js), check.Equals, `""`)
+}
+
+func (s *TimeSuite) Test_Marshal_Unset(c *check.C) {
+	// Write structs leave unset times out, Time fields are always sent
+	js, err := json.Marshal(&CampaignABSplitOpts{SplitTest: "schedule"})
+	c.Assert(err, check.IsNil)
+	c.Assert(string(js), check.Equals, `{"split_test":"schedule"}`)
+
+	js, err = json.Marshal(&Segment{Name: "Test"})
+	c.Assert(err, check.IsNil)
+	c.Assert(string(js), check.Equals, `{"name":"Test","created_at":"","updated_at":""}`)
+}
+
+func (s *TimeSuite) Test_List_TimeCreated(c *check.C) {
+	l := &List{}
+	c.Assert(json.Unmarshal([]byte(`{"date_created":"2015-09-16T14:55:51+00:00"}`), l), check.IsNil)
+	c.Assert(l.TimeCreated().Equal(time.Date(2015, 9, 16, 14, 55, 51, 0, time.UTC)), check.Equals, true)
+}
@@ -16,8 +16,16 @@ package mailchimp
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/schema"
 )
@@ -49,6 +57,9 @@ type Webhook struct {
 	// This field has links to schema types
 	Links json.RawMessage `json:"_links"`
 
+	// The secret embedded in URL, only set by CreateWebhook.
+	Secret string `json:"-"`
+
 	// Internal
 	Client MailchimpClient `json:"-"`
 }
@@ -91,24 +102,96 @@ type WebhookSources struct {
 // Webhook request, response definitions and implementation
 // ------------------------------------------------------------------------------
 
+// WebhookSecretParam is the query parameter holding the webhook secret
+const WebhookSecretParam = "token"
+
 // CreateWebhook defines the structure of a create webhook request to mailchimp.
 type CreateWebhook struct {
 	ListID  string          `json:"-"` // json marshal ignore
 	URL     string          `json:"url"`
 	Events  *WebhookEvents  `json:"events"`
 	Sources *WebhookSources `json:"sources"`
+
+	// Secret is added to the URL as a query token, verify it in your
+	// handler with WebhookHandler.Secrets or VerifyWebhookSecret.
+	Secret string `json:"-"`
+
+	// GenerateSecret creates a random Secret if none is set.
+	GenerateSecret bool `json:"-"`
+}
+
+// GenerateWebhookSecret returns a random secret suitable for webhook urls
+func GenerateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// WebhookURLWithSecret adds the secret to the webhook url as a query token
+func WebhookURLWithSecret(webhookURL string, secret string) (string, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	query.Set(WebhookSecretParam, secret)
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// VerifyWebhookSecret reports if the request carries one of the secrets.
+// Pass both the old and the new secret while rotating. All secrets are
+// compared in constant time.
+func VerifyWebhookSecret(r *http.Request, secrets ...string) bool {
+	token := []byte(r.URL.Query().Get(WebhookSecretParam))
+
+	ok := 0
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		ok |= subtle.ConstantTimeCompare(token, []byte(secret))
+	}
+
+	return ok == 1
 }
 
 // CreateWebhook adds a webhook to a list. Mailchimp will send events through this webhook on:
 // subcribes, unsubscribes, profile updates, email address changes and campaign sending status.
 // Returns webhook ID on success, otherwise error.
+// If request has a Secret, or GenerateSecret is set, the secret is added
+// to the url and returned in Webhook.Secret.
 func (c *Client) CreateWebhook(ctx context.Context, request *CreateWebhook) (*Webhook, error) {
 	_, err := c.GetList(ctx, request.ListID)
 	if err != nil {
 		return nil, err
 	}
 
-	response, err := c.Post(ctx, slashJoin(ListsURL, request.ListID, WebhooksURL), nil, request)
+	secret := request.Secret
+	if secret == "" && request.GenerateSecret {
+		secret, err = GenerateWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data := *request
+	if secret != "" {
+		data.URL, err = WebhookURLWithSecret(request.URL, secret)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	response, err := c.Post(ctx, slashJoin(ListsURL, request.ListID, WebhooksURL), nil, &data)
+	if err != nil {
+		Log.Error(err.Error(), caller())
+		return nil, err
+	}
 
 	var webhook *Webhook
 	err = json.Unmarshal(response, &webhook)
@@ -118,6 +201,7 @@ func (c *Client) CreateWebhook(ctx context.Context, request *CreateWebhook) (*We
 
 	// Add internal client
 	webhook.Client = c
+	webhook.Secret = secret
 
 	return webhook, nil
 }
@@ -177,6 +261,154 @@ func (c *Client) GetWebhook(ctx context.Context, listID string, webhookID string
 	return webhook, nil
 }
 
+// UpdateWebhook defines the fields that can be changed on a webhook.
+// Events and Sources are sent in full, so false values disable them.
+type UpdateWebhook struct {
+	URL     string          `json:"url,omitempty"`
+	Events  *WebhookEvents  `json:"events,omitempty"`
+	Sources *WebhookSources `json:"sources,omitempty"`
+}
+
+// MarshalJSON keeps false values in events and sources, which omitempty on
+// WebhookEvents and WebhookSources would drop.
+func (u UpdateWebhook) MarshalJSON() ([]byte, error) {
+	type events struct {
+		Subscribe   bool `json:"subscribe"`
+		Unsubscribe bool `json:"unsubscribe"`
+		Profile     bool `json:"profile"`
+		Cleaned     bool `json:"cleaned"`
+		UpEmail     bool `json:"upemail"`
+		Campaign    bool `json:"campaign"`
+	}
+	type sources struct {
+		User  bool `json:"user"`
+		Admin bool `json:"admin"`
+		API   bool `json:"api"`
+	}
+
+	data := struct {
+		URL     string   `json:"url,omitempty"`
+		Events  *events  `json:"events,omitempty"`
+		Sources *sources `json:"sources,omitempty"`
+	}{URL: u.URL}
+
+	if u.Events != nil {
+		e := events(*u.Events)
+		data.Events = &e
+	}
+	if u.Sources != nil {
+		s := sources(*u.Sources)
+		data.Sources = &s
+	}
+
+	return json.Marshal(data)
+}
+
+// Update changes the url, events or sources of the webhook and returns the updated webhook
+func (w *Webhook) Update(ctx context.Context, data *UpdateWebhook) (*Webhook, error) {
+	if w.Client == nil {
+		return nil, ErrorNoClient
+	}
+
+	response, err := w.Client.Patch(ctx, slashJoin(ListsURL, w.ListID, WebhooksURL, w.ID), nil, data)
+	if err != nil {
+		Log.Error(err.Error(), caller())
+		return nil, err
+	}
+
+	var webhook *Webhook
+	err = json.Unmarshal(response, &webhook)
+	if err != nil {
+		Log.Error(err.Error(), caller())
+		return nil, err
+	}
+
+	webhook.Client = w.Client
+
+	return webhook, nil
+}
+
+// EnsureWebhook makes sure the list has a webhook on webhookURL with exactly
+// the given events and sources. The webhook is created if it's missing and
+// updated if it differs, otherwise the existing webhook is returned untouched.
+// It is safe to call on every deploy.
+//
+// URLs are compared without the secret token, so a webhook created with a
+// secret is found from its plain url. If webhookURL carries a token, see
+// WebhookURLWithSecret, the stored url is updated when the token differs.
+func (c *Client) EnsureWebhook(ctx context.Context, listID string, webhookURL string, events *WebhookEvents, sources *WebhookSources) (*Webhook, error) {
+	webhooks, err := c.GetWebhooks(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	if events == nil {
+		events = &WebhookEvents{}
+	}
+	if sources == nil {
+		sources = &WebhookSources{}
+	}
+
+	withSecret := webhookURLSecret(webhookURL) != ""
+
+	for _, webhook := range webhooks {
+		if webhookURLWithoutSecret(webhook.URL) != webhookURLWithoutSecret(webhookURL) {
+			continue
+		}
+
+		if webhook.ListID == "" {
+			webhook.ListID = listID
+		}
+
+		update := &UpdateWebhook{
+			Events:  events,
+			Sources: sources,
+		}
+		if withSecret && webhook.URL != webhookURL {
+			update.URL = webhookURL
+		}
+
+		if update.URL == "" &&
+			webhook.Events != nil && *webhook.Events == *events &&
+			webhook.Sources != nil && *webhook.Sources == *sources {
+			return webhook, nil
+		}
+
+		return webhook.Update(ctx, update)
+	}
+
+	return c.CreateWebhook(ctx, &CreateWebhook{
+		ListID:  listID,
+		URL:     webhookURL,
+		Events:  events,
+		Sources: sources,
+	})
+}
+
+// webhookURLSecret returns the secret token of a webhook url, if any
+func webhookURLSecret(webhookURL string) string {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get(WebhookSecretParam)
+}
+
+// webhookURLWithoutSecret returns webhookURL without the secret token and
+// with the query in a stable order, for comparing webhook urls.
+func webhookURLWithoutSecret(webhookURL string) string {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return webhookURL
+	}
+
+	query := u.Query()
+	query.Del(WebhookSecretParam)
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
 // DeleteWebhook removes a webhook from mailchimp.
 // Returns error on failure
 func (w *Webhook) DeleteWebhook(ctx context.Context) error {
@@ -224,20 +456,97 @@ type WebhookEvent struct {
 	Subject    string `schema:"data[subject]"`
 	Status     string `schema:"data[status]"`
 
+	// Merges holds every data[merges][TAG] value, keyed by tag.
+	// Address merges are in Addresses and groupings in Groupings.
+	Merges map[string]string `schema:"-"`
+
+	// Groupings are the interest groupings sent as data[merges][GROUPINGS][n][...]
+	Groupings []*WebhookGrouping `schema:"-"`
+
+	// Addresses are the address merges sent as data[merges][TAG][addr1] and so on, keyed by tag.
+	Addresses map[string]*AddressValue `schema:"-"`
+
 	mergeFields map[string]string `schema:"-"`
 }
 
-// GetMergesField returns the value of the merge field if it exists and was defined in WebhookParseEvent.
+// webhookEventJSON has the fields of WebhookEvent without its json methods
+type webhookEventJSON WebhookEvent
+
+// MarshalJSON fulfills json.Marshaler. The form keys given to
+// WebhookParseEvent are included, so events kept in a EventStore can
+// still be looked up with GetMergesField.
+func (e WebhookEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		webhookEventJSON
+		MergeFields map[string]string `json:"merge_fields,omitempty"`
+	}{webhookEventJSON(e), e.mergeFields})
+}
+
+// UnmarshalJSON fulfills json.Unmarshaler
+func (e *WebhookEvent) UnmarshalJSON(data []byte) error {
+	var event struct {
+		webhookEventJSON
+		MergeFields map[string]string `json:"merge_fields,omitempty"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return err
+	}
+
+	*e = WebhookEvent(event.webhookEventJSON)
+	e.mergeFields = event.MergeFields
+	return nil
+}
+
+// WebhookGrouping is a interest grouping and the groups the member is in.
+type WebhookGrouping struct {
+	ID       string
+	UniqueID string
+	Name     string
+
+	// Groups is a comma separated list of group names. Commas
+	// in group names are escaped with a backslash.
+	Groups string
+}
+
+// GroupNames splits Groups into separate group names
+func (g *WebhookGrouping) GroupNames() []string {
+	if g.Groups == "" {
+		return []string{}
+	}
+
+	names := []string{}
+	current := []rune{}
+	escaped := false
+	for _, r := range g.Groups {
+		switch {
+		case escaped:
+			current = append(current, r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			names = append(names, strings.TrimSpace(string(current)))
+			current = current[:0]
+		default:
+			current = append(current, r)
+		}
+	}
+	return append(names, strings.TrimSpace(string(current)))
+}
+
+// GetMergesField returns the value of the merge field. field is either a merge tag
+// like FNAME or a form key defined in WebhookParseEvent.
 func (e *WebhookEvent) GetMergesField(field string) string {
-	if e.mergeFields == nil {
-		return ""
+	if v, ok := e.mergeFields[field]; ok && v != "" {
+		return v
 	}
 
-	return e.mergeFields[field]
+	return e.Merges[field]
 }
 
 // WebhookParseEvent will parse the webhook form event and return a WebhookEvent on success,
-// otherwise error.
+// otherwise error. All merges are decoded into Merges, Groupings and Addresses.
+// mergesFields is kept for compatibility, it allows looking up any form key with GetMergesField.
 func WebhookParseEvent(r *http.Request, mergesFields ...string) (*WebhookEvent, error) {
 	err := r.ParseForm()
 	if err != nil {
@@ -253,6 +562,10 @@ func WebhookParseEvent(r *http.Request, mergesFields ...string) (*WebhookEvent,
 		return nil, err
 	}
 
+	if err := event.parseMerges(r.Form); err != nil {
+		return nil, err
+	}
+
 	// Save defined merges fields
 	if event.mergeFields == nil {
 		event.mergeFields = map[string]string{}
@@ -264,3 +577,95 @@ func WebhookParseEvent(r *http.Request, mergesFields ...string) (*WebhookEvent,
 
 	return event, nil
 }
+
+// parseMerges decodes all data[merges][...] keys in form
+func (e *WebhookEvent) parseMerges(form url.Values) error {
+	e.Merges = map[string]string{}
+	e.Addresses = map[string]*AddressValue{}
+	groupings := map[int]*WebhookGrouping{}
+
+	for key, values := range form {
+		path := webhookFormPath(key)
+		if len(path) < 3 || path[0] != "data" || path[1] != "merges" {
+			continue
+		}
+
+		tag := path[2]
+		value := ""
+		if len(values) > 0 {
+			value = values[0]
+		}
+
+		switch {
+		case len(path) == 3:
+			e.Merges[tag] = value
+
+		case tag == "GROUPINGS" && len(path) == 5:
+			index, err := strconv.Atoi(path[3])
+			if err != nil {
+				return fmt.Errorf("malformed grouping key: %s", key)
+			}
+			grouping, ok := groupings[index]
+			if !ok {
+				grouping = &WebhookGrouping{}
+				groupings[index] = grouping
+			}
+			switch path[4] {
+			case "id":
+				grouping.ID = value
+			case "unique_id":
+				grouping.UniqueID = value
+			case "name":
+				grouping.Name = value
+			case "groups":
+				grouping.Groups = value
+			}
+
+		case len(path) == 4:
+			address, ok := e.Addresses[tag]
+			if !ok {
+				address = &AddressValue{}
+				e.Addresses[tag] = address
+			}
+			switch path[3] {
+			case "addr1":
+				address.Addr1 = value
+			case "addr2":
+				address.Addr2 = value
+			case "city":
+				address.City = value
+			case "state":
+				address.State = value
+			case "zip":
+				address.Zip = value
+			case "country":
+				address.Country = value
+			}
+		}
+	}
+
+	indexes := []int{}
+	for index := range groupings {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	e.Groupings = []*WebhookGrouping{}
+	for _, index := range indexes {
+		e.Groupings = append(e.Groupings, groupings[index])
+	}
+
+	return nil
+}
+
+// webhookFormPath splits "data[merges][GROUPINGS][0][id]" into
+// ["data", "merges", "GROUPINGS", "0", "id"]
+func webhookFormPath(key string) []string {
+	start := strings.Index(key, "[")
+	if start < 0 || !strings.HasSuffix(key, "]") {
+		return []string{key}
+	}
+
+	path := []string{key[:start]}
+	return append(path, strings.Split(key[start+1:len(key)-1], "][")...)
+}
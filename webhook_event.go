@@ -0,0 +1,222 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"fmt"
+	"time"
+)
+
+// WebhookAction is the data[action] of unsubscribe events
+type WebhookAction string
+
+const (
+	// WebhookActionUnsub the member unsubscribed and is kept on the list
+	WebhookActionUnsub WebhookAction = "unsub"
+	// WebhookActionDelete the member was deleted from the list
+	WebhookActionDelete WebhookAction = "delete"
+)
+
+// WebhookReason is the data[reason] of unsubscribe and cleaned events
+type WebhookReason string
+
+const (
+	// WebhookReasonManual the member unsubscribed themselves (unsubscribe)
+	WebhookReasonManual WebhookReason = "manual"
+	// WebhookReasonAbuse the member reported a campaign as spam (unsubscribe, cleaned)
+	WebhookReasonAbuse WebhookReason = "abuse"
+	// WebhookReasonHard the address hard bounced (cleaned)
+	WebhookReasonHard WebhookReason = "hard"
+)
+
+// FiredAtTime parses FiredAt. Mailchimp sends it in UTC without a zone.
+func (e *WebhookEvent) FiredAtTime() (time.Time, error) {
+	return time.ParseInLocation(TimeFormat, e.FiredAt, time.UTC)
+}
+
+// WebhookEventHeader holds the fields every typed event has
+type WebhookEventHeader struct {
+	Type    string
+	FiredAt time.Time
+	ListID  string
+}
+
+// SubscribeEvent is sent when a member subscribes
+type SubscribeEvent struct {
+	WebhookEventHeader
+
+	ID        string
+	Email     string
+	EmailType string
+	IPOpt     string
+	IPSignup  string
+
+	Merges    map[string]string
+	Groupings []*WebhookGrouping
+	Addresses map[string]*AddressValue
+}
+
+// UnsubscribeEvent is sent when a member unsubscribes or is deleted
+type UnsubscribeEvent struct {
+	WebhookEventHeader
+
+	Action     WebhookAction
+	Reason     WebhookReason
+	ID         string
+	Email      string
+	EmailType  string
+	IPOpt      string
+	CampaignID string
+
+	Merges    map[string]string
+	Groupings []*WebhookGrouping
+	Addresses map[string]*AddressValue
+}
+
+// ProfileEvent is sent when a member updates their profile
+type ProfileEvent struct {
+	WebhookEventHeader
+
+	ID        string
+	Email     string
+	EmailType string
+	IPOpt     string
+
+	Merges    map[string]string
+	Groupings []*WebhookGrouping
+	Addresses map[string]*AddressValue
+}
+
+// UpEmailEvent is sent when a member changes their email address
+type UpEmailEvent struct {
+	WebhookEventHeader
+
+	NewID    string
+	NewEmail string
+	OldEmail string
+}
+
+// CleanedEvent is sent when a address is cleaned from the list
+type CleanedEvent struct {
+	WebhookEventHeader
+
+	CampaignID string
+	Reason     WebhookReason
+	Email      string
+}
+
+// CampaignEvent is sent when a campaign is sent or cancelled
+type CampaignEvent struct {
+	WebhookEventHeader
+
+	ID      string
+	Subject string
+	Status  string
+
+	// Reason is set when sending failed
+	Reason string
+}
+
+// Typed converts the event to the struct matching its type, one of
+// *SubscribeEvent, *UnsubscribeEvent, *ProfileEvent, *UpEmailEvent,
+// *CleanedEvent or *CampaignEvent.
+//
+//  typed, err := event.Typed()
+//  ...
+//  switch e := typed.(type) {
+//  case *mailchimp.UnsubscribeEvent:
+//      ...
+//  }
+func (e *WebhookEvent) Typed() (interface{}, error) {
+	firedAt, err := e.FiredAtTime()
+	if err != nil {
+		return nil, err
+	}
+
+	header := WebhookEventHeader{
+		Type:    e.Type,
+		FiredAt: firedAt,
+		ListID:  e.ListID,
+	}
+
+	switch e.Type {
+	case WebhookEventTypeSubscribe:
+		return &SubscribeEvent{
+			WebhookEventHeader: header,
+			ID:                 e.ID,
+			Email:              e.Email,
+			EmailType:          e.EmailType,
+			IPOpt:              e.IPOpt,
+			IPSignup:           e.IPSignup,
+			Merges:             e.Merges,
+			Groupings:          e.Groupings,
+			Addresses:          e.Addresses,
+		}, nil
+
+	case WebhookEventTypeUnsubscribe:
+		return &UnsubscribeEvent{
+			WebhookEventHeader: header,
+			Action:             WebhookAction(e.Action),
+			Reason:             WebhookReason(e.Reason),
+			ID:                 e.ID,
+			Email:              e.Email,
+			EmailType:          e.EmailType,
+			IPOpt:              e.IPOpt,
+			CampaignID:         e.CampaignID,
+			Merges:             e.Merges,
+			Groupings:          e.Groupings,
+			Addresses:          e.Addresses,
+		}, nil
+
+	case WebhookEventTypeProfileUpdates:
+		return &ProfileEvent{
+			WebhookEventHeader: header,
+			ID:                 e.ID,
+			Email:              e.Email,
+			EmailType:          e.EmailType,
+			IPOpt:              e.IPOpt,
+			Merges:             e.Merges,
+			Groupings:          e.Groupings,
+			Addresses:          e.Addresses,
+		}, nil
+
+	case WebhookEventTypeEmailChanged:
+		return &UpEmailEvent{
+			WebhookEventHeader: header,
+			NewID:              e.NewID,
+			NewEmail:           e.NewEmail,
+			OldEmail:           e.OldEmail,
+		}, nil
+
+	case WebhookEventTypeEmailCleaned:
+		return &CleanedEvent{
+			WebhookEventHeader: header,
+			CampaignID:         e.CampaignID,
+			Reason:             WebhookReason(e.Reason),
+			Email:              e.Email,
+		}, nil
+
+	case WebhookEventTypeCampaignStatus:
+		return &CampaignEvent{
+			WebhookEventHeader: header,
+			ID:                 e.ID,
+			Subject:            e.Subject,
+			Status:             e.Status,
+			Reason:             e.Reason,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown event type: %s", e.Type)
+}
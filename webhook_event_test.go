@@ -0,0 +1,110 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"time"
+
+	check "gopkg.in/check.v1"
+)
+
+var _ = check.Suite(&WebhookEventSuite{})
+
+type WebhookEventSuite struct{}
+
+func (s *WebhookEventSuite) SetUpSuite(c *check.C) {}
+
+func (s *WebhookEventSuite) SetUpTest(c *check.C) {}
+
+func (s *WebhookEventSuite) TearDownTest(c *check.C) {}
+
+func (s *WebhookEventSuite) Test_FiredAtTime(c *check.C) {
+	t, err := (&WebhookEvent{FiredAt: "2009-03-26 21:35:57"}).FiredAtTime()
+	c.Assert(err, check.IsNil)
+	c.Assert(t.Location(), check.Equals, time.UTC)
+	c.Assert(t.Equal(time.Date(2009, 3, 26, 21, 35, 57, 0, time.UTC)), check.Equals, true)
+
+	_, err = (&WebhookEvent{FiredAt: "yesterday"}).FiredAtTime()
+	c.Assert(err, check.NotNil)
+}
+
+func (s *WebhookEventSuite) typed(c *check.C, eventType string) interface{} {
+	event, err := WebhookParseEvent(webhookFixtureRequest(eventType))
+	c.Assert(err, check.IsNil)
+	typed, err := event.Typed()
+	c.Assert(err, check.IsNil)
+	return typed
+}
+
+func (s *WebhookEventSuite) Test_Typed_Subscribe(c *check.C) {
+	e, ok := s.typed(c, WebhookEventTypeSubscribe).(*SubscribeEvent)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(e.Type, check.Equals, WebhookEventTypeSubscribe)
+	c.Assert(e.FiredAt.Equal(time.Date(2009, 3, 26, 21, 35, 57, 0, time.UTC)), check.Equals, true)
+	c.Assert(e.ListID, check.Equals, "a6b5da1054")
+	c.Assert(e.Email, check.Equals, "api@mailchimp.com")
+	c.Assert(e.IPSignup, check.Equals, "10.20.10.30")
+	c.Assert(e.Merges["FNAME"], check.Equals, "Mailchimp")
+}
+
+func (s *WebhookEventSuite) Test_Typed_Unsubscribe(c *check.C) {
+	e, ok := s.typed(c, WebhookEventTypeUnsubscribe).(*UnsubscribeEvent)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(e.Action, check.Equals, WebhookActionUnsub)
+	c.Assert(e.Reason, check.Equals, WebhookReasonManual)
+	c.Assert(e.CampaignID, check.Equals, "cb398d21d2")
+	c.Assert(e.Email, check.Equals, "api+unsub@mailchimp.com")
+}
+
+func (s *WebhookEventSuite) Test_Typed_Profile(c *check.C) {
+	e, ok := s.typed(c, WebhookEventTypeProfileUpdates).(*ProfileEvent)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(e.Email, check.Equals, "api@mailchimp.com")
+	c.Assert(e.Addresses["ADDRESS"].City, check.Equals, "Atlanta")
+	c.Assert(e.Groupings, check.HasLen, 2)
+}
+
+func (s *WebhookEventSuite) Test_Typed_UpEmail(c *check.C) {
+	e, ok := s.typed(c, WebhookEventTypeEmailChanged).(*UpEmailEvent)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(e.NewID, check.Equals, "51da8c3259")
+	c.Assert(e.NewEmail, check.Equals, "api+new@mailchimp.com")
+	c.Assert(e.OldEmail, check.Equals, "api+old@mailchimp.com")
+}
+
+func (s *WebhookEventSuite) Test_Typed_Cleaned(c *check.C) {
+	e, ok := s.typed(c, WebhookEventTypeEmailCleaned).(*CleanedEvent)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(e.Reason, check.Equals, WebhookReasonHard)
+	c.Assert(e.CampaignID, check.Equals, "4fjk2ma9xd")
+	c.Assert(e.Email, check.Equals, "api+cleaned@mailchimp.com")
+}
+
+func (s *WebhookEventSuite) Test_Typed_Campaign(c *check.C) {
+	e, ok := s.typed(c, WebhookEventTypeCampaignStatus).(*CampaignEvent)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(e.ID, check.Equals, "5aa2102003")
+	c.Assert(e.Subject, check.Equals, "Test Campaign Subject")
+	c.Assert(e.Status, check.Equals, "sent")
+	c.Assert(e.Reason, check.Equals, "")
+}
+
+func (s *WebhookEventSuite) Test_Typed_Errors(c *check.C) {
+	_, err := (&WebhookEvent{Type: "bogus", FiredAt: "2009-03-26 21:35:57"}).Typed()
+	c.Assert(err, check.ErrorMatches, "unknown event type: bogus")
+
+	_, err = (&WebhookEvent{Type: WebhookEventTypeSubscribe}).Typed()
+	c.Assert(err, check.NotNil)
+}
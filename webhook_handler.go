@@ -0,0 +1,160 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WebhookHandler is a http.Handler receiving Mailchimp webhooks and
+// dispatching them to a callback per event type, with the event converted
+// by Typed. Events without a callback are acknowledged and dropped.
+// Returning an error from a callback makes the handler respond with a
+// status that Mailchimp retries.
+//
+//  http.Handle("/webhook", &mailchimp.WebhookHandler{
+//      OnUnsubscribe: func(ctx context.Context, e *mailchimp.UnsubscribeEvent) error {
+//          return users.Unsubscribe(ctx, e.Email)
+//      },
+//  })
+type WebhookHandler struct {
+	OnSubscribe   func(ctx context.Context, event *SubscribeEvent) error
+	OnUnsubscribe func(ctx context.Context, event *UnsubscribeEvent) error
+	OnProfile     func(ctx context.Context, event *ProfileEvent) error
+	OnUpEmail     func(ctx context.Context, event *UpEmailEvent) error
+	OnCleaned     func(ctx context.Context, event *CleanedEvent) error
+	OnCampaign    func(ctx context.Context, event *CampaignEvent) error
+
+	// MergeFields are passed on to WebhookParseEvent
+	MergeFields []string
+
+	// Secrets, if any, are matched against the token in the webhook url.
+	// Requests without a valid token are rejected. Add the new secret
+	// next to the old one while rotating.
+	Secrets []string
+}
+
+// ServeHTTP fulfills http.Handler.
+// Mailchimp validates the webhook url with a GET request when it's created,
+// these get a 200 response. Any other method than GET and POST is rejected.
+// Requests with a missing or wrong secret get a 403. Malformed events get
+// a 400, errors from the callbacks a 503 so Mailchimp tries again later.
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	event, ok := h.accept(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Dispatch(r.Context(), event); err != nil {
+		Log.WithFields(logrus.Fields{
+			"type":  event.Type,
+			"error": err.Error(),
+		}).Error("webhook handler error", caller())
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// accept checks the method and secret of the request and parses the event.
+// If the request should not be processed any further, the response is
+// written and false returned.
+func (h *WebhookHandler) accept(w http.ResponseWriter, r *http.Request) (*WebhookEvent, bool) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return nil, false
+	}
+
+	if len(h.Secrets) > 0 && !VerifyWebhookSecret(r, h.Secrets...) {
+		Log.WithFields(logrus.Fields{
+			"remote": r.RemoteAddr,
+		}).Info("webhook secret mismatch", caller())
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return nil, false
+	}
+
+	if r.Method == http.MethodGet {
+		w.WriteHeader(http.StatusOK)
+		return nil, false
+	}
+
+	event, err := WebhookParseEvent(r, h.MergeFields...)
+	if err == nil && h.handles(event.Type) {
+		// Checked here as well as in Dispatch, retrying won't fix it
+		_, err = event.Typed()
+	}
+	if err != nil {
+		Log.WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Info("malformed webhook", caller())
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return nil, false
+	}
+
+	return event, true
+}
+
+// Dispatch converts the event with Typed and calls the callback registered
+// for its type
+func (h *WebhookHandler) Dispatch(ctx context.Context, event *WebhookEvent) error {
+	if !h.handles(event.Type) {
+		return nil
+	}
+
+	typed, err := event.Typed()
+	if err != nil {
+		return err
+	}
+
+	switch e := typed.(type) {
+	case *SubscribeEvent:
+		return h.OnSubscribe(ctx, e)
+	case *UnsubscribeEvent:
+		return h.OnUnsubscribe(ctx, e)
+	case *ProfileEvent:
+		return h.OnProfile(ctx, e)
+	case *UpEmailEvent:
+		return h.OnUpEmail(ctx, e)
+	case *CleanedEvent:
+		return h.OnCleaned(ctx, e)
+	case *CampaignEvent:
+		return h.OnCampaign(ctx, e)
+	}
+	return nil
+}
+
+// handles reports if a callback is registered for the event type
+func (h *WebhookHandler) handles(eventType string) bool {
+	switch eventType {
+	case WebhookEventTypeSubscribe:
+		return h.OnSubscribe != nil
+	case WebhookEventTypeUnsubscribe:
+		return h.OnUnsubscribe != nil
+	case WebhookEventTypeProfileUpdates:
+		return h.OnProfile != nil
+	case WebhookEventTypeEmailChanged:
+		return h.OnUpEmail != nil
+	case WebhookEventTypeEmailCleaned:
+		return h.OnCleaned != nil
+	case WebhookEventTypeCampaignStatus:
+		return h.OnCampaign != nil
+	}
+	return false
+}
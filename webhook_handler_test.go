@@ -0,0 +1,193 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	check "gopkg.in/check.v1"
+)
+
+var _ = check.Suite(&WebhookHandlerSuite{})
+
+type WebhookHandlerSuite struct{}
+
+func (s *WebhookHandlerSuite) SetUpSuite(c *check.C) {}
+
+func (s *WebhookHandlerSuite) SetUpTest(c *check.C) {}
+
+func (s *WebhookHandlerSuite) TearDownTest(c *check.C) {}
+
+func webhookRequest(method string, body string) *http.Request {
+	r := httptest.NewRequest(method, "http://example.net/webhook", strings.NewReader(body))
+	if method == http.MethodPost {
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	return r
+}
+
+func (s *WebhookHandlerSuite) Test_ServeHTTP_ValidationPing(c *check.C) {
+	w := httptest.NewRecorder()
+	(&WebhookHandler{}).ServeHTTP(w, webhookRequest(http.MethodGet, ""))
+	c.Assert(w.Code, check.Equals, http.StatusOK)
+}
+
+func (s *WebhookHandlerSuite) Test_ServeHTTP_MethodNotAllowed(c *check.C) {
+	w := httptest.NewRecorder()
+	(&WebhookHandler{}).ServeHTTP(w, webhookRequest(http.MethodPut, ""))
+	c.Assert(w.Code, check.Equals, http.StatusMethodNotAllowed)
+	c.Assert(w.Header().Get("Allow"), check.Equals, "GET, POST")
+}
+
+func (s *WebhookHandlerSuite) Test_ServeHTTP_Dispatch(c *check.C) {
+	called := map[string]interface{}{}
+	h := &WebhookHandler{
+		OnSubscribe: func(ctx context.Context, e *SubscribeEvent) error {
+			called[e.Type] = e
+			return nil
+		},
+		OnUnsubscribe: func(ctx context.Context, e *UnsubscribeEvent) error {
+			called[e.Type] = e
+			return nil
+		},
+		OnProfile: func(ctx context.Context, e *ProfileEvent) error {
+			called[e.Type] = e
+			return nil
+		},
+		OnUpEmail: func(ctx context.Context, e *UpEmailEvent) error {
+			called[e.Type] = e
+			return nil
+		},
+		OnCleaned: func(ctx context.Context, e *CleanedEvent) error {
+			called[e.Type] = e
+			return nil
+		},
+		OnCampaign: func(ctx context.Context, e *CampaignEvent) error {
+			called[e.Type] = e
+			return nil
+		},
+	}
+
+	firedAt := time.Date(2009, 3, 26, 21, 35, 57, 0, time.UTC)
+	for eventType, want := range map[string]interface{}{
+		"subscribe":   &SubscribeEvent{Email: "api@mailchimp.com"},
+		"unsubscribe": &UnsubscribeEvent{Email: "api@mailchimp.com"},
+		"profile":     &ProfileEvent{Email: "api@mailchimp.com"},
+		"upemail":     &UpEmailEvent{NewEmail: "api+new@mailchimp.com"},
+		"cleaned":     &CleanedEvent{Email: "api@mailchimp.com"},
+		"campaign":    &CampaignEvent{Subject: "Test"},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, webhookRequest(http.MethodPost, "type="+eventType+"&fired_at=2009-03-26+21%3A35%3A57&data%5Bemail%5D=api%40mailchimp.com&data%5Bnew_email%5D=api%2Bnew%40mailchimp.com&data%5Bsubject%5D=Test"))
+		c.Assert(w.Code, check.Equals, http.StatusOK)
+		c.Assert(called[eventType], check.FitsTypeOf, want)
+
+		switch e := called[eventType].(type) {
+		case *SubscribeEvent:
+			c.Assert(e.Email, check.Equals, "api@mailchimp.com")
+			c.Assert(e.FiredAt.Equal(firedAt), check.Equals, true)
+		case *UnsubscribeEvent:
+			c.Assert(e.Email, check.Equals, "api@mailchimp.com")
+		case *ProfileEvent:
+			c.Assert(e.Email, check.Equals, "api@mailchimp.com")
+		case *UpEmailEvent:
+			c.Assert(e.NewEmail, check.Equals, "api+new@mailchimp.com")
+		case *CleanedEvent:
+			c.Assert(e.Email, check.Equals, "api@mailchimp.com")
+		case *CampaignEvent:
+			c.Assert(e.Subject, check.Equals, "Test")
+		}
+	}
+}
+
+func (s *WebhookHandlerSuite) Test_ServeHTTP_NoCallback(c *check.C) {
+	w := httptest.NewRecorder()
+	(&WebhookHandler{}).ServeHTTP(w, webhookRequest(http.MethodPost, "type=subscribe"))
+	c.Assert(w.Code, check.Equals, http.StatusOK)
+}
+
+func (s *WebhookHandlerSuite) Test_ServeHTTP_HandlerError(c *check.C) {
+	h := &WebhookHandler{
+		OnUnsubscribe: func(ctx context.Context, e *UnsubscribeEvent) error {
+			return errors.New("database down")
+		},
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, webhookRequest(http.MethodPost, "type=unsubscribe&fired_at=2009-03-26+21%3A35%3A57"))
+	c.Assert(w.Code, check.Equals, http.StatusServiceUnavailable)
+}
+
+func (s *WebhookHandlerSuite) Test_ServeHTTP_BadFiredAt(c *check.C) {
+	called := false
+	h := &WebhookHandler{
+		OnUnsubscribe: func(ctx context.Context, e *UnsubscribeEvent) error {
+			called = true
+			return nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, webhookRequest(http.MethodPost, "type=unsubscribe&fired_at=yesterday"))
+	c.Assert(w.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(called, check.Equals, false)
+
+	err := h.Dispatch(context.Background(), &WebhookEvent{Type: WebhookEventTypeUnsubscribe})
+	c.Assert(err, check.NotNil)
+	c.Assert(called, check.Equals, false)
+}
+
+func (s *WebhookHandlerSuite) Test_ServeHTTP_Malformed(c *check.C) {
+	w := httptest.NewRecorder()
+	(&WebhookHandler{}).ServeHTTP(w, webhookRequest(http.MethodPost, "type=%zz"))
+	c.Assert(w.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *WebhookHandlerSuite) Test_ServeHTTP_Secret(c *check.C) {
+	called := 0
+	h := &WebhookHandler{
+		Secrets: []string{"old", "new"},
+		OnSubscribe: func(ctx context.Context, e *SubscribeEvent) error {
+			called++
+			return nil
+		},
+	}
+
+	for _, tc := range []struct {
+		method string
+		url    string
+		code   int
+	}{
+		{http.MethodGet, "http://example.net/webhook?token=new", http.StatusOK},
+		{http.MethodGet, "http://example.net/webhook", http.StatusForbidden},
+		{http.MethodPost, "http://example.net/webhook?token=old", http.StatusOK},
+		{http.MethodPost, "http://example.net/webhook?token=new", http.StatusOK},
+		{http.MethodPost, "http://example.net/webhook?token=forged", http.StatusForbidden},
+		{http.MethodPut, "http://example.net/webhook?token=new", http.StatusMethodNotAllowed},
+	} {
+		r := httptest.NewRequest(tc.method, tc.url, strings.NewReader("type=subscribe&fired_at=2009-03-26+21%3A35%3A57"))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		c.Assert(w.Code, check.Equals, tc.code, check.Commentf("%s %s", tc.method, tc.url))
+	}
+
+	c.Assert(called, check.Equals, 2)
+}
@@ -0,0 +1,369 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WebhookEventKey returns the deduplication key of a webhook form. It hashes
+// the type, fired_at and every data[...] value, so a retry from Mailchimp
+// gets the same key while the secret token in the url is ignored.
+func WebhookEventKey(form url.Values) string {
+	keys := []string{}
+	for k := range form {
+		if strings.HasPrefix(k, "data[") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "type=%s\nfired_at=%s\n", form.Get("type"), form.Get("fired_at"))
+	for _, k := range keys {
+		for _, v := range form[k] {
+			fmt.Fprintf(hash, "%s=%s\n", k, v)
+		}
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// ErrEventInFlight is returned by Replay for events that are being handled
+var ErrEventInFlight = errors.New("mailchimp: webhook event in flight")
+
+// WebhookQueue wraps a WebhookHandler. Events are persisted to Store and
+// acknowledged right away, then handled in the background by Workers.
+// Events Mailchimp sends more than once are only handled once, and failed
+// events are retried with exponential backoff.
+//
+//  queue := mailchimp.NewWebhookQueue(handler, store)
+//  queue.Start(ctx)
+//  http.Handle("/webhook", queue)
+type WebhookQueue struct {
+	Handler *WebhookHandler
+	Store   EventStore
+
+	// Number of events handled concurrently. Defaults to 1.
+	Workers int
+
+	// Events are marked as failed after this many attempts. Defaults to 5.
+	MaxAttempts int
+
+	// Delay before the first retry, doubled for every attempt. Defaults to 1s.
+	RetryDelay time.Duration
+
+	// The longest delay between retries. Defaults to 1h.
+	MaxRetryDelay time.Duration
+
+	// How often the store is checked for events due for retry. Defaults to 1s.
+	PollInterval time.Duration
+
+	once     sync.Once
+	queue    chan *QueuedEvent
+	mu       sync.Mutex
+	inFlight map[string]bool
+	wg       sync.WaitGroup
+}
+
+// NewWebhookQueue returns a queue handling events with handler
+func NewWebhookQueue(handler *WebhookHandler, store EventStore) *WebhookQueue {
+	return &WebhookQueue{
+		Handler: handler,
+		Store:   store,
+	}
+}
+
+func (q *WebhookQueue) init() {
+	q.once.Do(func() {
+		if q.Workers <= 0 {
+			q.Workers = 1
+		}
+		if q.MaxAttempts <= 0 {
+			q.MaxAttempts = 5
+		}
+		if q.RetryDelay <= 0 {
+			q.RetryDelay = time.Second
+		}
+		if q.MaxRetryDelay <= 0 {
+			q.MaxRetryDelay = time.Hour
+		}
+		if q.PollInterval <= 0 {
+			q.PollInterval = time.Second
+		}
+		q.queue = make(chan *QueuedEvent, 1000)
+		q.inFlight = map[string]bool{}
+	})
+}
+
+// ServeHTTP fulfills http.Handler. Requests are checked like WebhookHandler
+// does. Events that could not be stored get a 503 so Mailchimp tries again,
+// everything else, including duplicates, gets a 200.
+func (q *WebhookQueue) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q.init()
+
+	event, ok := q.Handler.accept(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	queued := &QueuedEvent{
+		ID:          WebhookEventKey(r.Form),
+		Event:       event,
+		ReceivedAt:  now,
+		Status:      QueuedEventPending,
+		NextAttempt: now,
+	}
+
+	added, err := q.Store.Add(r.Context(), queued)
+	if err != nil {
+		Log.WithFields(logrus.Fields{
+			"type":  event.Type,
+			"error": err.Error(),
+		}).Error("webhook store error", caller())
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	if added {
+		q.enqueue(queued)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// enqueue hands the event to a worker unless it is already queued. If the
+// queue is full the event is left for the sweeper to pick up.
+func (q *WebhookQueue) enqueue(event *QueuedEvent) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.enqueueLocked(event)
+}
+
+// enqueueLocked is enqueue for callers holding q.mu
+func (q *WebhookQueue) enqueueLocked(event *QueuedEvent) {
+	if q.inFlight[event.ID] {
+		return
+	}
+
+	select {
+	case q.queue <- event:
+		q.inFlight[event.ID] = true
+	default:
+	}
+}
+
+// Start runs the workers until ctx is done. Pending events left in the
+// store, for example from before a restart, are picked up as well.
+func (q *WebhookQueue) Start(ctx context.Context) {
+	q.init()
+
+	for n := 0; n < q.Workers; n++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			q.work(ctx)
+		}()
+	}
+
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		q.sweep(ctx)
+	}()
+}
+
+// Wait blocks until all workers started by Start have stopped
+func (q *WebhookQueue) Wait() {
+	q.wg.Wait()
+}
+
+// Replay marks all events received from, and including, from until to as
+// pending and handles them again. It returns the number of events replayed.
+// Events that are being handled right now are left alone, they are reported
+// with a error matching ErrEventInFlight after the others are replayed.
+func (q *WebhookQueue) Replay(ctx context.Context, from time.Time, to time.Time) (int, error) {
+	q.init()
+
+	events, err := q.Store.Range(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	busy := []string{}
+	now := time.Now().UTC()
+	for _, event := range events {
+		ok, err := q.replay(ctx, event, now)
+		if err != nil {
+			return replayed, err
+		}
+		if !ok {
+			busy = append(busy, event.ID)
+			continue
+		}
+		replayed++
+	}
+
+	if len(busy) > 0 {
+		return replayed, fmt.Errorf("%w: %s", ErrEventInFlight, strings.Join(busy, ", "))
+	}
+
+	return replayed, nil
+}
+
+// replay resets and enqueues a event, unless a worker is handling it. The
+// lock is held while saving so a worker can't pick up the event halfway.
+func (q *WebhookQueue) replay(ctx context.Context, event *QueuedEvent, now time.Time) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.inFlight[event.ID] {
+		return false, nil
+	}
+
+	event.Status = QueuedEventPending
+	event.Attempts = 0
+	event.NextAttempt = now
+	event.LastError = ""
+	if err := q.Store.Save(ctx, event); err != nil {
+		return false, err
+	}
+
+	q.enqueueLocked(event)
+	return true, nil
+}
+
+// sweep enqueues pending events that are due
+func (q *WebhookQueue) sweep(ctx context.Context) {
+	ticker := time.NewTicker(q.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := q.enqueueDue(ctx); err != nil {
+			Log.WithFields(logrus.Fields{
+				"error": err.Error(),
+			}).Error("webhook store error", caller())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// enqueueDue enqueues the pending events that are due. The lock is held
+// from reading the store until the events are enqueued, workers save an
+// event before they release it, so a event handled in the meantime is
+// either still in flight or no longer pending.
+func (q *WebhookQueue) enqueueDue(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	events, err := q.Store.Pending(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, event := range events {
+		if !event.NextAttempt.After(now) {
+			q.enqueueLocked(event)
+		}
+	}
+	return nil
+}
+
+func (q *WebhookQueue) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-q.queue:
+			q.process(ctx, event)
+
+			q.mu.Lock()
+			delete(q.inFlight, event.ID)
+			q.mu.Unlock()
+		}
+	}
+}
+
+// process handles a single event and stores the outcome. Events that fail
+// because ctx is done are left as they were, without counting the attempt.
+func (q *WebhookQueue) process(ctx context.Context, event *QueuedEvent) {
+	err := q.Handler.Dispatch(ctx, event.Event)
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	event.Attempts++
+	switch {
+	case err == nil:
+		event.Status = QueuedEventDone
+		event.LastError = ""
+
+	case event.Attempts >= q.MaxAttempts:
+		event.Status = QueuedEventFailed
+		event.LastError = err.Error()
+
+	default:
+		event.LastError = err.Error()
+		event.NextAttempt = time.Now().UTC().Add(q.retryDelay(event.Attempts))
+	}
+
+	if err != nil {
+		Log.WithFields(logrus.Fields{
+			"id":       event.ID,
+			"type":     event.Event.Type,
+			"attempts": event.Attempts,
+			"error":    err.Error(),
+		}).Error("webhook handler error", caller())
+	}
+
+	if err := q.Store.Save(ctx, event); err != nil {
+		Log.WithFields(logrus.Fields{
+			"id":    event.ID,
+			"error": err.Error(),
+		}).Error("webhook store error", caller())
+	}
+}
+
+// retryDelay returns the delay after the attempt, RetryDelay doubled for
+// every earlier attempt and capped at MaxRetryDelay
+func (q *WebhookQueue) retryDelay(attempts int) time.Duration {
+	delay := q.RetryDelay
+	for n := 1; n < attempts && delay < q.MaxRetryDelay; n++ {
+		delay *= 2
+	}
+	if delay > q.MaxRetryDelay {
+		delay = q.MaxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,396 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	check "gopkg.in/check.v1"
+)
+
+var _ = check.Suite(&WebhookQueueSuite{})
+
+type WebhookQueueSuite struct{}
+
+func (s *WebhookQueueSuite) SetUpSuite(c *check.C) {}
+
+func (s *WebhookQueueSuite) SetUpTest(c *check.C) {}
+
+func (s *WebhookQueueSuite) TearDownTest(c *check.C) {}
+
+// waitFor polls fn until it returns true or a second has passed
+func waitFor(c *check.C, fn func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !fn() {
+		if time.Now().After(deadline) {
+			c.Fatal("timed out")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func (s *WebhookQueueSuite) Test_WebhookEventKey(c *check.C) {
+	form := url.Values{
+		"type":          {"unsubscribe"},
+		"fired_at":      {"2009-03-26 21:40:57"},
+		"data[email]":   {"api@mailchimp.com"},
+		"data[list_id]": {"a6b5da1054"},
+	}
+
+	key := WebhookEventKey(form)
+	c.Assert(key, check.HasLen, 64)
+
+	form.Set(WebhookSecretParam, "secret")
+	c.Assert(WebhookEventKey(form), check.Equals, key)
+
+	form.Set("data[email]", "other@mailchimp.com")
+	c.Assert(WebhookEventKey(form), check.Not(check.Equals), key)
+
+	form.Set("data[email]", "api@mailchimp.com")
+	form.Set("fired_at", "2009-03-26 21:40:58")
+	c.Assert(WebhookEventKey(form), check.Not(check.Equals), key)
+}
+
+func (s *WebhookQueueSuite) Test_ServeHTTP_Dedupe(c *check.C) {
+	var mu sync.Mutex
+	calls := 0
+
+	store := NewMemoryEventStore()
+	q := NewWebhookQueue(&WebhookHandler{
+		OnUnsubscribe: func(ctx context.Context, e *UnsubscribeEvent) error {
+			mu.Lock()
+			defer mu.Unlock()
+			calls++
+			return nil
+		},
+	}, store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.Start(ctx)
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		q.ServeHTTP(w, webhookFixtureRequest(WebhookEventTypeUnsubscribe))
+		c.Assert(w.Code, check.Equals, http.StatusOK)
+	}
+
+	waitFor(c, func() bool {
+		pending, _ := store.Pending(ctx)
+		return len(pending) == 0
+	})
+
+	cancel()
+	q.Wait()
+
+	c.Assert(calls, check.Equals, 1)
+
+	events, _ := store.Range(ctx, time.Time{}, time.Now().Add(time.Hour))
+	c.Assert(events, check.HasLen, 1)
+	c.Assert(events[0].Status, check.Equals, QueuedEventDone)
+	c.Assert(events[0].Attempts, check.Equals, 1)
+	c.Assert(events[0].Event.Email, check.Equals, "api+unsub@mailchimp.com")
+}
+
+func (s *WebhookQueueSuite) Test_ServeHTTP_Rejected(c *check.C) {
+	q := NewWebhookQueue(&WebhookHandler{Secrets: []string{"secret"}}, NewMemoryEventStore())
+
+	w := httptest.NewRecorder()
+	q.ServeHTTP(w, webhookFixtureRequest(WebhookEventTypeUnsubscribe))
+	c.Assert(w.Code, check.Equals, http.StatusForbidden)
+
+	w = httptest.NewRecorder()
+	q.ServeHTTP(w, webhookRequest(http.MethodGet, ""))
+	c.Assert(w.Code, check.Equals, http.StatusForbidden)
+}
+
+type failingEventStore struct {
+	*MemoryEventStore
+}
+
+func (s *failingEventStore) Add(ctx context.Context, event *QueuedEvent) (bool, error) {
+	return false, errors.New("disk full")
+}
+
+func (s *WebhookQueueSuite) Test_ServeHTTP_StoreError(c *check.C) {
+	q := NewWebhookQueue(&WebhookHandler{}, &failingEventStore{NewMemoryEventStore()})
+
+	w := httptest.NewRecorder()
+	q.ServeHTTP(w, webhookFixtureRequest(WebhookEventTypeUnsubscribe))
+	c.Assert(w.Code, check.Equals, http.StatusServiceUnavailable)
+}
+
+func (s *WebhookQueueSuite) Test_Retry(c *check.C) {
+	var mu sync.Mutex
+	calls := 0
+
+	store := NewMemoryEventStore()
+	q := NewWebhookQueue(&WebhookHandler{
+		OnUnsubscribe: func(ctx context.Context, e *UnsubscribeEvent) error {
+			mu.Lock()
+			defer mu.Unlock()
+			calls++
+			if calls < 3 {
+				return errors.New("database down")
+			}
+			return nil
+		},
+	}, store)
+	q.RetryDelay = time.Millisecond
+	q.PollInterval = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.Start(ctx)
+
+	w := httptest.NewRecorder()
+	q.ServeHTTP(w, webhookFixtureRequest(WebhookEventTypeUnsubscribe))
+	c.Assert(w.Code, check.Equals, http.StatusOK)
+
+	waitFor(c, func() bool {
+		pending, _ := store.Pending(ctx)
+		return len(pending) == 0
+	})
+
+	cancel()
+	q.Wait()
+
+	events, _ := store.Range(ctx, time.Time{}, time.Now().Add(time.Hour))
+	c.Assert(events, check.HasLen, 1)
+	c.Assert(events[0].Status, check.Equals, QueuedEventDone)
+	c.Assert(events[0].Attempts, check.Equals, 3)
+	c.Assert(events[0].LastError, check.Equals, "")
+}
+
+func (s *WebhookQueueSuite) Test_Retry_MaxAttempts(c *check.C) {
+	store := NewMemoryEventStore()
+	q := NewWebhookQueue(&WebhookHandler{
+		OnUnsubscribe: func(ctx context.Context, e *UnsubscribeEvent) error {
+			return errors.New("database down")
+		},
+	}, store)
+	q.MaxAttempts = 2
+	q.RetryDelay = time.Millisecond
+	q.PollInterval = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.Start(ctx)
+
+	q.ServeHTTP(httptest.NewRecorder(), webhookFixtureRequest(WebhookEventTypeUnsubscribe))
+
+	waitFor(c, func() bool {
+		pending, _ := store.Pending(ctx)
+		return len(pending) == 0
+	})
+
+	cancel()
+	q.Wait()
+
+	events, _ := store.Range(ctx, time.Time{}, time.Now().Add(time.Hour))
+	c.Assert(events, check.HasLen, 1)
+	c.Assert(events[0].Status, check.Equals, QueuedEventFailed)
+	c.Assert(events[0].Attempts, check.Equals, 2)
+	c.Assert(events[0].LastError, check.Equals, "database down")
+}
+
+// staleEventStore returns the pending events as they were before a worker
+// finished them, like a sweep reading the store just before a save.
+type staleEventStore struct {
+	*MemoryEventStore
+	once sync.Once
+	done chan struct{}
+}
+
+func (s *staleEventStore) Save(ctx context.Context, event *QueuedEvent) error {
+	err := s.MemoryEventStore.Save(ctx, event)
+	if event.Status == QueuedEventDone {
+		s.once.Do(func() { close(s.done) })
+	}
+	return err
+}
+
+func (s *staleEventStore) Pending(ctx context.Context) ([]*QueuedEvent, error) {
+	events, err := s.MemoryEventStore.Pending(ctx)
+	select {
+	case <-s.done:
+	case <-time.After(time.Second):
+	}
+	// Give the worker time to release the event
+	time.Sleep(10 * time.Millisecond)
+	return events, err
+}
+
+func (s *WebhookQueueSuite) Test_Sweep_HandledOnce(c *check.C) {
+	var mu sync.Mutex
+	calls := 0
+
+	store := &staleEventStore{MemoryEventStore: NewMemoryEventStore(), done: make(chan struct{})}
+	q := NewWebhookQueue(&WebhookHandler{
+		OnUnsubscribe: func(ctx context.Context, e *UnsubscribeEvent) error {
+			mu.Lock()
+			defer mu.Unlock()
+			calls++
+			return nil
+		},
+	}, store)
+	q.PollInterval = time.Millisecond
+
+	// Queued before the workers start, so the first sweep races the worker
+	q.ServeHTTP(httptest.NewRecorder(), webhookFixtureRequest(WebhookEventTypeUnsubscribe))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.Start(ctx)
+
+	<-store.done
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	q.Wait()
+
+	c.Assert(calls, check.Equals, 1)
+}
+
+func (s *WebhookQueueSuite) Test_RetryDelay(c *check.C) {
+	q := NewWebhookQueue(&WebhookHandler{}, NewMemoryEventStore())
+	q.RetryDelay = time.Second
+	q.MaxRetryDelay = time.Minute
+	q.init()
+
+	c.Assert(q.retryDelay(1), check.Equals, time.Second)
+	c.Assert(q.retryDelay(3), check.Equals, 4*time.Second)
+	c.Assert(q.retryDelay(7), check.Equals, time.Minute)
+	c.Assert(q.retryDelay(100), check.Equals, time.Minute)
+}
+
+func (s *WebhookQueueSuite) Test_Process_Canceled(c *check.C) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	store := NewMemoryEventStore()
+	q := NewWebhookQueue(&WebhookHandler{
+		OnUnsubscribe: func(ctx context.Context, e *UnsubscribeEvent) error {
+			cancel()
+			return ctx.Err()
+		},
+	}, store)
+	q.MaxAttempts = 1
+	q.init()
+
+	event := queuedEvent("a", time.Now())
+	store.Add(ctx, event)
+	q.process(ctx, event)
+
+	pending, _ := store.Pending(context.Background())
+	c.Assert(pending, check.HasLen, 1)
+	c.Assert(pending[0].Attempts, check.Equals, 0)
+	c.Assert(pending[0].Status, check.Equals, QueuedEventPending)
+}
+
+func (s *WebhookQueueSuite) Test_Start_PicksUpPending(c *check.C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := NewMemoryEventStore()
+	store.Add(ctx, queuedEvent("a", time.Now().Add(-time.Minute)))
+
+	handled := make(chan string, 1)
+	q := NewWebhookQueue(&WebhookHandler{
+		OnUnsubscribe: func(ctx context.Context, e *UnsubscribeEvent) error {
+			handled <- e.Email
+			return nil
+		},
+	}, store)
+	q.Start(ctx)
+
+	select {
+	case email := <-handled:
+		c.Assert(email, check.Equals, "a@example.net")
+	case <-time.After(time.Second):
+		c.Fatal("timed out")
+	}
+}
+
+func (s *WebhookQueueSuite) Test_Replay(c *check.C) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	t0 := time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryEventStore()
+	for i, id := range []string{"a", "b", "c"} {
+		e := queuedEvent(id, t0.Add(time.Duration(i)*time.Hour))
+		e.Status = QueuedEventFailed
+		e.Attempts = 5
+		store.Add(ctx, e)
+	}
+
+	var mu sync.Mutex
+	handled := []string{}
+	q := NewWebhookQueue(&WebhookHandler{
+		OnUnsubscribe: func(ctx context.Context, e *UnsubscribeEvent) error {
+			mu.Lock()
+			defer mu.Unlock()
+			handled = append(handled, e.Email)
+			return nil
+		},
+	}, store)
+	q.Start(ctx)
+
+	n, err := q.Replay(ctx, t0, t0.Add(2*time.Hour))
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 2)
+
+	waitFor(c, func() bool {
+		pending, _ := store.Pending(ctx)
+		return len(pending) == 0
+	})
+
+	cancel()
+	q.Wait()
+
+	c.Assert(handled, check.DeepEquals, []string{"a@example.net", "b@example.net"})
+
+	events, _ := store.Range(ctx, t0, t0.Add(3*time.Hour))
+	c.Assert(events[0].Status, check.Equals, QueuedEventDone)
+	c.Assert(events[0].Attempts, check.Equals, 1)
+	c.Assert(events[2].Status, check.Equals, QueuedEventFailed)
+}
+
+func (s *WebhookQueueSuite) Test_Replay_InFlight(c *check.C) {
+	ctx := context.Background()
+
+	t0 := time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryEventStore()
+	for i, id := range []string{"a", "b"} {
+		e := queuedEvent(id, t0.Add(time.Duration(i)*time.Hour))
+		e.Status = QueuedEventFailed
+		e.Attempts = 5
+		store.Add(ctx, e)
+	}
+
+	q := NewWebhookQueue(&WebhookHandler{}, store)
+	q.init()
+	q.inFlight["a"] = true
+
+	n, err := q.Replay(ctx, t0, t0.Add(2*time.Hour))
+	c.Assert(errors.Is(err, ErrEventInFlight), check.Equals, true)
+	c.Assert(err, check.ErrorMatches, ".*: a")
+	c.Assert(n, check.Equals, 1)
+
+	events, _ := store.Range(ctx, t0, t0.Add(2*time.Hour))
+	c.Assert(events[0].Status, check.Equals, QueuedEventFailed)
+	c.Assert(events[0].Attempts, check.Equals, 5)
+	c.Assert(events[1].Status, check.Equals, QueuedEventPending)
+}
@@ -0,0 +1,257 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// QueuedEventStatus is the processing status of a QueuedEvent
+type QueuedEventStatus string
+
+const (
+	// QueuedEventPending events are waiting for their first or next attempt
+	QueuedEventPending QueuedEventStatus = "pending"
+	// QueuedEventDone events were handled without error
+	QueuedEventDone QueuedEventStatus = "done"
+	// QueuedEventFailed events used up all attempts
+	QueuedEventFailed QueuedEventStatus = "failed"
+)
+
+// QueuedEvent is a webhook event persisted by WebhookQueue
+type QueuedEvent struct {
+	// ID is the deduplication key, see WebhookEventKey.
+	ID string `                   json:"id"`
+
+	Event *WebhookEvent `          json:"event"`
+
+	// When the webhook was received.
+	ReceivedAt time.Time `         json:"received_at"`
+
+	Status QueuedEventStatus `     json:"status"`
+
+	// Number of times the event has been handled.
+	Attempts int `                 json:"attempts"`
+
+	// Pending events are not retried before this time.
+	NextAttempt time.Time `        json:"next_attempt"`
+
+	// The error from the last attempt, if any.
+	LastError string `             json:"last_error,omitempty"`
+}
+
+// copy returns a shallow copy, the event itself is never changed after parsing
+func (e *QueuedEvent) copy() *QueuedEvent {
+	c := *e
+	return &c
+}
+
+// EventStore persists webhook events for WebhookQueue. Implementations
+// must be safe for concurrent use.
+type EventStore interface {
+	// Add stores a new event. It returns false, and leaves the store
+	// untouched, if an event with the same ID is already stored.
+	Add(ctx context.Context, event *QueuedEvent) (bool, error)
+
+	// Save updates a stored event.
+	Save(ctx context.Context, event *QueuedEvent) error
+
+	// Pending returns all pending events, oldest first.
+	Pending(ctx context.Context) ([]*QueuedEvent, error)
+
+	// Range returns all events received from, and including, from until to, oldest first.
+	Range(ctx context.Context, from time.Time, to time.Time) ([]*QueuedEvent, error)
+}
+
+// MemoryEventStore keeps events in memory. Events are lost on restart.
+type MemoryEventStore struct {
+	mu     sync.Mutex
+	events map[string]*QueuedEvent
+}
+
+// NewMemoryEventStore returns a empty store
+func NewMemoryEventStore() *MemoryEventStore {
+	return &MemoryEventStore{
+		events: map[string]*QueuedEvent{},
+	}
+}
+
+// Add fulfills EventStore
+func (s *MemoryEventStore) Add(ctx context.Context, event *QueuedEvent) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.events[event.ID]; ok {
+		return false, nil
+	}
+	s.events[event.ID] = event.copy()
+	return true, nil
+}
+
+// Save fulfills EventStore
+func (s *MemoryEventStore) Save(ctx context.Context, event *QueuedEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.events[event.ID]; !ok {
+		return fmt.Errorf("unknown event: %s", event.ID)
+	}
+	s.events[event.ID] = event.copy()
+	return nil
+}
+
+// Pending fulfills EventStore
+func (s *MemoryEventStore) Pending(ctx context.Context) ([]*QueuedEvent, error) {
+	return s.filter(func(e *QueuedEvent) bool {
+		return e.Status == QueuedEventPending
+	}), nil
+}
+
+// Range fulfills EventStore
+func (s *MemoryEventStore) Range(ctx context.Context, from time.Time, to time.Time) ([]*QueuedEvent, error) {
+	return s.filter(func(e *QueuedEvent) bool {
+		return !e.ReceivedAt.Before(from) && e.ReceivedAt.Before(to)
+	}), nil
+}
+
+func (s *MemoryEventStore) filter(match func(*QueuedEvent) bool) []*QueuedEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := []*QueuedEvent{}
+	for _, e := range s.events {
+		if match(e) {
+			events = append(events, e.copy())
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].ReceivedAt.Equal(events[j].ReceivedAt) {
+			return events[i].ID < events[j].ID
+		}
+		return events[i].ReceivedAt.Before(events[j].ReceivedAt)
+	})
+
+	return events
+}
+
+// FileEventStore appends every change to a JSON lines file and reads it
+// back on start. The latest line for each event wins. The file is never
+// compacted, rotate it when it grows too large.
+type FileEventStore struct {
+	// Guards writes to file, mem has its own lock
+	mu   sync.Mutex
+	file *os.File
+	mem  *MemoryEventStore
+}
+
+// NewFileEventStore opens or creates the store at path
+func NewFileEventStore(path string) (*FileEventStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FileEventStore{
+		file: file,
+		mem:  NewMemoryEventStore(),
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var event *QueuedEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("%s:%d: %s", path, line, err)
+		}
+		s.mem.events[event.ID] = event
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Add fulfills EventStore
+func (s *FileEventStore) Add(ctx context.Context, event *QueuedEvent) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	added, err := s.mem.Add(ctx, event)
+	if err != nil || !added {
+		return added, err
+	}
+
+	if err := s.write(event); err != nil {
+		// Forget the event so the sender's retry can add it again
+		s.mem.mu.Lock()
+		delete(s.mem.events, event.ID)
+		s.mem.mu.Unlock()
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Save fulfills EventStore
+func (s *FileEventStore) Save(ctx context.Context, event *QueuedEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.mem.Save(ctx, event); err != nil {
+		return err
+	}
+	return s.write(event)
+}
+
+// Pending fulfills EventStore
+func (s *FileEventStore) Pending(ctx context.Context) ([]*QueuedEvent, error) {
+	return s.mem.Pending(ctx)
+}
+
+// Range fulfills EventStore
+func (s *FileEventStore) Range(ctx context.Context, from time.Time, to time.Time) ([]*QueuedEvent, error) {
+	return s.mem.Range(ctx, from, to)
+}
+
+// Close closes the underlying file
+func (s *FileEventStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// write appends the event and syncs the file, the caller must hold mu
+func (s *FileEventStore) write(event *QueuedEvent) error {
+	js, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.file.Write(append(js, '\n')); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
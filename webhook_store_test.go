@@ -0,0 +1,150 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailchimp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	check "gopkg.in/check.v1"
+)
+
+var _ = check.Suite(&EventStoreSuite{})
+
+type EventStoreSuite struct{}
+
+func (s *EventStoreSuite) SetUpSuite(c *check.C) {}
+
+func (s *EventStoreSuite) SetUpTest(c *check.C) {}
+
+func (s *EventStoreSuite) TearDownTest(c *check.C) {}
+
+func queuedEvent(id string, received time.Time) *QueuedEvent {
+	return &QueuedEvent{
+		ID:          id,
+		Event:       &WebhookEvent{Type: WebhookEventTypeUnsubscribe, FiredAt: received.UTC().Format(TimeFormat), Email: id + "@example.net"},
+		ReceivedAt:  received,
+		Status:      QueuedEventPending,
+		NextAttempt: received,
+	}
+}
+
+func (s *EventStoreSuite) testStore(c *check.C, store EventStore) {
+	ctx := context.Background()
+	t0 := time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	added, err := store.Add(ctx, queuedEvent("b", t0.Add(time.Minute)))
+	c.Assert(err, check.IsNil)
+	c.Assert(added, check.Equals, true)
+
+	added, err = store.Add(ctx, queuedEvent("a", t0))
+	c.Assert(err, check.IsNil)
+	c.Assert(added, check.Equals, true)
+
+	added, err = store.Add(ctx, queuedEvent("a", t0.Add(time.Hour)))
+	c.Assert(err, check.IsNil)
+	c.Assert(added, check.Equals, false)
+
+	pending, err := store.Pending(ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(pending, check.HasLen, 2)
+	c.Assert(pending[0].ID, check.Equals, "a")
+	c.Assert(pending[1].ID, check.Equals, "b")
+
+	// Changing a returned event does not change the store
+	pending[0].Status = QueuedEventDone
+	pending, _ = store.Pending(ctx)
+	c.Assert(pending, check.HasLen, 2)
+
+	done := pending[0]
+	done.Status = QueuedEventDone
+	done.Attempts = 1
+	c.Assert(store.Save(ctx, done), check.IsNil)
+
+	pending, err = store.Pending(ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(pending, check.HasLen, 1)
+	c.Assert(pending[0].ID, check.Equals, "b")
+
+	events, err := store.Range(ctx, t0, t0.Add(time.Minute))
+	c.Assert(err, check.IsNil)
+	c.Assert(events, check.HasLen, 1)
+	c.Assert(events[0].ID, check.Equals, "a")
+	c.Assert(events[0].Attempts, check.Equals, 1)
+
+	err = store.Save(ctx, queuedEvent("c", t0))
+	c.Assert(err, check.ErrorMatches, "unknown event: c")
+}
+
+func (s *EventStoreSuite) Test_MemoryEventStore(c *check.C) {
+	s.testStore(c, NewMemoryEventStore())
+}
+
+func (s *EventStoreSuite) Test_FileEventStore(c *check.C) {
+	path := filepath.Join(c.MkDir(), "events.jsonl")
+
+	store, err := NewFileEventStore(path)
+	c.Assert(err, check.IsNil)
+	s.testStore(c, store)
+	c.Assert(store.Close(), check.IsNil)
+
+	// Reopening reads back the latest state of every event
+	store, err = NewFileEventStore(path)
+	c.Assert(err, check.IsNil)
+	defer store.Close()
+
+	pending, err := store.Pending(context.Background())
+	c.Assert(err, check.IsNil)
+	c.Assert(pending, check.HasLen, 1)
+	c.Assert(pending[0].ID, check.Equals, "b")
+	c.Assert(pending[0].Event.Email, check.Equals, "b@example.net")
+
+	added, err := store.Add(context.Background(), queuedEvent("a", time.Now()))
+	c.Assert(err, check.IsNil)
+	c.Assert(added, check.Equals, false)
+}
+
+func (s *EventStoreSuite) Test_FileEventStore_MergeFields(c *check.C) {
+	path := filepath.Join(c.MkDir(), "events.jsonl")
+	store, err := NewFileEventStore(path)
+	c.Assert(err, check.IsNil)
+
+	event := queuedEvent("a", time.Now())
+	event.Event.Merges = map[string]string{"FNAME": "Joe"}
+	event.Event.mergeFields = map[string]string{"data[merges][MMERGE9]": "custom"}
+	_, err = store.Add(context.Background(), event)
+	c.Assert(err, check.IsNil)
+	c.Assert(store.Close(), check.IsNil)
+
+	store, err = NewFileEventStore(path)
+	c.Assert(err, check.IsNil)
+	defer store.Close()
+
+	pending, err := store.Pending(context.Background())
+	c.Assert(err, check.IsNil)
+	c.Assert(pending, check.HasLen, 1)
+	c.Assert(pending[0].Event.GetMergesField("FNAME"), check.Equals, "Joe")
+	c.Assert(pending[0].Event.GetMergesField("data[merges][MMERGE9]"), check.Equals, "custom")
+}
+
+func (s *EventStoreSuite) Test_FileEventStore_Corrupt(c *check.C) {
+	path := filepath.Join(c.MkDir(), "events.jsonl")
+	c.Assert(os.WriteFile(path, []byte("{\"id\":\"a\"}\nnot json\n"), 0600), check.IsNil)
+
+	_, err := NewFileEventStore(path)
+	c.Assert(err, check.ErrorMatches, ".*events.jsonl:2: .*")
+}
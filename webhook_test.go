@@ -16,7 +16,10 @@ package mailchimp
 
 import (
 	"context"
+	"net/http"
+	"net/url"
 	"os"
+	"strings"
 
 	check "gopkg.in/check.v1"
 
@@ -63,6 +66,95 @@ func (s *WebhookTestSuite) Skip_CreateWebhook(c *check.C) {
 	c.Assert(createWebhookResponse, check.NotNil)
 }
 
+func (s *WebhookTestSuite) Test_CreateWebhook_Secret(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"id":"1"}`,
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "POST",
+		Code:   200,
+		Body:   `{"id":"2","url":"http://test.url/webhook?a=b&token=s3cret","list_id":"1"}`,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(r.RequestURI, check.Equals, "http://us13.api.mailchimp.com/3.0/lists/1/webhooks")
+			c.Assert(body, check.Equals, `{"url":"http://test.url/webhook?a=b\u0026token=s3cret","events":{"subscribe":true},"sources":{"user":true}}`)
+		},
+	})
+
+	request := &CreateWebhook{
+		ListID:  "1",
+		URL:     "http://test.url/webhook?a=b",
+		Events:  &WebhookEvents{Subscribe: true},
+		Sources: &WebhookSources{User: true},
+		Secret:  "s3cret",
+	}
+	webhook, err := s.client.CreateWebhook(s.ctx, request)
+	c.Assert(err, check.IsNil)
+	c.Assert(webhook.Secret, check.Equals, "s3cret")
+	c.Assert(request.URL, check.Equals, "http://test.url/webhook?a=b")
+}
+
+func (s *WebhookTestSuite) Test_CreateWebhook_GenerateSecret(c *check.C) {
+	var sent string
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"id":"1"}`,
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "POST",
+		Code:   200,
+		Body:   `{"id":"2","list_id":"1"}`,
+		CheckFn: func(r *http.Request, body string) {
+			sent = body
+		},
+	})
+
+	webhook, err := s.client.CreateWebhook(s.ctx, &CreateWebhook{
+		ListID:         "1",
+		URL:            "http://test.url/webhook",
+		GenerateSecret: true,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(webhook.Secret, check.HasLen, 64)
+	c.Assert(sent, check.Matches, `\{"url":"http://test.url/webhook\?token=`+webhook.Secret+`".*`)
+}
+
+func (s *WebhookTestSuite) Test_CreateWebhook_ErrorResponse(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"id":"1"}`,
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "POST",
+		Code:   400,
+		Body:   `{"title":"Invalid Resource","status":400,"detail":"The resource submitted could not be validated."}`,
+	})
+
+	webhook, err := s.client.CreateWebhook(s.ctx, &CreateWebhook{ListID: "1", URL: "http://test.url/webhook"})
+	c.Assert(err, check.ErrorMatches, "Invalid Resource \\(400\\).*")
+	c.Assert(webhook, check.IsNil)
+}
+
+func (s *WebhookTestSuite) Test_VerifyWebhookSecret(c *check.C) {
+	u, err := WebhookURLWithSecret("http://test.url/webhook", "new")
+	c.Assert(err, check.IsNil)
+
+	r, _ := http.NewRequest("POST", u, nil)
+	c.Assert(VerifyWebhookSecret(r, "old", "new"), check.Equals, true)
+	c.Assert(VerifyWebhookSecret(r, "new"), check.Equals, true)
+	c.Assert(VerifyWebhookSecret(r, "old"), check.Equals, false)
+	c.Assert(VerifyWebhookSecret(r), check.Equals, false)
+
+	r, _ = http.NewRequest("POST", "http://test.url/webhook?token=", nil)
+	c.Assert(VerifyWebhookSecret(r, ""), check.Equals, false)
+
+	r, _ = http.NewRequest("POST", "http://test.url/webhook?token="+url.QueryEscape("a b"), nil)
+	c.Assert(VerifyWebhookSecret(r, "a b"), check.Equals, true)
+}
+
 func (s *WebhookTestSuite) Skip_GetWebhook(c *check.C) {
 	getWebhookResponse, err := s.client.GetWebhook(s.ctx, "1", "2")
 	c.Assert(err, check.IsNil)
@@ -83,3 +175,363 @@ func (s *WebhookTestSuite) Skip_DeleteWebhook(c *check.C) {
 	err = getWebhookResponse.DeleteWebhook(s.ctx)
 	c.Assert(err, check.IsNil)
 }
+
+// webhookFixtures are payloads for each event type as documented by Mailchimp,
+// one key=value pair per line.
+var webhookFixtures = map[string][]string{
+	WebhookEventTypeSubscribe: {
+		"type=subscribe",
+		"fired_at=2009-03-26 21:35:57",
+		"data[id]=8a25ff1d98",
+		"data[list_id]=a6b5da1054",
+		"data[email]=api@mailchimp.com",
+		"data[email_type]=html",
+		"data[merges][EMAIL]=api@mailchimp.com",
+		"data[merges][FNAME]=Mailchimp",
+		"data[merges][LNAME]=API",
+		"data[merges][INTERESTS]=Group1,Group2",
+		"data[ip_opt]=10.20.10.30",
+		"data[ip_signup]=10.20.10.30",
+	},
+	WebhookEventTypeUnsubscribe: {
+		"type=unsubscribe",
+		"fired_at=2009-03-26 21:40:57",
+		"data[action]=unsub",
+		"data[reason]=manual",
+		"data[id]=8a25ff1d98",
+		"data[list_id]=a6b5da1054",
+		"data[email]=api+unsub@mailchimp.com",
+		"data[email_type]=html",
+		"data[merges][EMAIL]=api+unsub@mailchimp.com",
+		"data[merges][FNAME]=Mailchimp",
+		"data[merges][LNAME]=API",
+		"data[merges][INTERESTS]=Group1,Group2",
+		"data[ip_opt]=10.20.10.30",
+		"data[campaign_id]=cb398d21d2",
+	},
+	WebhookEventTypeProfileUpdates: {
+		"type=profile",
+		"fired_at=2009-03-26 21:31:21",
+		"data[id]=8a25ff1d98",
+		"data[list_id]=a6b5da1054",
+		"data[email]=api@mailchimp.com",
+		"data[email_type]=html",
+		"data[merges][EMAIL]=api@mailchimp.com",
+		"data[merges][FNAME]=Mailchimp",
+		"data[merges][LNAME]=API",
+		"data[merges][INTERESTS]=Group1,Group2",
+		"data[merges][ADDRESS][addr1]=675 Ponce de Leon Ave NE",
+		"data[merges][ADDRESS][addr2]=Suite 5000",
+		"data[merges][ADDRESS][city]=Atlanta",
+		"data[merges][ADDRESS][state]=GA",
+		"data[merges][ADDRESS][zip]=30308",
+		"data[merges][ADDRESS][country]=US",
+		"data[merges][GROUPINGS][0][id]=1",
+		"data[merges][GROUPINGS][0][unique_id]=abc1",
+		"data[merges][GROUPINGS][0][name]=Interests",
+		`data[merges][GROUPINGS][0][groups]=Hats, Shoes\, Socks`,
+		"data[merges][GROUPINGS][1][id]=2",
+		"data[merges][GROUPINGS][1][unique_id]=abc2",
+		"data[merges][GROUPINGS][1][name]=Region",
+		"data[merges][GROUPINGS][1][groups]=",
+		"data[ip_opt]=10.20.10.30",
+	},
+	WebhookEventTypeEmailChanged: {
+		"type=upemail",
+		"fired_at=2009-03-26 22:15:09",
+		"data[list_id]=a6b5da1054",
+		"data[new_id]=51da8c3259",
+		"data[new_email]=api+new@mailchimp.com",
+		"data[old_email]=api+old@mailchimp.com",
+	},
+	WebhookEventTypeEmailCleaned: {
+		"type=cleaned",
+		"fired_at=2009-03-26 22:01:00",
+		"data[list_id]=a6b5da1054",
+		"data[campaign_id]=4fjk2ma9xd",
+		"data[reason]=hard",
+		"data[email]=api+cleaned@mailchimp.com",
+	},
+	WebhookEventTypeCampaignStatus: {
+		"type=campaign",
+		"fired_at=2009-03-26 21:31:21",
+		"data[id]=5aa2102003",
+		"data[subject]=Test Campaign Subject",
+		"data[status]=sent",
+		"data[reason]=",
+		"data[list_id]=a6b5da1054",
+	},
+}
+
+// webhookFixtureRequest form encodes a fixture into a POST request
+func webhookFixtureRequest(eventType string) *http.Request {
+	pairs := []string{}
+	for _, line := range webhookFixtures[eventType] {
+		kv := strings.SplitN(line, "=", 2)
+		pairs = append(pairs, url.QueryEscape(kv[0])+"="+url.QueryEscape(kv[1]))
+	}
+
+	r, _ := http.NewRequest("POST", "http://example.net/webhook", strings.NewReader(strings.Join(pairs, "&")))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func (s *WebhookTestSuite) Test_WebhookParseEvent_Subscribe(c *check.C) {
+	event, err := WebhookParseEvent(webhookFixtureRequest(WebhookEventTypeSubscribe))
+	c.Assert(err, check.IsNil)
+	c.Assert(event.Type, check.Equals, "subscribe")
+	c.Assert(event.FiredAt, check.Equals, "2009-03-26 21:35:57")
+	c.Assert(event.ID, check.Equals, "8a25ff1d98")
+	c.Assert(event.Email, check.Equals, "api@mailchimp.com")
+	c.Assert(event.IPSignup, check.Equals, "10.20.10.30")
+	c.Assert(event.Merges, check.DeepEquals, map[string]string{
+		"EMAIL":     "api@mailchimp.com",
+		"FNAME":     "Mailchimp",
+		"LNAME":     "API",
+		"INTERESTS": "Group1,Group2",
+	})
+	c.Assert(event.Groupings, check.HasLen, 0)
+	c.Assert(event.Addresses, check.HasLen, 0)
+	c.Assert(event.GetMergesField("FNAME"), check.Equals, "Mailchimp")
+}
+
+func (s *WebhookTestSuite) Test_WebhookParseEvent_Unsubscribe(c *check.C) {
+	event, err := WebhookParseEvent(webhookFixtureRequest(WebhookEventTypeUnsubscribe))
+	c.Assert(err, check.IsNil)
+	c.Assert(event.Type, check.Equals, "unsubscribe")
+	c.Assert(event.Action, check.Equals, "unsub")
+	c.Assert(event.Reason, check.Equals, "manual")
+	c.Assert(event.CampaignID, check.Equals, "cb398d21d2")
+	c.Assert(event.Merges["EMAIL"], check.Equals, "api+unsub@mailchimp.com")
+}
+
+func (s *WebhookTestSuite) Test_WebhookParseEvent_Profile(c *check.C) {
+	event, err := WebhookParseEvent(webhookFixtureRequest(WebhookEventTypeProfileUpdates))
+	c.Assert(err, check.IsNil)
+	c.Assert(event.Type, check.Equals, "profile")
+	c.Assert(event.Merges, check.DeepEquals, map[string]string{
+		"EMAIL":     "api@mailchimp.com",
+		"FNAME":     "Mailchimp",
+		"LNAME":     "API",
+		"INTERESTS": "Group1,Group2",
+	})
+	c.Assert(event.Addresses, check.DeepEquals, map[string]*AddressValue{
+		"ADDRESS": {
+			Addr1:   "675 Ponce de Leon Ave NE",
+			Addr2:   "Suite 5000",
+			City:    "Atlanta",
+			State:   "GA",
+			Zip:     "30308",
+			Country: "US",
+		},
+	})
+	c.Assert(event.Groupings, check.DeepEquals, []*WebhookGrouping{
+		{ID: "1", UniqueID: "abc1", Name: "Interests", Groups: `Hats, Shoes\, Socks`},
+		{ID: "2", UniqueID: "abc2", Name: "Region", Groups: ""},
+	})
+	c.Assert(event.Groupings[0].GroupNames(), check.DeepEquals, []string{"Hats", "Shoes, Socks"})
+	c.Assert(event.Groupings[1].GroupNames(), check.DeepEquals, []string{})
+}
+
+func (s *WebhookTestSuite) Test_WebhookParseEvent_UpEmail(c *check.C) {
+	event, err := WebhookParseEvent(webhookFixtureRequest(WebhookEventTypeEmailChanged))
+	c.Assert(err, check.IsNil)
+	c.Assert(event.Type, check.Equals, "upemail")
+	c.Assert(event.NewID, check.Equals, "51da8c3259")
+	c.Assert(event.NewEmail, check.Equals, "api+new@mailchimp.com")
+	c.Assert(event.OldEmail, check.Equals, "api+old@mailchimp.com")
+	c.Assert(event.Merges, check.HasLen, 0)
+}
+
+func (s *WebhookTestSuite) Test_WebhookParseEvent_Cleaned(c *check.C) {
+	event, err := WebhookParseEvent(webhookFixtureRequest(WebhookEventTypeEmailCleaned))
+	c.Assert(err, check.IsNil)
+	c.Assert(event.Type, check.Equals, "cleaned")
+	c.Assert(event.Reason, check.Equals, "hard")
+	c.Assert(event.CampaignID, check.Equals, "4fjk2ma9xd")
+	c.Assert(event.Email, check.Equals, "api+cleaned@mailchimp.com")
+}
+
+func (s *WebhookTestSuite) Test_WebhookParseEvent_Campaign(c *check.C) {
+	event, err := WebhookParseEvent(webhookFixtureRequest(WebhookEventTypeCampaignStatus))
+	c.Assert(err, check.IsNil)
+	c.Assert(event.Type, check.Equals, "campaign")
+	c.Assert(event.ID, check.Equals, "5aa2102003")
+	c.Assert(event.Subject, check.Equals, "Test Campaign Subject")
+	c.Assert(event.Status, check.Equals, "sent")
+	c.Assert(event.ListID, check.Equals, "a6b5da1054")
+}
+
+func (s *WebhookTestSuite) Test_WebhookParseEvent_LegacyMergesFields(c *check.C) {
+	event, err := WebhookParseEvent(webhookFixtureRequest(WebhookEventTypeSubscribe), "data[merges][LNAME]")
+	c.Assert(err, check.IsNil)
+	c.Assert(event.GetMergesField("data[merges][LNAME]"), check.Equals, "API")
+	c.Assert(event.GetMergesField("LNAME"), check.Equals, "API")
+	c.Assert(event.GetMergesField("MISSING"), check.Equals, "")
+}
+
+func (s *WebhookTestSuite) Test_WebhookParseEvent_MalformedGrouping(c *check.C) {
+	r, _ := http.NewRequest("POST", "http://example.net/webhook", strings.NewReader(url.QueryEscape("data[merges][GROUPINGS][x][id]")+"=1"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	_, err := WebhookParseEvent(r)
+	c.Assert(err, check.ErrorMatches, "malformed grouping key: .*")
+}
+
+func (s *WebhookTestSuite) Test_Update_Normal(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "PATCH",
+		Code:   200,
+		Body:   `{"id":"2","url":"http://test.url/webhook","events":{"subscribe":true},"sources":{"api":true},"list_id":"1"}`,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(r.RequestURI, check.Equals, "http://us13.api.mailchimp.com/3.0/lists/1/webhooks/2")
+			c.Assert(body, check.Equals, `{"events":{"subscribe":true,"unsubscribe":false,"profile":false,"cleaned":false,"upemail":false,"campaign":false},"sources":{"user":false,"admin":false,"api":true}}`)
+		},
+	})
+
+	webhook := &Webhook{ID: "2", ListID: "1", Client: s.client}
+	updated, err := webhook.Update(s.ctx, &UpdateWebhook{
+		Events:  &WebhookEvents{Subscribe: true},
+		Sources: &WebhookSources{API: true},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Events, check.DeepEquals, &WebhookEvents{Subscribe: true})
+	c.Assert(updated.Client, check.Not(check.IsNil))
+}
+
+func (s *WebhookTestSuite) Test_Update_URLOnly(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "PATCH",
+		Code:   200,
+		Body:   `{"id":"2"}`,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(body, check.Equals, `{"url":"http://test.url/new"}`)
+		},
+	})
+
+	webhook := &Webhook{ID: "2", ListID: "1", Client: s.client}
+	_, err := webhook.Update(s.ctx, &UpdateWebhook{URL: "http://test.url/new"})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *WebhookTestSuite) Test_Update_NoClient(c *check.C) {
+	webhook := &Webhook{ID: "2", ListID: "1"}
+	_, err := webhook.Update(s.ctx, &UpdateWebhook{})
+	c.Assert(err, check.ErrorMatches, "no client assigned by parent")
+}
+
+func (s *WebhookTestSuite) Test_EnsureWebhook_Unchanged(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"webhooks":[{"id":"1","url":"http://test.url/other","list_id":"1"},{"id":"2","url":"http://test.url/webhook","events":{"subscribe":true,"unsubscribe":true},"sources":{"user":true},"list_id":"1"}],"total_items":2}`,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(r.RequestURI, check.Equals, "http://us13.api.mailchimp.com/3.0/lists/1/webhooks")
+		},
+	})
+
+	webhook, err := s.client.EnsureWebhook(s.ctx, "1", "http://test.url/webhook",
+		&WebhookEvents{Subscribe: true, Unsubscribe: true},
+		&WebhookSources{User: true},
+	)
+	c.Assert(err, check.IsNil)
+	c.Assert(webhook.ID, check.Equals, "2")
+	s.server.VerifyNoMoreRequests(c)
+}
+
+func (s *WebhookTestSuite) Test_EnsureWebhook_Changed(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"webhooks":[{"id":"2","url":"http://test.url/webhook","events":{"subscribe":true,"unsubscribe":true},"sources":{"user":true},"list_id":"1"}],"total_items":1}`,
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "PATCH",
+		Code:   200,
+		Body:   `{"id":"2","url":"http://test.url/webhook","events":{"subscribe":true},"sources":{"user":true},"list_id":"1"}`,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(r.RequestURI, check.Equals, "http://us13.api.mailchimp.com/3.0/lists/1/webhooks/2")
+			c.Assert(body, check.Equals, `{"events":{"subscribe":true,"unsubscribe":false,"profile":false,"cleaned":false,"upemail":false,"campaign":false},"sources":{"user":true,"admin":false,"api":false}}`)
+		},
+	})
+
+	webhook, err := s.client.EnsureWebhook(s.ctx, "1", "http://test.url/webhook",
+		&WebhookEvents{Subscribe: true},
+		&WebhookSources{User: true},
+	)
+	c.Assert(err, check.IsNil)
+	c.Assert(webhook.Events, check.DeepEquals, &WebhookEvents{Subscribe: true})
+	s.server.VerifyNoMoreRequests(c)
+}
+
+func (s *WebhookTestSuite) Test_EnsureWebhook_Missing(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"webhooks":[],"total_items":0}`,
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Path:   "/lists/1",
+		Code:   200,
+		Body:   `{"id":"1"}`,
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "POST",
+		Code:   200,
+		Body:   `{"id":"3","url":"http://test.url/webhook","list_id":"1"}`,
+		CheckFn: func(r *http.Request, body string) {
+			c.Assert(r.RequestURI, check.Equals, "http://us13.api.mailchimp.com/3.0/lists/1/webhooks")
+			c.Assert(body, check.Equals, `{"url":"http://test.url/webhook","events":{"campaign":true},"sources":{"admin":true}}`)
+		},
+	})
+
+	webhook, err := s.client.EnsureWebhook(s.ctx, "1", "http://test.url/webhook",
+		&WebhookEvents{Campaign: true},
+		&WebhookSources{Admin: true},
+	)
+	c.Assert(err, check.IsNil)
+	c.Assert(webhook.ID, check.Equals, "3")
+	c.Assert(webhook.Client, check.Not(check.IsNil))
+	s.server.VerifyNoMoreRequests(c)
+}
+
+func (s *WebhookTestSuite) Test_EnsureWebhook_Secret(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"webhooks":[{"id":"2","url":"http://test.url/webhook?token=s3cret","events":{"subscribe":true},"sources":{"user":true},"list_id":"1"}],"total_items":1}`,
+	})
+
+	webhook, err := s.client.EnsureWebhook(s.ctx, "1", "http://test.url/webhook",
+		&WebhookEvents{Subscribe: true},
+		&WebhookSources{User: true},
+	)
+	c.Assert(err, check.IsNil)
+	c.Assert(webhook.ID, check.Equals, "2")
+	s.server.VerifyNoMoreRequests(c)
+}
+
+func (s *WebhookTestSuite) Test_EnsureWebhook_RotateSecret(c *check.C) {
+	s.server.AddResponse(&t.MockResponse{
+		Method: "GET",
+		Code:   200,
+		Body:   `{"webhooks":[{"id":"2","url":"http://test.url/webhook?a=b&token=old","events":{"subscribe":true},"sources":{"user":true},"list_id":"1"}],"total_items":1}`,
+	})
+	s.server.AddResponse(&t.MockResponse{
+		Method: "PATCH",
+		Path:   "/lists/1/webhooks/2",
+		JSON:   `{"url":"http://test.url/webhook?a=b\u0026token=new","events":{"subscribe":true,"unsubscribe":false,"profile":false,"cleaned":false,"upemail":false,"campaign":false},"sources":{"user":true,"admin":false,"api":false}}`,
+		Code:   200,
+		Body:   `{"id":"2","url":"http://test.url/webhook?a=b&token=new","list_id":"1"}`,
+	})
+
+	webhookURL, err := WebhookURLWithSecret("http://test.url/webhook?a=b", "new")
+	c.Assert(err, check.IsNil)
+	webhook, err := s.client.EnsureWebhook(s.ctx, "1", webhookURL,
+		&WebhookEvents{Subscribe: true},
+		&WebhookSources{User: true},
+	)
+	c.Assert(err, check.IsNil)
+	c.Assert(webhook.URL, check.Equals, "http://test.url/webhook?a=b&token=new")
+	s.server.VerifyNoMoreRequests(c)
+}
@@ -0,0 +1,290 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhooktest simulates Mailchimp webhooks, so services built on
+// mailchimp.WebhookParseEvent can be tested without a public url.
+//
+// Payloads are form encoded the way Mailchimp does it: fields in
+// Mailchimp's order, brackets percent encoded and spaces as '+'.
+//
+//  payload := webhooktest.New(mailchimp.WebhookEventTypeUnsubscribe).
+//      Set("data[email]", "user@example.com")
+//  w := webhooktest.Serve(handler, payload)
+package webhooktest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/greatbeyond/mailchimp"
+)
+
+// UserAgent is the user agent Mailchimp sends webhooks with
+const UserAgent = "MailChimp"
+
+// EventTypes lists every webhook event type
+var EventTypes = []string{
+	mailchimp.WebhookEventTypeSubscribe,
+	mailchimp.WebhookEventTypeUnsubscribe,
+	mailchimp.WebhookEventTypeProfileUpdates,
+	mailchimp.WebhookEventTypeEmailChanged,
+	mailchimp.WebhookEventTypeEmailCleaned,
+	mailchimp.WebhookEventTypeCampaignStatus,
+}
+
+// Field is a single form field
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Payload is a webhook body. It is a slice rather than url.Values since
+// Mailchimp sends the fields in a fixed order, which url.Values would sort.
+type Payload []Field
+
+// samples are the example payloads from the Mailchimp webhook documentation
+var samples = map[string]Payload{
+	mailchimp.WebhookEventTypeSubscribe: {
+		{"type", "subscribe"},
+		{"fired_at", "2009-03-26 21:35:57"},
+		{"data[id]", "8a25ff1d98"},
+		{"data[list_id]", "a6b5da1054"},
+		{"data[email]", "api@mailchimp.com"},
+		{"data[email_type]", "html"},
+		{"data[merges][EMAIL]", "api@mailchimp.com"},
+		{"data[merges][FNAME]", "Mailchimp"},
+		{"data[merges][LNAME]", "API"},
+		{"data[merges][INTERESTS]", "Group1,Group2"},
+		{"data[ip_opt]", "10.20.10.30"},
+		{"data[ip_signup]", "10.20.10.30"},
+	},
+	mailchimp.WebhookEventTypeUnsubscribe: {
+		{"type", "unsubscribe"},
+		{"fired_at", "2009-03-26 21:40:57"},
+		{"data[action]", "unsub"},
+		{"data[reason]", "manual"},
+		{"data[id]", "8a25ff1d98"},
+		{"data[list_id]", "a6b5da1054"},
+		{"data[email]", "api+unsub@mailchimp.com"},
+		{"data[email_type]", "html"},
+		{"data[merges][EMAIL]", "api+unsub@mailchimp.com"},
+		{"data[merges][FNAME]", "Mailchimp"},
+		{"data[merges][LNAME]", "API"},
+		{"data[merges][INTERESTS]", "Group1,Group2"},
+		{"data[ip_opt]", "10.20.10.30"},
+		{"data[campaign_id]", "cb398d21d2"},
+	},
+	mailchimp.WebhookEventTypeProfileUpdates: {
+		{"type", "profile"},
+		{"fired_at", "2009-03-26 21:31:21"},
+		{"data[id]", "8a25ff1d98"},
+		{"data[list_id]", "a6b5da1054"},
+		{"data[email]", "api@mailchimp.com"},
+		{"data[email_type]", "html"},
+		{"data[merges][EMAIL]", "api@mailchimp.com"},
+		{"data[merges][FNAME]", "Mailchimp"},
+		{"data[merges][LNAME]", "API"},
+		{"data[merges][INTERESTS]", "Group1,Group2"},
+		{"data[ip_opt]", "10.20.10.30"},
+	},
+	mailchimp.WebhookEventTypeEmailChanged: {
+		{"type", "upemail"},
+		{"fired_at", "2009-03-26 22:15:09"},
+		{"data[list_id]", "a6b5da1054"},
+		{"data[new_id]", "51da8c3259"},
+		{"data[new_email]", "api+new@mailchimp.com"},
+		{"data[old_email]", "api+old@mailchimp.com"},
+	},
+	mailchimp.WebhookEventTypeEmailCleaned: {
+		{"type", "cleaned"},
+		{"fired_at", "2009-03-26 22:01:00"},
+		{"data[list_id]", "a6b5da1054"},
+		{"data[campaign_id]", "4fjk2ma9xd"},
+		{"data[reason]", "hard"},
+		{"data[email]", "api+cleaned@mailchimp.com"},
+	},
+	mailchimp.WebhookEventTypeCampaignStatus: {
+		{"type", "campaign"},
+		{"fired_at", "2009-03-26 21:31:21"},
+		{"data[id]", "5aa2102003"},
+		{"data[subject]", "Test Campaign Subject"},
+		{"data[status]", "sent"},
+		{"data[reason]", ""},
+		{"data[list_id]", "a6b5da1054"},
+	},
+}
+
+// New returns the documented example payload for eventType. It panics on
+// unknown types, like regexp.MustCompile, since the type is a constant in
+// any sane test.
+func New(eventType string) Payload {
+	sample, ok := samples[eventType]
+	if !ok {
+		panic(fmt.Sprintf("webhooktest: unknown event type: %s", eventType))
+	}
+	return sample.clone()
+}
+
+// clone copies the payload, so changes to the copy never reach p or other
+// payloads sharing its backing array
+func (p Payload) clone() Payload {
+	out := make(Payload, len(p))
+	copy(out, p)
+	return out
+}
+
+// Get returns the first value of key, or "" if it is not set
+func (p Payload) Get(key string) string {
+	for _, f := range p {
+		if f.Key == key {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Set returns a copy of the payload with the value of key replaced, or
+// appended if it is not set. p is left unchanged.
+func (p Payload) Set(key string, value string) Payload {
+	out := p.clone()
+	for i := range out {
+		if out[i].Key == key {
+			out[i].Value = value
+			return out
+		}
+	}
+	return append(out, Field{key, value})
+}
+
+// Del returns a copy of the payload without key. p is left unchanged.
+func (p Payload) Del(key string) Payload {
+	out := Payload{}
+	for _, f := range p {
+		if f.Key != key {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// SetFiredAt sets fired_at to t in UTC
+func (p Payload) SetFiredAt(t time.Time) Payload {
+	return p.Set("fired_at", t.UTC().Format(mailchimp.TimeFormat))
+}
+
+// SetMerge sets data[merges][tag]
+func (p Payload) SetMerge(tag string, value string) Payload {
+	return p.Set("data[merges]["+tag+"]", value)
+}
+
+// SetAddress sets the parts of the address merge field tag
+func (p Payload) SetAddress(tag string, address *mailchimp.AddressValue) Payload {
+	prefix := "data[merges][" + tag + "]"
+	p = p.Set(prefix+"[addr1]", address.Addr1)
+	p = p.Set(prefix+"[addr2]", address.Addr2)
+	p = p.Set(prefix+"[city]", address.City)
+	p = p.Set(prefix+"[state]", address.State)
+	p = p.Set(prefix+"[zip]", address.Zip)
+	return p.Set(prefix+"[country]", address.Country)
+}
+
+// AddGrouping returns a copy of the payload with a interest grouping
+// appended. Commas in group names are escaped with a backslash, like
+// Mailchimp does.
+func (p Payload) AddGrouping(id string, uniqueID string, name string, groups ...string) Payload {
+	n := 0
+	for _, f := range p {
+		if strings.HasPrefix(f.Key, "data[merges][GROUPINGS][") && strings.HasSuffix(f.Key, "][id]") {
+			n++
+		}
+	}
+
+	escaped := make([]string, len(groups))
+	for i, g := range groups {
+		escaped[i] = strings.Replace(g, ",", `\,`, -1)
+	}
+
+	prefix := fmt.Sprintf("data[merges][GROUPINGS][%d]", n)
+	return append(p.clone(),
+		Field{prefix + "[id]", id},
+		Field{prefix + "[unique_id]", uniqueID},
+		Field{prefix + "[name]", name},
+		Field{prefix + "[groups]", strings.Join(escaped, ", ")},
+	)
+}
+
+// Values returns the payload as url.Values
+func (p Payload) Values() url.Values {
+	values := url.Values{}
+	for _, f := range p {
+		values.Add(f.Key, f.Value)
+	}
+	return values
+}
+
+// Encode returns the request body
+func (p Payload) Encode() string {
+	pairs := make([]string, len(p))
+	for i, f := range p {
+		pairs[i] = url.QueryEscape(f.Key) + "=" + url.QueryEscape(f.Value)
+	}
+	return strings.Join(pairs, "&")
+}
+
+// NewRequest returns a POST request of payload to target, with the headers
+// Mailchimp sends.
+func NewRequest(target string, payload Payload) (*http.Request, error) {
+	r, err := http.NewRequest(http.MethodPost, target, strings.NewReader(payload.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("User-Agent", UserAgent)
+	return r, nil
+}
+
+// Serve sends payload to handler and returns the recorded response
+func Serve(handler http.Handler, payload Payload) *httptest.ResponseRecorder {
+	r, _ := NewRequest("http://localhost/webhook", payload)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+// Validate sends the GET request Mailchimp uses to check a new webhook url
+func Validate(handler http.Handler) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "http://localhost/webhook", nil)
+	r.Header.Set("User-Agent", UserAgent)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+// Post sends payload to target. If client is nil http.DefaultClient is used.
+// The caller must close the response body.
+func Post(ctx context.Context, client *http.Client, target string, payload Payload) (*http.Response, error) {
+	r, err := NewRequest(target, payload)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(r.WithContext(ctx))
+}
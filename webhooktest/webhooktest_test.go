@@ -0,0 +1,149 @@
+// © Copyright 2016 GREAT BEYOND AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooktest
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	check "gopkg.in/check.v1"
+
+	"github.com/greatbeyond/mailchimp"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test_WebhookTest(t *testing.T) { check.TestingT(t) }
+
+var _ = check.Suite(&WebhookTestSuite{})
+
+type WebhookTestSuite struct{}
+
+func (s *WebhookTestSuite) SetUpSuite(c *check.C) {}
+
+func (s *WebhookTestSuite) SetUpTest(c *check.C) {}
+
+func (s *WebhookTestSuite) TearDownTest(c *check.C) {}
+
+func (s *WebhookTestSuite) Test_Encode(c *check.C) {
+	c.Assert(New(mailchimp.WebhookEventTypeEmailCleaned).Encode(), check.Equals,
+		"type=cleaned&fired_at=2009-03-26+22%3A01%3A00&data%5Blist_id%5D=a6b5da1054"+
+			"&data%5Bcampaign_id%5D=4fjk2ma9xd&data%5Breason%5D=hard&data%5Bemail%5D=api%2Bcleaned%40mailchimp.com")
+}
+
+func (s *WebhookTestSuite) Test_New_AllTypes(c *check.C) {
+	for _, eventType := range EventTypes {
+		r, err := NewRequest("http://localhost/webhook", New(eventType))
+		c.Assert(err, check.IsNil)
+
+		event, err := mailchimp.WebhookParseEvent(r)
+		c.Assert(err, check.IsNil)
+		c.Assert(event.Type, check.Equals, eventType)
+		c.Assert(event.ListID, check.Equals, "a6b5da1054")
+	}
+}
+
+func (s *WebhookTestSuite) Test_New_Unknown(c *check.C) {
+	c.Assert(func() { New("bogus") }, check.PanicMatches, "webhooktest: unknown event type: bogus")
+}
+
+func (s *WebhookTestSuite) Test_New_Copies(c *check.C) {
+	New(mailchimp.WebhookEventTypeSubscribe).Set("data[email]", "changed@example.com")
+	c.Assert(New(mailchimp.WebhookEventTypeSubscribe).Get("data[email]"), check.Equals, "api@mailchimp.com")
+}
+
+func (s *WebhookTestSuite) Test_Modify(c *check.C) {
+	p := New(mailchimp.WebhookEventTypeProfileUpdates).
+		Set("data[email]", "user@example.com").
+		SetFiredAt(time.Date(2017, 5, 1, 14, 0, 0, 0, time.FixedZone("CEST", 2*3600))).
+		SetMerge("FNAME", "Jane").
+		SetAddress("ADDRESS", &mailchimp.AddressValue{City: "Atlanta", Country: "US"}).
+		AddGrouping("1", "abc1", "Interests", "Hats", "Shoes, Socks").
+		AddGrouping("2", "abc2", "Region").
+		Del("data[ip_opt]")
+
+	c.Assert(p.Get("fired_at"), check.Equals, "2017-05-01 12:00:00")
+	c.Assert(p.Get("data[ip_opt]"), check.Equals, "")
+
+	r, _ := NewRequest("http://localhost/webhook", p)
+	event, err := mailchimp.WebhookParseEvent(r)
+	c.Assert(err, check.IsNil)
+	c.Assert(event.Email, check.Equals, "user@example.com")
+	c.Assert(event.Merges["FNAME"], check.Equals, "Jane")
+	c.Assert(event.Addresses["ADDRESS"].City, check.Equals, "Atlanta")
+	c.Assert(event.Groupings, check.HasLen, 2)
+	c.Assert(event.Groupings[0].GroupNames(), check.DeepEquals, []string{"Hats", "Shoes, Socks"})
+	c.Assert(event.Groupings[1].GroupNames(), check.HasLen, 0)
+}
+
+func (s *WebhookTestSuite) Test_Modify_LeavesBase(c *check.C) {
+	base := New(mailchimp.WebhookEventTypeSubscribe).Del("data[ip_opt]")
+
+	a := base.Set("data[email]", "a@example.com")
+	b := base.Set("data[email]", "b@example.com").Del("type")
+	d := base.AddGrouping("1", "abc1", "Interests", "Hats")
+	e := base.AddGrouping("2", "abc2", "Region")
+
+	c.Assert(base.Get("data[email]"), check.Equals, "api@mailchimp.com")
+	c.Assert(base.Get("type"), check.Equals, "subscribe")
+	c.Assert(a.Get("data[email]"), check.Equals, "a@example.com")
+	c.Assert(a.Get("type"), check.Equals, "subscribe")
+	c.Assert(b.Get("data[email]"), check.Equals, "b@example.com")
+	c.Assert(b.Get("type"), check.Equals, "")
+	c.Assert(d.Get("data[merges][GROUPINGS][0][name]"), check.Equals, "Interests")
+	c.Assert(e.Get("data[merges][GROUPINGS][0][name]"), check.Equals, "Region")
+	c.Assert(base, check.HasLen, len(New(mailchimp.WebhookEventTypeSubscribe))-1)
+}
+
+func (s *WebhookTestSuite) Test_Serve(c *check.C) {
+	var received *mailchimp.UpEmailEvent
+	h := &mailchimp.WebhookHandler{
+		OnUpEmail: func(ctx context.Context, e *mailchimp.UpEmailEvent) error {
+			received = e
+			return nil
+		},
+	}
+
+	c.Assert(Validate(h).Code, check.Equals, http.StatusOK)
+
+	w := Serve(h, New(mailchimp.WebhookEventTypeEmailChanged))
+	c.Assert(w.Code, check.Equals, http.StatusOK)
+	c.Assert(received, check.NotNil)
+	c.Assert(received.NewEmail, check.Equals, "api+new@mailchimp.com")
+}
+
+func (s *WebhookTestSuite) Test_Post(c *check.C) {
+	var body string
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		header = r.Header
+	}))
+	defer server.Close()
+
+	p := New(mailchimp.WebhookEventTypeSubscribe)
+	resp, err := Post(context.Background(), nil, server.URL+"/webhook?token=secret", p)
+	c.Assert(err, check.IsNil)
+	resp.Body.Close()
+
+	c.Assert(resp.StatusCode, check.Equals, http.StatusOK)
+	c.Assert(body, check.Equals, p.Encode())
+	c.Assert(header.Get("Content-Type"), check.Equals, "application/x-www-form-urlencoded")
+	c.Assert(header.Get("User-Agent"), check.Equals, UserAgent)
+}